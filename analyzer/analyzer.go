@@ -16,16 +16,20 @@ type SQLAnalysis struct {
 	HasWindowFunction  bool              // 是否包含窗口函数
 	TableAliases       map[string]string // 表别名映射
 	ColumnAliases      map[string]string // 列别名映射
+	TimeTravelTables   map[string]string // 读取历史快照的表 -> 时间旅行子句
+	SampledTables      map[string]string // 采样读取的表 -> TABLESAMPLE 子句
 }
 
 // AnalyzeSQL 分析 SQL 语句（基于 SqlNode）
 func AnalyzeSQL(sqlNode parser.SqlNode) *SQLAnalysis {
 	if sqlNode == nil {
 		return &SQLAnalysis{
-			Tables:        []string{},
-			Columns:       []string{},
-			TableAliases:  make(map[string]string),
-			ColumnAliases: make(map[string]string),
+			Tables:           []string{},
+			Columns:          []string{},
+			TableAliases:     make(map[string]string),
+			ColumnAliases:    make(map[string]string),
+			TimeTravelTables: make(map[string]string),
+			SampledTables:    make(map[string]string),
 		}
 	}
 	
@@ -38,10 +42,13 @@ func AnalyzeSQL(sqlNode parser.SqlNode) *SQLAnalysis {
 			JoinTypes:          []string{},
 			TableAliases:       make(map[string]string),
 			ColumnAliases:      make(map[string]string),
+			TimeTravelTables:   make(map[string]string),
+			SampledTables:      make(map[string]string),
 		},
 		tableSet:  make(map[string]bool),
 		columnSet: make(map[string]bool),
 	}
+	analyzer.BaseSqlNodeVisitor = parser.NewBaseSqlNodeVisitor(analyzer)
 	
 	// 遍历 SqlNode 树
 	sqlNode.Accept(analyzer)
@@ -50,16 +57,21 @@ func AnalyzeSQL(sqlNode parser.SqlNode) *SQLAnalysis {
 }
 
 // SQLAnalyzer 实现 SqlNodeVisitor 接口来分析 SQL
+// 未覆盖的节点由 BaseSqlNodeVisitor 继续遍历子节点
 type SQLAnalyzer struct {
+	*parser.BaseSqlNodeVisitor
+	
 	Analysis  *SQLAnalysis
 	tableSet  map[string]bool // 用于去重
 	columnSet map[string]bool // 用于去重
+	
+	lambdaScopes []map[string]bool // lambda 参数作用域栈
 }
 
 // VisitIdentifier 访问标识符
 func (a *SQLAnalyzer) VisitIdentifier(node *parser.SqlIdentifier) (interface{}, error) {
-	// 标识符可能是列名
-	if len(node.Names) > 0 {
+	// 标识符可能是列名（lambda 参数除外）
+	if len(node.Names) > 0 && !a.isLambdaParameter(node.Names[0]) {
 		fullName := strings.Join(node.Names, ".")
 		if !a.columnSet[fullName] && fullName != "*" {
 			a.columnSet[fullName] = true
@@ -69,12 +81,6 @@ func (a *SQLAnalyzer) VisitIdentifier(node *parser.SqlIdentifier) (interface{},
 	return nil, nil
 }
 
-// VisitLiteral 访问字面量
-func (a *SQLAnalyzer) VisitLiteral(node *parser.SqlLiteral) (interface{}, error) {
-	// 字面量不需要特别处理
-	return nil, nil
-}
-
 // VisitCall 访问函数调用/操作符
 func (a *SQLAnalyzer) VisitCall(node *parser.SqlCall) (interface{}, error) {
 	if node.Operator != nil {
@@ -151,9 +157,11 @@ func (a *SQLAnalyzer) VisitSelect(node *parser.SqlSelect) (interface{}, error) {
 
 // VisitJoin 访问 JOIN 节点
 func (a *SQLAnalyzer) VisitJoin(node *parser.SqlJoin) (interface{}, error) {
-	// 记录 JOIN 类型
-	joinType := string(node.JoinType) + " JOIN"
-	a.Analysis.JoinTypes = append(a.Analysis.JoinTypes, joinType)
+	// 记录 JOIN 类型（FROM a, b 形式的逗号连接不是显式 JOIN）
+	if node.JoinType != parser.JoinComma {
+		joinType := string(node.JoinType) + " JOIN"
+		a.Analysis.JoinTypes = append(a.Analysis.JoinTypes, joinType)
+	}
 	
 	// 提取左侧表
 	if node.Left != nil {
@@ -197,20 +205,82 @@ func (a *SQLAnalyzer) VisitBasicCall(node *parser.SqlBasicCall) (interface{}, er
 	return nil, nil
 }
 
-// VisitNodeList 访问节点列表
-func (a *SQLAnalyzer) VisitNodeList(node *parser.SqlNodeList) (interface{}, error) {
-	for _, item := range node.List {
-		item.Accept(a)
+// VisitHint 访问 Hint 节点
+func (a *SQLAnalyzer) VisitHint(node *parser.SqlHint) (interface{}, error) {
+	// Hint 暂时不需要分析，直接返回
+	return nil, nil
+}
+
+// VisitLambda 访问 Lambda 表达式
+func (a *SQLAnalyzer) VisitLambda(node *parser.SqlLambda) (interface{}, error) {
+	if node.Body == nil {
+		return nil, nil
+	}
+	
+	// 参数只在 lambda 体内可见，不作为列名记录
+	a.lambdaScopes = append(a.lambdaScopes, node.ParameterNames())
+	defer func() {
+		a.lambdaScopes = a.lambdaScopes[:len(a.lambdaScopes)-1]
+	}()
+	
+	return node.Body.Accept(a)
+}
+
+// VisitTableRef 访问带时间旅行/采样的表引用
+func (a *SQLAnalyzer) VisitTableRef(node *parser.SqlTableRef) (interface{}, error) {
+	if node.Name == nil {
+		return nil, nil
+	}
+	
+	a.extractTablesFromNode(node.Name)
+	
+	// 记录历史快照和采样读取，供审计使用
+	tableName := node.Name.ToString()
+	if node.Temporal != nil {
+		a.Analysis.TimeTravelTables[tableName] = node.Temporal.ToString()
+	}
+	if node.Sample != nil {
+		a.Analysis.SampledTables[tableName] = node.Sample.ToString()
 	}
+	
 	return nil, nil
 }
 
-// VisitHint 访问 Hint 节点
-func (a *SQLAnalyzer) VisitHint(node *parser.SqlHint) (interface{}, error) {
-	// Hint 暂时不需要分析，直接返回
+// VisitDescribe 访问 DESCRIBE 语句
+func (a *SQLAnalyzer) VisitDescribe(node *parser.SqlDescribe) (interface{}, error) {
+	if node.Table != nil {
+		a.extractTablesFromNode(node.Table)
+	}
+	if node.Query != nil {
+		node.Query.Accept(a)
+	}
+	return nil, nil
+}
+
+// VisitShow 访问 SHOW 语句
+func (a *SQLAnalyzer) VisitShow(node *parser.SqlShow) (interface{}, error) {
+	if node.Object != nil {
+		a.extractTablesFromNode(node.Object)
+	}
 	return nil, nil
 }
 
+// VisitUse 访问 USE / SET CATALOG 语句
+func (a *SQLAnalyzer) VisitUse(node *parser.SqlUse) (interface{}, error) {
+	// 只切换命名空间，不需要分析
+	return nil, nil
+}
+
+// isLambdaParameter 判断名字是否为当前作用域内的 lambda 参数
+func (a *SQLAnalyzer) isLambdaParameter(name string) bool {
+	for i := len(a.lambdaScopes) - 1; i >= 0; i-- {
+		if a.lambdaScopes[i][name] {
+			return true
+		}
+	}
+	return false
+}
+
 // extractTablesFromNode 从节点中提取表名
 func (a *SQLAnalyzer) extractTablesFromNode(node parser.SqlNode) {
 	if node == nil {
@@ -229,6 +299,12 @@ func (a *SQLAnalyzer) extractTablesFromNode(node parser.SqlNode) {
 		return
 	}
 	
+	// 如果是带时间旅行/采样的表引用
+	if tableRef, ok := node.(*parser.SqlTableRef); ok {
+		tableRef.Accept(a)
+		return
+	}
+	
 	// 如果是 JOIN 节点，递归处理
 	if join, ok := node.(*parser.SqlJoin); ok {
 		join.Accept(a)
@@ -303,10 +379,12 @@ func isWindowFunction(funcName string) bool {
 func AnalyzeSQLFromParseResult(parseResult *parser.ParseResult) *SQLAnalysis {
 	if parseResult == nil || parseResult.Statement == nil {
 		return &SQLAnalysis{
-			Tables:        []string{},
-			Columns:       []string{},
-			TableAliases:  make(map[string]string),
-			ColumnAliases: make(map[string]string),
+			Tables:           []string{},
+			Columns:          []string{},
+			TableAliases:     make(map[string]string),
+			ColumnAliases:    make(map[string]string),
+			TimeTravelTables: make(map[string]string),
+			SampledTables:    make(map[string]string),
 		}
 	}
 	
@@ -0,0 +1,369 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-job-service/parser"
+)
+
+// =============================================================================
+// Expr - 表达式
+// =============================================================================
+
+// Expr 表达式，由 Col、Lit、函数和操作符构造
+// 生成的节点与解析器的输出形状相同，构造时的错误（如空列名）保存在表达式中，由 Build 返回
+type Expr struct {
+	node  parser.SqlNode
+	alias string // As 设置的别名，只能用于 SELECT 列表
+	err   error
+}
+
+// Node 返回表达式的语法树节点，不含别名
+func (e Expr) Node() parser.SqlNode {
+	return e.node
+}
+
+// Err 返回构造表达式时的错误
+func (e Expr) Err() error {
+	return e.err
+}
+
+// As 为 SELECT 列表项设置别名
+func (e Expr) As(alias string) Expr {
+	if e.err != nil {
+		return e
+	}
+	if alias == "" {
+		return errorf("别名不能为空")
+	}
+	if isStar(e.node) {
+		return errorf("%s 不能设置别名", parser.Unparse(e.node))
+	}
+	e.alias = alias
+	return e
+}
+
+// operand 返回作为其他表达式操作数的节点
+func (e Expr) operand() (parser.SqlNode, error) {
+	switch {
+	case e.err != nil:
+		return nil, e.err
+	case e.node == nil:
+		return nil, errors.New("表达式为空")
+	case e.alias != "":
+		return nil, fmt.Errorf("别名 %s 只能用于 SELECT 列表", e.alias)
+	}
+	return e.node, nil
+}
+
+// selectItem 返回作为 SELECT 列表项的节点，有别名时包上 AS 调用
+func (e Expr) selectItem() (parser.SqlNode, error) {
+	alias := e.alias
+	e.alias = ""
+	node, err := e.operand()
+	if err != nil || alias == "" {
+		return node, err
+	}
+	return asCall(node, alias), nil
+}
+
+// errorf 返回带构造错误的表达式
+func errorf(format string, args ...interface{}) Expr {
+	return Expr{err: fmt.Errorf(format, args...)}
+}
+
+// asCall 构造别名，与解析器一样表示为 AS 调用
+func asCall(node parser.SqlNode, alias string) *parser.SqlCall {
+	op := parser.NewSqlOperator("AS", parser.SqlKindAs, parser.SyntaxSpecial)
+	return parser.NewSqlCall(op, []parser.SqlNode{node, parser.NewSqlIdentifier([]string{alias}, nil)}, nil)
+}
+
+// isStar 判断是否为 * 或 t.*
+func isStar(node parser.SqlNode) bool {
+	identifier, ok := node.(*parser.SqlIdentifier)
+	return ok && len(identifier.Names) > 0 && identifier.Names[len(identifier.Names)-1] == "*"
+}
+
+// =============================================================================
+// 标识符与字面量
+// =============================================================================
+
+// Col 列引用，name 按 . 拆分为多部分标识符，如 "db.t.id"、"t.*"
+func Col(name string) Expr {
+	names, err := splitName(name)
+	if err != nil {
+		return Expr{err: err}
+	}
+	return Expr{node: parser.NewSqlIdentifier(names, nil)}
+}
+
+// Star 选择所有列的 *
+func Star() Expr {
+	return Col("*")
+}
+
+// splitName 拆分多部分名字，* 只能出现在最后一部分
+func splitName(name string) ([]string, error) {
+	if name == "" {
+		return nil, errors.New("名字不能为空")
+	}
+	names := strings.Split(name, ".")
+	for i, part := range names {
+		if part == "" {
+			return nil, fmt.Errorf("名字 %q 含有空的部分", name)
+		}
+		if part == "*" && i != len(names)-1 {
+			return nil, fmt.Errorf("名字 %q 中 * 只能在最后", name)
+		}
+	}
+	return names, nil
+}
+
+// Lit 字面量，整数转为 INTEGER，浮点数转为 DECIMAL，另支持 string、bool 和 nil（NULL）
+func Lit(value interface{}) Expr {
+	var literal *parser.SqlLiteral
+	switch v := value.(type) {
+	case nil:
+		literal = parser.NewSqlLiteral(nil, parser.LiteralNull, nil)
+	case int:
+		literal = parser.NewSqlLiteral(int64(v), parser.LiteralInteger, nil)
+	case int32:
+		literal = parser.NewSqlLiteral(int64(v), parser.LiteralInteger, nil)
+	case int64:
+		literal = parser.NewSqlLiteral(v, parser.LiteralInteger, nil)
+	case float32:
+		literal = parser.NewSqlLiteral(float64(v), parser.LiteralDecimal, nil)
+	case float64:
+		literal = parser.NewSqlLiteral(v, parser.LiteralDecimal, nil)
+	case string:
+		literal = parser.NewSqlLiteral(v, parser.LiteralString, nil)
+	case bool:
+		literal = parser.NewSqlLiteral(v, parser.LiteralBoolean, nil)
+	default:
+		return errorf("不支持的字面量类型 %T", value)
+	}
+	return Expr{node: literal}
+}
+
+// =============================================================================
+// 函数与操作符
+// =============================================================================
+
+// aggregateFunctions 聚合函数，不能出现在 WHERE、JOIN 条件和 GROUP BY 中
+var aggregateFunctions = map[string]bool{
+	"SUM": true, "COUNT": true, "AVG": true, "MIN": true, "MAX": true,
+}
+
+// Func 函数调用，函数名转为大写
+func Func(name string, args ...Expr) Expr {
+	if name == "" {
+		return errorf("函数名不能为空")
+	}
+	op := parser.NewSqlOperator(strings.ToUpper(name), parser.SqlKindCall, parser.SyntaxFunction)
+	return call(op, args...)
+}
+
+// Sum SUM(e)
+func Sum(e Expr) Expr { return Func("SUM", e) }
+
+// Count COUNT(e)，COUNT(*) 写作 Count(Star())
+func Count(e Expr) Expr { return Func("COUNT", e) }
+
+// Avg AVG(e)
+func Avg(e Expr) Expr { return Func("AVG", e) }
+
+// Min MIN(e)
+func Min(e Expr) Expr { return Func("MIN", e) }
+
+// Max MAX(e)
+func Max(e Expr) Expr { return Func("MAX", e) }
+
+// Cast CAST(e AS dataType)
+func Cast(e Expr, dataType string) Expr {
+	if dataType == "" {
+		return errorf("CAST 的目标类型不能为空")
+	}
+	op := parser.NewSqlOperator("CAST", parser.SqlKindCast, parser.SyntaxSpecial)
+	return call(op, e, Expr{node: parser.NewSqlLiteral(dataType, parser.LiteralSymbol, nil)})
+}
+
+// Eq left = right
+func Eq(left, right Expr) Expr { return binary("=", parser.SqlKindEquals, left, right) }
+
+// Ne left <> right
+func Ne(left, right Expr) Expr { return binary("<>", parser.SqlKindNotEquals, left, right) }
+
+// Gt left > right
+func Gt(left, right Expr) Expr { return binary(">", parser.SqlKindGreaterThan, left, right) }
+
+// Lt left < right
+func Lt(left, right Expr) Expr { return binary("<", parser.SqlKindLessThan, left, right) }
+
+// Ge left >= right
+func Ge(left, right Expr) Expr { return binary(">=", parser.SqlKindGreaterThanOrEqual, left, right) }
+
+// Le left <= right
+func Le(left, right Expr) Expr { return binary("<=", parser.SqlKindLessThanOrEqual, left, right) }
+
+// Add left + right
+func Add(left, right Expr) Expr { return binary("+", parser.SqlKindPlus, left, right) }
+
+// Sub left - right
+func Sub(left, right Expr) Expr { return binary("-", parser.SqlKindMinus, left, right) }
+
+// Mul left * right
+func Mul(left, right Expr) Expr { return binary("*", parser.SqlKindTimes, left, right) }
+
+// Div left / right
+func Div(left, right Expr) Expr { return binary("/", parser.SqlKindDivide, left, right) }
+
+// And 用 AND 连接条件，组成左深树
+func And(conds ...Expr) Expr { return chain("AND", parser.SqlKindAnd, conds) }
+
+// Or 用 OR 连接条件，组成左深树
+func Or(conds ...Expr) Expr { return chain("OR", parser.SqlKindOr, conds) }
+
+// Not NOT e
+func Not(e Expr) Expr {
+	return call(parser.NewSqlOperator("NOT", parser.SqlKindNot, parser.SyntaxPrefix), e)
+}
+
+// IsNull e IS NULL
+func IsNull(e Expr) Expr {
+	return call(parser.NewSqlOperator("IS NULL", parser.SqlKindOther, parser.SyntaxPostfix), e)
+}
+
+// IsNotNull e IS NOT NULL
+func IsNotNull(e Expr) Expr {
+	return call(parser.NewSqlOperator("IS NOT NULL", parser.SqlKindOther, parser.SyntaxPostfix), e)
+}
+
+// In e IN (items...)
+func In(e Expr, items ...Expr) Expr {
+	return in(parser.NewSqlOperator("IN", parser.SqlKindIn, parser.SyntaxSpecial), e, items)
+}
+
+// NotIn e NOT IN (items...)
+func NotIn(e Expr, items ...Expr) Expr {
+	return in(parser.NewSqlOperator("NOT IN", parser.SqlKindNotIn, parser.SyntaxSpecial), e, items)
+}
+
+// call 构造以 args 为操作数的调用，任一操作数有错误时返回该错误
+func call(op *parser.SqlOperator, args ...Expr) Expr {
+	operands, err := operands(args)
+	if err != nil {
+		return Expr{err: err}
+	}
+	return Expr{node: parser.NewSqlCall(op, operands, nil)}
+}
+
+// operands 取出各表达式的操作数节点
+func operands(args []Expr) ([]parser.SqlNode, error) {
+	nodes := make([]parser.SqlNode, len(args))
+	for i, arg := range args {
+		node, err := arg.operand()
+		if err != nil {
+			return nil, err
+		}
+		nodes[i] = node
+	}
+	return nodes, nil
+}
+
+// binary 构造二元操作符调用
+func binary(name string, kind parser.SqlKind, left, right Expr) Expr {
+	return call(parser.NewSqlOperator(name, kind, parser.SyntaxBinary), left, right)
+}
+
+// chain 用同一个二元操作符把条件连接为左深树，只有一个条件时原样返回
+func chain(name string, kind parser.SqlKind, conds []Expr) Expr {
+	if len(conds) == 0 {
+		return errorf("%s 至少需要一个条件", name)
+	}
+	result := conds[0]
+	if _, err := result.operand(); err != nil {
+		return Expr{err: err}
+	}
+	for _, cond := range conds[1:] {
+		result = binary(name, kind, result, cond)
+	}
+	return result
+}
+
+// in 构造 IN / NOT IN，列表表示为 SqlNodeList
+func in(op *parser.SqlOperator, e Expr, items []Expr) Expr {
+	if len(items) == 0 {
+		return errorf("%s 列表不能为空", op.Name)
+	}
+	list, err := operands(items)
+	if err != nil {
+		return Expr{err: err}
+	}
+	return call(op, e, Expr{node: parser.NewSqlNodeList(list, nil)})
+}
+
+// =============================================================================
+// OrderItem - 排序项
+// =============================================================================
+
+// Sortable 可用于 ORDER BY 的表达式或排序项
+type Sortable interface {
+	orderNode() (parser.SqlNode, error)
+}
+
+// OrderItem 排序项，与解析器一样把 DESC 和 NULLS FIRST / NULLS LAST 表示为后缀操作符
+type OrderItem struct {
+	node  parser.SqlNode
+	nulls bool // 已指定 NULLS FIRST / NULLS LAST
+	err   error
+}
+
+// Asc 升序排序项
+func (e Expr) Asc() OrderItem {
+	node, err := e.operand()
+	return OrderItem{node: node, err: err}
+}
+
+// Desc 降序排序项
+func (e Expr) Desc() OrderItem {
+	item := e.Asc()
+	if item.err == nil {
+		op := parser.NewSqlOperator("DESC", parser.SqlKindDescending, parser.SyntaxPostfix)
+		item.node = parser.NewSqlCall(op, []parser.SqlNode{item.node}, nil)
+	}
+	return item
+}
+
+// NullsFirst 空值排在最前
+func (o OrderItem) NullsFirst() OrderItem {
+	return o.withNulls(parser.NewSqlOperator("NULLS FIRST", parser.SqlKindNullsFirst, parser.SyntaxPostfix))
+}
+
+// NullsLast 空值排在最后
+func (o OrderItem) NullsLast() OrderItem {
+	return o.withNulls(parser.NewSqlOperator("NULLS LAST", parser.SqlKindNullsLast, parser.SyntaxPostfix))
+}
+
+// withNulls 用空值排序操作符包裹排序项
+func (o OrderItem) withNulls(op *parser.SqlOperator) OrderItem {
+	switch {
+	case o.err != nil:
+		return o
+	case o.nulls:
+		return OrderItem{err: errors.New("排序项只能指定一次 NULLS FIRST / NULLS LAST")}
+	}
+	return OrderItem{node: parser.NewSqlCall(op, []parser.SqlNode{o.node}, nil), nulls: true}
+}
+
+func (o OrderItem) orderNode() (parser.SqlNode, error) {
+	if o.err == nil && o.node == nil {
+		return nil, errors.New("排序项为空")
+	}
+	return o.node, o.err
+}
+
+func (e Expr) orderNode() (parser.SqlNode, error) {
+	return e.operand()
+}
@@ -0,0 +1,124 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"go-job-service/parser"
+)
+
+// ident 构造无位置信息的标识符
+func ident(names ...string) *parser.SqlIdentifier {
+	return parser.NewSqlIdentifier(names, nil)
+}
+
+// TestExprNodes 测试表达式生成与解析器相同形状的节点
+func TestExprNodes(t *testing.T) {
+	eq := parser.NewSqlOperator("=", parser.SqlKindEquals, parser.SyntaxBinary)
+	and := parser.NewSqlOperator("AND", parser.SqlKindAnd, parser.SyntaxBinary)
+	one := parser.NewSqlLiteral(int64(1), parser.LiteralInteger, nil)
+
+	tests := []struct {
+		name     string
+		expr     Expr
+		expected parser.SqlNode
+	}{
+		{"多部分列名", Col("plat1.atest.id"), ident("plat1", "atest", "id")},
+		{"星号", Col("t.*"), ident("t", "*")},
+		{"整数", Lit(1), one},
+		{"小数", Lit(1.5), parser.NewSqlLiteral(1.5, parser.LiteralDecimal, nil)},
+		{"字符串", Lit("x"), parser.NewSqlLiteral("x", parser.LiteralString, nil)},
+		{"布尔", Lit(true), parser.NewSqlLiteral(true, parser.LiteralBoolean, nil)},
+		{"NULL", Lit(nil), parser.NewSqlLiteral(nil, parser.LiteralNull, nil)},
+		{"函数名大写", Func("coalesce", Col("a"), Lit(1)),
+			parser.NewSqlCall(parser.NewSqlOperator("COALESCE", parser.SqlKindCall, parser.SyntaxFunction),
+				[]parser.SqlNode{ident("a"), one}, nil)},
+		{"比较", Eq(Col("a"), Lit(1)), parser.NewSqlCall(eq, []parser.SqlNode{ident("a"), one}, nil)},
+		{"AND 左深", And(Col("a"), Col("b"), Col("c")),
+			parser.NewSqlCall(and, []parser.SqlNode{
+				parser.NewSqlCall(and, []parser.SqlNode{ident("a"), ident("b")}, nil), ident("c")}, nil)},
+		{"IN", In(Col("a"), Lit(1), Lit(2)),
+			parser.NewSqlCall(parser.NewSqlOperator("IN", parser.SqlKindIn, parser.SyntaxSpecial), []parser.SqlNode{
+				ident("a"), parser.NewSqlNodeList([]parser.SqlNode{one, parser.NewSqlLiteral(int64(2), parser.LiteralInteger, nil)}, nil)}, nil)},
+		{"CAST", Cast(Col("a"), "BIGINT"),
+			parser.NewSqlCall(parser.NewSqlOperator("CAST", parser.SqlKindCast, parser.SyntaxSpecial),
+				[]parser.SqlNode{ident("a"), parser.NewSqlLiteral("BIGINT", parser.LiteralSymbol, nil)}, nil)},
+	}
+	for _, tt := range tests {
+		if err := tt.expr.Err(); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !parser.Equal(tt.expr.Node(), tt.expected, parser.EqualOptions{}) {
+			t.Errorf("%s: 节点 = %s, 期望 %s", tt.name, parser.Unparse(tt.expr.Node()), parser.Unparse(tt.expected))
+		}
+	}
+}
+
+// TestExprUnparse 测试表达式的输出
+func TestExprUnparse(t *testing.T) {
+	tests := []struct {
+		expr     Expr
+		expected string
+	}{
+		{Mul(Add(Col("a"), Lit(1)), Col("b")), "(a + 1) * b"},
+		{Or(And(Ge(Col("a"), Lit(1)), Le(Col("a"), Lit(9))), IsNull(Col("a"))), "a >= 1 AND a <= 9 OR a IS NULL"},
+		{Not(NotIn(Col("s"), Lit("x"), Lit("y"))), "NOT s NOT IN ('x', 'y')"},
+		{Ne(Div(Sum(Col("v")), Count(Star())), Lit(0.5)), "SUM(v) / COUNT(*) <> 0.5"},
+	}
+	for _, tt := range tests {
+		if err := tt.expr.Err(); err != nil {
+			t.Fatalf("%s: %v", tt.expected, err)
+		}
+		if got := parser.Unparse(tt.expr.Node()); got != tt.expected {
+			t.Errorf("Unparse = %q, 期望 %q", got, tt.expected)
+		}
+	}
+}
+
+// TestExprErrors 测试构造表达式时的错误
+func TestExprErrors(t *testing.T) {
+	tests := map[string]struct {
+		expr    Expr
+		message string
+	}{
+		"空列名":       {Col(""), "名字不能为空"},
+		"空的部分":      {Col("a..b"), "空的部分"},
+		"星号位置":      {Col("*.a"), "* 只能在最后"},
+		"字面量类型":     {Lit([]int{1}), "不支持的字面量类型"},
+		"空函数名":      {Func(""), "函数名不能为空"},
+		"操作数中的别名":   {Eq(Col("a").As("x"), Lit(1)), "别名 x 只能用于 SELECT 列表"},
+		"空表达式":      {Sum(Expr{}), "表达式为空"},
+		"空 IN 列表":   {In(Col("a")), "IN 列表不能为空"},
+		"空 AND":     {And(), "AND 至少需要一个条件"},
+		"错误向外传递":    {And(Col("a"), Not(Col(""))), "名字不能为空"},
+		"星号别名":      {Star().As("x"), "* 不能设置别名"},
+		"空别名":       {Col("a").As(""), "别名不能为空"},
+		"CAST 目标类型": {Cast(Col("a"), ""), "目标类型不能为空"},
+	}
+	for name, tt := range tests {
+		if err := tt.expr.Err(); err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%s: 错误 = %v, 期望包含 %q", name, err, tt.message)
+		}
+	}
+}
+
+// TestOrderItem 测试排序项
+func TestOrderItem(t *testing.T) {
+	desc := parser.NewSqlOperator("DESC", parser.SqlKindDescending, parser.SyntaxPostfix)
+	nullsLast := parser.NewSqlOperator("NULLS LAST", parser.SqlKindNullsLast, parser.SyntaxPostfix)
+	expected := parser.NewSqlCall(nullsLast, []parser.SqlNode{parser.NewSqlCall(desc, []parser.SqlNode{ident("a")}, nil)}, nil)
+
+	node, err := Col("a").Desc().NullsLast().orderNode()
+	if err != nil || !parser.Equal(node, expected, parser.EqualOptions{}) {
+		t.Errorf("排序项 = %s, %v", parser.Unparse(node), err)
+	}
+	if node, err := Col("a").Asc().orderNode(); err != nil || !parser.Equal(node, ident("a"), parser.EqualOptions{}) {
+		t.Errorf("升序排序项 = %s, %v", parser.Unparse(node), err)
+	}
+	if _, err := Col("a").Asc().NullsFirst().NullsLast().orderNode(); err == nil {
+		t.Error("重复指定 NULLS 应返回错误")
+	}
+	if _, err := (OrderItem{}).orderNode(); err == nil {
+		t.Error("空排序项应返回错误")
+	}
+}
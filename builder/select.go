@@ -0,0 +1,431 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-job-service/parser"
+)
+
+// =============================================================================
+// Relation - FROM 中的表和子查询
+// =============================================================================
+
+// Relation 表或子查询，用于 FROM 和 JOIN
+type Relation struct {
+	node  parser.SqlNode
+	name  string // 表名，子查询为空
+	alias string
+	err   error
+}
+
+// Table 表引用，name 按 . 拆分，如 "db.t"
+func Table(name string) Relation {
+	names, err := splitName(name)
+	if err == nil && names[len(names)-1] == "*" {
+		err = fmt.Errorf("表名 %q 不能含有 *", name)
+	}
+	if err != nil {
+		return Relation{err: err}
+	}
+	return Relation{node: parser.NewSqlIdentifier(names, nil), name: name}
+}
+
+// Subquery 子查询，必须用 As 设置别名
+func Subquery(query *SelectBuilder) Relation {
+	sel, err := query.Build()
+	if err != nil {
+		return Relation{err: fmt.Errorf("子查询: %w", err)}
+	}
+	return Relation{node: sel}
+}
+
+// As 设置别名
+func (r Relation) As(alias string) Relation {
+	if r.err == nil && alias == "" {
+		r.err = errors.New("别名不能为空")
+	}
+	r.alias = alias
+	return r
+}
+
+// key 返回关系在 FROM 中的名字：有别名时为别名，否则为表名
+func (r Relation) key() string {
+	if r.alias != "" {
+		return strings.ToLower(r.alias)
+	}
+	return strings.ToLower(r.name)
+}
+
+// source 返回 FROM 中的节点，有别名时与解析器一样包上 AS 调用
+func (r Relation) source() (parser.SqlNode, error) {
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case r.node == nil:
+		return nil, errors.New("关系为空")
+	case r.alias != "":
+		return asCall(r.node, r.alias), nil
+	case r.name == "":
+		return nil, errors.New("子查询必须有别名")
+	}
+	return r.node, nil
+}
+
+// =============================================================================
+// SelectBuilder - SELECT 语句
+// =============================================================================
+
+// SelectBuilder 以链式调用构造 SELECT 语句
+//
+// 各方法记录遇到的错误并返回自身，Build 时一并返回，例如：
+//
+//	Select(Col("a.id"), Sum(Col("a.v")).As("tot")).
+//		From(Table("db.t").As("a")).
+//		Where(Gt(Col("a.v"), Lit(0))).
+//		GroupBy(Col("a.id"))
+type SelectBuilder struct {
+	sel     *parser.SqlSelect
+	sources map[string]bool // FROM 中已有的表名和别名
+	errs    []error
+}
+
+// Select 以 items 为 SELECT 列表开始构造查询
+func Select(items ...Expr) *SelectBuilder {
+	b := &SelectBuilder{sel: parser.NewSqlSelect(nil), sources: make(map[string]bool)}
+	if len(items) == 0 {
+		b.fail("SELECT", errors.New("列表不能为空"))
+	}
+	for _, item := range items {
+		node, err := item.selectItem()
+		if err != nil {
+			b.fail("SELECT", err)
+			continue
+		}
+		b.sel.SelectList = append(b.sel.SelectList, node)
+	}
+	return b
+}
+
+// Distinct SELECT DISTINCT
+func (b *SelectBuilder) Distinct() *SelectBuilder {
+	for _, keyword := range b.sel.KeywordList {
+		if keyword == "DISTINCT" {
+			return b
+		}
+	}
+	b.sel.KeywordList = append(b.sel.KeywordList, "DISTINCT")
+	return b
+}
+
+// Hint 添加优化器提示，如 Hint("JOIN", Col("TEE")) 输出 /*+ JOIN(TEE) */
+func (b *SelectBuilder) Hint(name string, params ...Expr) *SelectBuilder {
+	if name == "" {
+		return b.fail("HINT", errors.New("名称不能为空"))
+	}
+	nodes, err := operands(params)
+	if err != nil {
+		return b.fail("HINT "+name, err)
+	}
+	b.sel.Hints = append(b.sel.Hints, parser.NewSqlHint(name, nodes, nil))
+	return b
+}
+
+// From 设置 FROM 子句，多个关系与解析器一样以逗号连接
+func (b *SelectBuilder) From(relations ...Relation) *SelectBuilder {
+	if b.sel.From != nil {
+		return b.fail("FROM", errors.New("只能调用一次"))
+	}
+	if len(relations) == 0 {
+		return b.fail("FROM", errors.New("至少需要一个表"))
+	}
+	for _, relation := range relations {
+		node, err := b.addSource(relation)
+		if err != nil {
+			b.fail("FROM", err)
+			continue
+		}
+		if b.sel.From == nil {
+			b.sel.From = node
+		} else {
+			b.sel.From = parser.NewSqlJoin(b.sel.From, node, parser.JoinComma, nil, nil)
+		}
+	}
+	return b
+}
+
+// Join INNER JOIN relation ON on
+// JOIN 系列方法生成的 SqlJoin 与解析器对显式 JOIN 的输出相同，连接到 FROM 中逗号分隔的最后一个关系上
+func (b *SelectBuilder) Join(relation Relation, on Expr) *SelectBuilder {
+	return b.join(parser.JoinInner, relation, on)
+}
+
+// LeftJoin LEFT JOIN relation ON on
+func (b *SelectBuilder) LeftJoin(relation Relation, on Expr) *SelectBuilder {
+	return b.join(parser.JoinLeft, relation, on)
+}
+
+// RightJoin RIGHT JOIN relation ON on
+func (b *SelectBuilder) RightJoin(relation Relation, on Expr) *SelectBuilder {
+	return b.join(parser.JoinRight, relation, on)
+}
+
+// FullJoin FULL JOIN relation ON on
+func (b *SelectBuilder) FullJoin(relation Relation, on Expr) *SelectBuilder {
+	return b.join(parser.JoinFull, relation, on)
+}
+
+// CrossJoin CROSS JOIN relation
+func (b *SelectBuilder) CrossJoin(relation Relation) *SelectBuilder {
+	b.appendJoin(parser.JoinCross, relation)
+	return b
+}
+
+// JoinUsing joinType JOIN relation USING (columns...)
+func (b *SelectBuilder) JoinUsing(joinType parser.JoinType, relation Relation, columns ...string) *SelectBuilder {
+	switch {
+	case joinType == parser.JoinCross || joinType == parser.JoinComma:
+		return b.fail("JOIN", fmt.Errorf("%s JOIN 不能使用 USING", joinType))
+	case len(columns) == 0:
+		return b.fail("JOIN", errors.New("USING 至少需要一列"))
+	}
+	using := make([]parser.SqlNode, len(columns))
+	for i, column := range columns {
+		if column == "" || strings.Contains(column, ".") || column == "*" {
+			return b.fail("JOIN", fmt.Errorf("USING 列名 %q 无效", column))
+		}
+		using[i] = parser.NewSqlIdentifier([]string{column}, nil)
+	}
+	if join := b.appendJoin(joinType, relation); join != nil {
+		join.Using = using
+	}
+	return b
+}
+
+// join 添加带 ON 条件的 JOIN
+func (b *SelectBuilder) join(joinType parser.JoinType, relation Relation, on Expr) *SelectBuilder {
+	condition, err := on.operand()
+	if err != nil {
+		return b.fail(string(joinType)+" JOIN ON", err)
+	}
+	if name := findAggregate(condition); name != "" {
+		return b.fail(string(joinType)+" JOIN ON", fmt.Errorf("不能使用聚合函数 %s", name))
+	}
+	if join := b.appendJoin(joinType, relation); join != nil {
+		join.Condition = condition
+	}
+	return b
+}
+
+// appendJoin 把 relation 连接到 FROM 的右侧，JOIN 左结合；出错时记录错误并返回 nil
+// 与解析器一致，JOIN 比逗号结合得更紧：From(a, b).Join(c, on) 为 a, (b JOIN c ON on)
+func (b *SelectBuilder) appendJoin(joinType parser.JoinType, relation Relation) *parser.SqlJoin {
+	clause := string(joinType) + " JOIN"
+	if b.sel.From == nil {
+		b.fail(clause, errors.New("需要先调用 From"))
+		return nil
+	}
+	node, err := b.addSource(relation)
+	if err != nil {
+		b.fail(clause, err)
+		return nil
+	}
+	if comma, ok := b.sel.From.(*parser.SqlJoin); ok && comma.JoinType == parser.JoinComma {
+		join := parser.NewSqlJoin(comma.Right, node, joinType, nil, nil)
+		comma.Right = join
+		return join
+	}
+	join := parser.NewSqlJoin(b.sel.From, node, joinType, nil, nil)
+	b.sel.From = join
+	return join
+}
+
+// addSource 检查关系并记录其名字，同一个名字只能出现一次
+func (b *SelectBuilder) addSource(relation Relation) (parser.SqlNode, error) {
+	node, err := relation.source()
+	if err != nil {
+		return nil, err
+	}
+	key := relation.key()
+	if b.sources[key] {
+		return nil, fmt.Errorf("%s 在 FROM 中出现多次", key)
+	}
+	b.sources[key] = true
+	return node, nil
+}
+
+// Where 添加 WHERE 条件，多个条件（包括多次调用）以 AND 连接
+func (b *SelectBuilder) Where(conds ...Expr) *SelectBuilder {
+	b.sel.Where = b.appendCondition("WHERE", b.sel.Where, conds)
+	return b
+}
+
+// Having 添加 HAVING 条件，多个条件（包括多次调用）以 AND 连接
+func (b *SelectBuilder) Having(conds ...Expr) *SelectBuilder {
+	b.sel.Having = b.appendCondition("HAVING", b.sel.Having, conds)
+	return b
+}
+
+// appendCondition 把 conds 以 AND 连接到已有的条件之后
+func (b *SelectBuilder) appendCondition(clause string, existing parser.SqlNode, conds []Expr) parser.SqlNode {
+	if existing != nil {
+		conds = append([]Expr{{node: existing}}, conds...)
+	}
+	cond, err := And(conds...).operand()
+	if err != nil {
+		b.fail(clause, err)
+		return existing
+	}
+	return cond
+}
+
+// GroupBy 添加 GROUP BY 表达式
+func (b *SelectBuilder) GroupBy(exprs ...Expr) *SelectBuilder {
+	nodes, err := operands(exprs)
+	if err != nil {
+		return b.fail("GROUP BY", err)
+	}
+	b.sel.GroupBy = append(b.sel.GroupBy, nodes...)
+	return b
+}
+
+// OrderBy 添加排序项，Expr 按升序排序
+func (b *SelectBuilder) OrderBy(items ...Sortable) *SelectBuilder {
+	for _, item := range items {
+		node, err := item.orderNode()
+		if err != nil {
+			b.fail("ORDER BY", err)
+			continue
+		}
+		b.sel.OrderBy = append(b.sel.OrderBy, node)
+	}
+	return b
+}
+
+// Limit LIMIT n
+func (b *SelectBuilder) Limit(n int64) *SelectBuilder {
+	if n < 0 {
+		return b.fail("LIMIT", fmt.Errorf("行数 %d 不能为负数", n))
+	}
+	b.sel.Fetch = parser.NewSqlLiteral(n, parser.LiteralInteger, nil)
+	return b
+}
+
+// Offset OFFSET n
+func (b *SelectBuilder) Offset(n int64) *SelectBuilder {
+	if n < 0 {
+		return b.fail("OFFSET", fmt.Errorf("行数 %d 不能为负数", n))
+	}
+	b.sel.Offset = parser.NewSqlLiteral(n, parser.LiteralInteger, nil)
+	return b
+}
+
+// fail 记录 clause 中的错误
+func (b *SelectBuilder) fail(clause string, err error) *SelectBuilder {
+	b.errs = append(b.errs, fmt.Errorf("%s: %w", clause, err))
+	return b
+}
+
+// =============================================================================
+// 构造与输出
+// =============================================================================
+
+// Build 校验并返回 SELECT 语句
+//
+// 除构造时记录的错误外，还检查：
+//   - SELECT 列表不能为空
+//   - WHERE 和 GROUP BY 中不能使用聚合函数
+//   - HAVING 需要 GROUP BY，或者 SELECT 列表、HAVING 中有聚合函数
+//
+// 返回的语法树是副本，之后继续调用 b 的方法不影响已返回的结果；没有 FROM 时与解析器一样使用 DUAL
+func (b *SelectBuilder) Build() (*parser.SqlSelect, error) {
+	errs := append([]error{}, b.errs...)
+	sel := b.sel
+	if name := findAggregate(sel.Where); name != "" {
+		errs = append(errs, fmt.Errorf("WHERE: 不能使用聚合函数 %s", name))
+	}
+	for _, node := range sel.GroupBy {
+		if name := findAggregate(node); name != "" {
+			errs = append(errs, fmt.Errorf("GROUP BY: 不能使用聚合函数 %s", name))
+		}
+	}
+	if sel.Having != nil && len(sel.GroupBy) == 0 && findAggregate(sel.Having) == "" && !hasAggregate(sel.SelectList) {
+		errs = append(errs, errors.New("HAVING: 需要 GROUP BY 或聚合函数"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	result := sel.Clone().(*parser.SqlSelect)
+	if result.From == nil {
+		result.From = parser.NewSqlIdentifier([]string{"DUAL"}, nil)
+	}
+	return result, nil
+}
+
+// MustBuild 同 Build，出错时 panic
+func (b *SelectBuilder) MustBuild() *parser.SqlSelect {
+	sel, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return sel
+}
+
+// SQL 校验并输出 Spark SQL
+func (b *SelectBuilder) SQL() (string, error) {
+	return b.SQLWithDialect(parser.SparkDialect)
+}
+
+// SQLWithDialect 校验并按方言输出 SQL
+func (b *SelectBuilder) SQLWithDialect(dialect parser.SqlDialect) (string, error) {
+	sel, err := b.Build()
+	if err != nil {
+		return "", err
+	}
+	return parser.UnparseWithDialect(sel, dialect), nil
+}
+
+// String 输出 SQL，有错误时输出错误信息
+func (b *SelectBuilder) String() string {
+	sql, err := b.SQL()
+	if err != nil {
+		return "<无效的查询: " + err.Error() + ">"
+	}
+	return sql
+}
+
+// findAggregate 返回 node 中第一个聚合函数的名字，没有时返回空字符串
+// 子查询中的聚合函数不计入
+func findAggregate(node parser.SqlNode) string {
+	name := ""
+	parser.Inspect(node, func(child parser.SqlNode) bool {
+		if name != "" {
+			return false
+		}
+		switch n := child.(type) {
+		case nil:
+			return false
+		case *parser.SqlSelect:
+			return n == node
+		case *parser.SqlCall:
+			if n.Operator != nil && n.Operator.Syntax == parser.SyntaxFunction && aggregateFunctions[n.Operator.Name] {
+				name = n.Operator.Name
+				return false
+			}
+		}
+		return true
+	})
+	return name
+}
+
+// hasAggregate 判断列表中是否有聚合函数
+func hasAggregate(nodes []parser.SqlNode) bool {
+	for _, node := range nodes {
+		if findAggregate(node) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package builder
+
+import (
+	"strings"
+	"testing"
+
+	"go-job-service/parser"
+)
+
+// buildSelectTestQuery 构造带 JOIN、分组和排序的查询
+func buildSelectTestQuery() *SelectBuilder {
+	return Select(Col("a.id"), Sum(Col("b.v")).As("tot")).
+		Hint("JOIN", Col("TEE")).
+		From(Table("plat1.atest").As("a")).
+		LeftJoin(Table("plat1.btest").As("b"), Eq(Col("a.id"), Col("b.id"))).
+		Where(Gt(Col("a.dt"), Lit("2024-01-01"))).
+		Where(IsNotNull(Col("b.v"))).
+		GroupBy(Col("a.id")).
+		Having(Gt(Sum(Col("b.v")), Lit(100))).
+		OrderBy(Col("tot").Desc(), Col("a.id")).
+		Limit(10)
+}
+
+// TestSelectNodes 测试生成与解析器相同形状的 SELECT 语法树
+func TestSelectNodes(t *testing.T) {
+	sel, err := buildSelectTestQuery().Build()
+	if err != nil {
+		t.Fatalf("构造失败: %v", err)
+	}
+
+	as := parser.NewSqlOperator("AS", parser.SqlKindAs, parser.SyntaxSpecial)
+	eq := parser.NewSqlOperator("=", parser.SqlKindEquals, parser.SyntaxBinary)
+	gt := parser.NewSqlOperator(">", parser.SqlKindGreaterThan, parser.SyntaxBinary)
+	sum := func() *parser.SqlCall {
+		return parser.NewSqlCall(parser.NewSqlOperator("SUM", parser.SqlKindCall, parser.SyntaxFunction),
+			[]parser.SqlNode{ident("b", "v")}, nil)
+	}
+
+	expected := parser.NewSqlSelect(nil)
+	expected.Hints = []*parser.SqlHint{parser.NewSqlHint("JOIN", []parser.SqlNode{ident("TEE")}, nil)}
+	expected.SelectList = []parser.SqlNode{ident("a", "id"), parser.NewSqlCall(as, []parser.SqlNode{sum(), ident("tot")}, nil)}
+	expected.From = parser.NewSqlJoin(
+		parser.NewSqlCall(as, []parser.SqlNode{ident("plat1", "atest"), ident("a")}, nil),
+		parser.NewSqlCall(as, []parser.SqlNode{ident("plat1", "btest"), ident("b")}, nil),
+		parser.JoinLeft, parser.NewSqlCall(eq, []parser.SqlNode{ident("a", "id"), ident("b", "id")}, nil), nil)
+	expected.Where = parser.NewSqlCall(parser.NewSqlOperator("AND", parser.SqlKindAnd, parser.SyntaxBinary), []parser.SqlNode{
+		parser.NewSqlCall(gt, []parser.SqlNode{ident("a", "dt"), parser.NewSqlLiteral("2024-01-01", parser.LiteralString, nil)}, nil),
+		parser.NewSqlCall(parser.NewSqlOperator("IS NOT NULL", parser.SqlKindOther, parser.SyntaxPostfix), []parser.SqlNode{ident("b", "v")}, nil),
+	}, nil)
+	expected.GroupBy = []parser.SqlNode{ident("a", "id")}
+	expected.Having = parser.NewSqlCall(gt, []parser.SqlNode{sum(), parser.NewSqlLiteral(int64(100), parser.LiteralInteger, nil)}, nil)
+	expected.OrderBy = []parser.SqlNode{
+		parser.NewSqlCall(parser.NewSqlOperator("DESC", parser.SqlKindDescending, parser.SyntaxPostfix), []parser.SqlNode{ident("tot")}, nil),
+		ident("a", "id"),
+	}
+	expected.Fetch = parser.NewSqlLiteral(int64(10), parser.LiteralInteger, nil)
+
+	if !parser.Equal(sel, expected, parser.EqualOptions{}) {
+		t.Errorf("语法树 =\n%s\n期望\n%s", parser.Unparse(sel), parser.Unparse(expected))
+	}
+}
+
+// TestSelectSQL 测试通过 Unparse 输出 SQL
+func TestSelectSQL(t *testing.T) {
+	tests := []struct {
+		query    *SelectBuilder
+		expected string
+	}{
+		{
+			buildSelectTestQuery(),
+			"SELECT /*+ JOIN(TEE) */ a.id, SUM(b.v) AS tot FROM plat1.atest AS a LEFT JOIN plat1.btest AS b ON a.id = b.id " +
+				"WHERE a.dt > '2024-01-01' AND b.v IS NOT NULL GROUP BY a.id HAVING SUM(b.v) > 100 ORDER BY tot DESC, a.id LIMIT 10",
+		},
+		{
+			Select(Star()).Distinct().Distinct().From(Table("a"), Table("b")).CrossJoin(Table("c")).
+				JoinUsing(parser.JoinFull, Table("d"), "id", "dt").Limit(5).Offset(10),
+			"SELECT DISTINCT * FROM a, (b CROSS JOIN c FULL JOIN d USING (id, dt)) LIMIT 5 OFFSET 10",
+		},
+		{
+			Select(Col("t.n")).From(Subquery(Select(Count(Star()).As("n")).From(Table("x"))).As("t")).
+				OrderBy(Col("t.n").Asc().NullsFirst()),
+			"SELECT t.n FROM (SELECT COUNT(*) AS n FROM x) AS t ORDER BY t.n NULLS FIRST",
+		},
+		{Select(Lit(1)), "SELECT 1"},
+	}
+	for _, tt := range tests {
+		sql, err := tt.query.SQL()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expected, err)
+		}
+		if sql != tt.expected {
+			t.Errorf("SQL =\n%s\n期望\n%s", sql, tt.expected)
+		}
+		if tt.query.String() != sql {
+			t.Errorf("String() = %q", tt.query.String())
+		}
+	}
+
+	sql, err := Select(Col("a")).From(Table("t")).OrderBy(Col("a")).Limit(1).Offset(2).SQLWithDialect(parser.TrinoDialect)
+	if err != nil || !strings.HasSuffix(sql, "OFFSET 2 ROWS FETCH NEXT 1 ROWS ONLY") {
+		t.Errorf("方言输出 = %q, %v", sql, err)
+	}
+}
+
+// TestSelectErrors 测试构造时的校验
+func TestSelectErrors(t *testing.T) {
+	tests := map[string]struct {
+		query   *SelectBuilder
+		message string
+	}{
+		"空 SELECT 列表":      {Select().From(Table("t")), "SELECT: 列表不能为空"},
+		"列表项错误":            {Select(Col("")), "SELECT: 名字不能为空"},
+		"JOIN 之前没有 FROM":   {Select(Star()).Join(Table("t"), Eq(Col("a"), Col("b"))), "INNER JOIN: 需要先调用 From"},
+		"重复的别名":            {Select(Star()).From(Table("a").As("x")).Join(Table("b").As("X"), Lit(true)), "x 在 FROM 中出现多次"},
+		"重复的表":             {Select(Star()).From(Table("db.t"), Table("db.t")), "db.t 在 FROM 中出现多次"},
+		"两次 FROM":          {Select(Star()).From(Table("a")).From(Table("b")), "FROM: 只能调用一次"},
+		"表名中的星号":           {Select(Star()).From(Table("t.*")), "不能含有 *"},
+		"子查询没有别名":          {Select(Star()).From(Subquery(Select(Lit(1)))), "子查询必须有别名"},
+		"子查询中的错误":          {Select(Star()).From(Subquery(Select()).As("s")), "子查询: SELECT: 列表不能为空"},
+		"USING 列名":         {Select(Star()).From(Table("a")).JoinUsing(parser.JoinInner, Table("b"), "a.id"), "USING 列名 \"a.id\" 无效"},
+		"CROSS JOIN USING": {Select(Star()).From(Table("a")).JoinUsing(parser.JoinCross, Table("b"), "id"), "不能使用 USING"},
+		"ON 中的聚合函数":        {Select(Star()).From(Table("a")).Join(Table("b"), Gt(Max(Col("a.v")), Lit(1))), "不能使用聚合函数 MAX"},
+		"WHERE 中的聚合函数":     {Select(Col("a")).From(Table("t")).Where(Gt(Count(Star()), Lit(1))), "WHERE: 不能使用聚合函数 COUNT"},
+		"GROUP BY 中的聚合":    {Select(Col("a")).From(Table("t")).GroupBy(Sum(Col("a"))), "GROUP BY: 不能使用聚合函数 SUM"},
+		"HAVING 没有分组":      {Select(Col("a")).From(Table("t")).Having(Gt(Col("a"), Lit(1))), "HAVING: 需要 GROUP BY 或聚合函数"},
+		"负数 LIMIT":         {Select(Star()).Limit(-1), "LIMIT: 行数 -1 不能为负数"},
+		"WHERE 中的别名":       {Select(Star()).Where(Col("a").As("b")), "WHERE: 别名 b 只能用于 SELECT 列表"},
+		"空 HINT 名称":        {Select(Star()).Hint(""), "HINT: 名称不能为空"},
+	}
+	for name, tt := range tests {
+		sel, err := tt.query.Build()
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("%s: 错误 = %v, 期望包含 %q", name, err, tt.message)
+		}
+		if sel != nil {
+			t.Errorf("%s: 出错时应返回 nil", name)
+		}
+		if !strings.Contains(tt.query.String(), tt.message) {
+			t.Errorf("%s: String() = %q", name, tt.query.String())
+		}
+	}
+
+	// 多个错误一并返回
+	_, err := Select(Col("")).Join(Table("t"), Lit(true)).Limit(-1).Build()
+	if err == nil || strings.Count(err.Error(), "\n") != 2 {
+		t.Errorf("应返回全部 3 个错误, 实际为 %v", err)
+	}
+
+	// 有聚合函数时 HAVING 不需要 GROUP BY
+	if _, err := Select(Count(Star())).From(Table("t")).Having(Gt(Count(Star()), Lit(1))).Build(); err != nil {
+		t.Errorf("HAVING 校验错误: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustBuild 出错时应 panic")
+		}
+	}()
+	Select().MustBuild()
+}
+
+// TestSelectBuildCopy 测试 Build 返回副本，没有 FROM 时使用 DUAL
+func TestSelectBuildCopy(t *testing.T) {
+	query := Select(Lit(1))
+	first := query.MustBuild()
+	if id, ok := first.From.(*parser.SqlIdentifier); !ok || id.ToString() != "DUAL" {
+		t.Errorf("没有 FROM 时应使用 DUAL, 实际为 %v", first.From)
+	}
+
+	query.Where(Eq(Lit(1), Lit(1))).Limit(1)
+	if first.Where != nil || first.Fetch != nil {
+		t.Error("Build 之后的修改不应影响已返回的语法树")
+	}
+	if second := query.MustBuild(); second.Where == nil || second.Fetch == nil {
+		t.Error("修改没有生效")
+	}
+}
+
+// TestSelectMatchesParser 测试构造结果与解析结果相同
+func TestSelectMatchesParser(t *testing.T) {
+	tests := []struct {
+		sql   string
+		query *SelectBuilder
+	}{
+		{
+			"select /*+ JOIN(TEE) */ plat1.atest.id, sum(a1) as tot from plat1.atest a, plat1.btest b " +
+				"where a.id = b.id and (b.s in ('x', 'y') or b.v is null) group by plat1.atest.id having sum(a1) > 10 order by tot desc limit 5",
+			Select(Col("plat1.atest.id"), Sum(Col("a1")).As("tot")).
+				Hint("JOIN", Col("TEE")).
+				From(Table("plat1.atest").As("a"), Table("plat1.btest").As("b")).
+				Where(Eq(Col("a.id"), Col("b.id")), Or(In(Col("b.s"), Lit("x"), Lit("y")), IsNull(Col("b.v")))).
+				GroupBy(Col("plat1.atest.id")).
+				Having(Gt(Sum(Col("a1")), Lit(10))).
+				OrderBy(Col("tot").Desc()).
+				Limit(5),
+		},
+		{
+			"select cast(t.v as bigint) * 2.5, not t.f from (select v, f from x where v >= 0) t",
+			Select(Mul(Cast(Col("t.v"), "bigint"), Lit(2.5)), Not(Col("t.f"))).
+				From(Subquery(Select(Col("v"), Col("f")).From(Table("x")).Where(Ge(Col("v"), Lit(0)))).As("t")),
+		},
+		{
+			"select a.id, b.v from db.a a left join db.b b on a.id = b.id and b.v > 0 join c using (id, dt)",
+			Select(Col("a.id"), Col("b.v")).
+				From(Table("db.a").As("a")).
+				LeftJoin(Table("db.b").As("b"), And(Eq(Col("a.id"), Col("b.id")), Gt(Col("b.v"), Lit(0)))).
+				JoinUsing(parser.JoinInner, Table("c"), "id", "dt"),
+		},
+		{
+			"select * from a, b cross join c full outer join d on c.id = d.id",
+			Select(Star()).From(Table("a"), Table("b")).CrossJoin(Table("c")).FullJoin(Table("d"), Eq(Col("c.id"), Col("d.id"))),
+		},
+	}
+	for _, tt := range tests {
+		result, err := parser.ParseSQLWithAntlr(tt.sql)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", tt.sql, err)
+		}
+		built, err := tt.query.Build()
+		if err != nil {
+			t.Fatalf("构造失败: %v", err)
+		}
+		if !parser.Equal(result.SqlNode, built, parser.EqualOptions{IgnorePositions: true}) {
+			t.Errorf("构造结果与解析结果不同:\n%s\n%s", parser.Unparse(built), parser.Unparse(result.SqlNode))
+		}
+	}
+}
@@ -114,11 +114,23 @@ func demonstrateVisitorPattern() {
 	
 	// 使用自定义 Visitor 遍历 AST
 	visitor := &CustomVisitor{}
+	visitor.BaseSqlNodeVisitor = parser.NewBaseSqlNodeVisitor(visitor)
 	result.SqlNode.Accept(visitor)
+	
+	// 只关心节点本身时，Inspect 更简洁
+	parser.Inspect(result.SqlNode, func(node parser.SqlNode) bool {
+		if literal, ok := node.(*parser.SqlLiteral); ok {
+			fmt.Printf("Inspect 发现字面量: %v\n", literal.Value)
+		}
+		return true
+	})
 }
 
 // CustomVisitor 是一个自定义的 visitor 实现
-type CustomVisitor struct{}
+// 嵌入 BaseSqlNodeVisitor 后只需覆盖关心的节点，其余节点默认遍历子节点
+type CustomVisitor struct {
+	*parser.BaseSqlNodeVisitor
+}
 
 func (v *CustomVisitor) VisitIdentifier(node *parser.SqlIdentifier) (interface{}, error) {
 	fmt.Printf("发现标识符: %s\n", node.ToString())
@@ -132,42 +144,15 @@ func (v *CustomVisitor) VisitLiteral(node *parser.SqlLiteral) (interface{}, erro
 
 func (v *CustomVisitor) VisitCall(node *parser.SqlCall) (interface{}, error) {
 	fmt.Printf("发现调用: %s\n", node.Operator.Name)
-	for _, operand := range node.Operands {
-		operand.Accept(v)
-	}
-	return nil, nil
+	return v.VisitChildren(node)
 }
 
 func (v *CustomVisitor) VisitSelect(node *parser.SqlSelect) (interface{}, error) {
 	fmt.Println("发现 SELECT 语句")
-	for _, item := range node.SelectList {
-		item.Accept(v)
-	}
-	if node.From != nil {
-		node.From.Accept(v)
-	}
-	if node.Where != nil {
-		node.Where.Accept(v)
-	}
-	return nil, nil
+	return v.VisitChildren(node)
 }
 
 func (v *CustomVisitor) VisitJoin(node *parser.SqlJoin) (interface{}, error) {
 	fmt.Println("发现 JOIN")
-	return nil, nil
+	return v.VisitChildren(node)
 }
-
-func (v *CustomVisitor) VisitBasicCall(node *parser.SqlBasicCall) (interface{}, error) {
-	if node.Operand != nil {
-		node.Operand.Accept(v)
-	}
-	return nil, nil
-}
-
-func (v *CustomVisitor) VisitNodeList(node *parser.SqlNodeList) (interface{}, error) {
-	for _, item := range node.List {
-		item.Accept(v)
-	}
-	return nil, nil
-}
-
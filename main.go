@@ -44,7 +44,7 @@ func main() {
 		}
 
 		fmt.Printf("解析成功!\n")
-		fmt.Printf("SqlNode: %s\n\n", parseResult.SqlNode.ToString())
+		fmt.Printf("SqlNode:\n%s\n\n", parser.Format(parseResult.SqlNode, parser.FormatOptions{}))
 
 		// 分析 SQL
 		analysis := analyzer.AnalyzeSQL(parseResult.SqlNode)
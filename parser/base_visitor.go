@@ -0,0 +1,356 @@
+package parser
+
+// =============================================================================
+// BaseSqlNodeVisitor - 默认遍历全部子节点的访问者
+// =============================================================================
+
+// BaseSqlNodeVisitor SqlNodeVisitor 的默认实现，每个 Visit 方法都只是依次访问子节点
+//
+// 自定义访问者嵌入 *BaseSqlNodeVisitor 并只覆盖关心的方法，构造时把自身传给
+// NewBaseSqlNodeVisitor，这样默认实现递归时会回到覆盖后的方法。
+// 以后新增节点类型时，嵌入了它的访问者无需修改即可继续编译和遍历：
+//
+//	type tableCollector struct {
+//		*parser.BaseSqlNodeVisitor
+//		tables []string
+//	}
+//
+//	func (c *tableCollector) VisitTableRef(node *parser.SqlTableRef) (interface{}, error) {
+//		c.tables = append(c.tables, node.Name.ToString())
+//		return c.VisitChildren(node)
+//	}
+//
+//	c := &tableCollector{}
+//	c.BaseSqlNodeVisitor = parser.NewBaseSqlNodeVisitor(c)
+//	node.Accept(c)
+type BaseSqlNodeVisitor struct {
+	self SqlNodeVisitor
+}
+
+// NewBaseSqlNodeVisitor 创建默认访问者，self 为访问子节点时使用的访问者（通常是嵌入它的外层结构）
+// self 为 nil 时使用默认实现本身
+func NewBaseSqlNodeVisitor(self SqlNodeVisitor) *BaseSqlNodeVisitor {
+	v := &BaseSqlNodeVisitor{self: self}
+	if v.self == nil {
+		v.self = v
+	}
+	return v
+}
+
+// VisitChildren 用 self 依次访问 node 的直接子节点，遇到错误时立即返回
+func (v *BaseSqlNodeVisitor) VisitChildren(node SqlNode) (interface{}, error) {
+	var err error
+	forEachChildNode(node, func(child SqlNode) bool {
+		_, err = child.Accept(v.self)
+		return err == nil
+	})
+	return nil, err
+}
+
+// VisitIdentifier 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitLiteral 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitLiteral(node *SqlLiteral) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitCall 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitCall(node *SqlCall) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitSelect 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitSelect(node *SqlSelect) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitJoin 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitJoin(node *SqlJoin) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitBasicCall 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitBasicCall(node *SqlBasicCall) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitNodeList 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitNodeList(node *SqlNodeList) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitHint 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitHint(node *SqlHint) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitLambda 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitLambda(node *SqlLambda) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitTableRef 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitTableRef(node *SqlTableRef) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitExplain 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitExplain(node *SqlExplain) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitDescribe 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitDescribe(node *SqlDescribe) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitShow 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitShow(node *SqlShow) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitUse 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitUse(node *SqlUse) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitError 实现 SqlNodeVisitor 接口
+func (v *BaseSqlNodeVisitor) VisitError(node *SqlErrorNode) (interface{}, error) {
+	return v.VisitChildren(node)
+}
+
+// =============================================================================
+// SqlVisitor[R] - 带类型结果的访问者
+// =============================================================================
+
+// SqlVisitor 返回类型为 R 的访问者，避免在调用方对 interface{} 做类型断言
+// 通过 Visit 函数驱动；实现时通常嵌入 *BaseSqlVisitor[R] 并只覆盖关心的方法
+type SqlVisitor[R any] interface {
+	VisitIdentifier(node *SqlIdentifier) (R, error)
+	VisitLiteral(node *SqlLiteral) (R, error)
+	VisitCall(node *SqlCall) (R, error)
+	VisitSelect(node *SqlSelect) (R, error)
+	VisitJoin(node *SqlJoin) (R, error)
+	VisitBasicCall(node *SqlBasicCall) (R, error)
+	VisitNodeList(node *SqlNodeList) (R, error)
+	VisitHint(node *SqlHint) (R, error)
+	VisitLambda(node *SqlLambda) (R, error)
+	VisitTableRef(node *SqlTableRef) (R, error)
+	VisitExplain(node *SqlExplain) (R, error)
+	VisitDescribe(node *SqlDescribe) (R, error)
+	VisitShow(node *SqlShow) (R, error)
+	VisitUse(node *SqlUse) (R, error)
+	VisitError(node *SqlErrorNode) (R, error)
+}
+
+// Visit 用 visitor 访问 node 并返回类型化的结果，node 为 nil 时返回零值
+func Visit[R any](node SqlNode, visitor SqlVisitor[R]) (R, error) {
+	var zero R
+	if node == nil {
+		return zero, nil
+	}
+	result, err := node.Accept(sqlVisitorAdapter[R]{visitor: visitor})
+	if err != nil {
+		return zero, err
+	}
+	typed, _ := result.(R)
+	return typed, nil
+}
+
+// BaseSqlVisitor SqlVisitor[R] 的默认实现，参考 ANTLR 的 AbstractParseTreeVisitor
+// 每个 Visit 方法依次访问子节点，并用 Aggregate 合并子节点的结果：
+// 初始值为 R 的零值，Aggregate 为 nil 时结果为最后一个子节点的结果
+//
+//	type identifierCounter struct {
+//		*parser.BaseSqlVisitor[int]
+//	}
+//
+//	func (c *identifierCounter) VisitIdentifier(*parser.SqlIdentifier) (int, error) {
+//		return 1, nil
+//	}
+//
+//	c := &identifierCounter{}
+//	c.BaseSqlVisitor = parser.NewBaseSqlVisitor[int](c)
+//	c.Aggregate = func(total, next int) int { return total + next }
+//	count, err := parser.Visit[int](node, c)
+type BaseSqlVisitor[R any] struct {
+	self      SqlVisitor[R]
+	Aggregate func(aggregate, next R) R // 合并子节点结果
+}
+
+// NewBaseSqlVisitor 创建默认访问者，self 为访问子节点时使用的访问者（通常是嵌入它的外层结构）
+// self 为 nil 时使用默认实现本身
+func NewBaseSqlVisitor[R any](self SqlVisitor[R]) *BaseSqlVisitor[R] {
+	v := &BaseSqlVisitor[R]{self: self}
+	if v.self == nil {
+		v.self = v
+	}
+	return v
+}
+
+// VisitChildren 用 self 依次访问 node 的直接子节点并合并结果，遇到错误时立即返回
+func (v *BaseSqlVisitor[R]) VisitChildren(node SqlNode) (R, error) {
+	var aggregate R
+	var err error
+	forEachChildNode(node, func(child SqlNode) bool {
+		var next R
+		next, err = Visit(child, v.self)
+		if err != nil {
+			return false
+		}
+		if v.Aggregate != nil {
+			aggregate = v.Aggregate(aggregate, next)
+		} else {
+			aggregate = next
+		}
+		return true
+	})
+	if err != nil {
+		var zero R
+		return zero, err
+	}
+	return aggregate, nil
+}
+
+// VisitIdentifier 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitIdentifier(node *SqlIdentifier) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitLiteral 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitLiteral(node *SqlLiteral) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitCall 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitCall(node *SqlCall) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitSelect 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitSelect(node *SqlSelect) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitJoin 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitJoin(node *SqlJoin) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitBasicCall 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitBasicCall(node *SqlBasicCall) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitNodeList 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitNodeList(node *SqlNodeList) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitHint 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitHint(node *SqlHint) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitLambda 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitLambda(node *SqlLambda) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitTableRef 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitTableRef(node *SqlTableRef) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitExplain 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitExplain(node *SqlExplain) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitDescribe 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitDescribe(node *SqlDescribe) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitShow 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitShow(node *SqlShow) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitUse 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitUse(node *SqlUse) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// VisitError 实现 SqlVisitor 接口
+func (v *BaseSqlVisitor[R]) VisitError(node *SqlErrorNode) (R, error) {
+	return v.VisitChildren(node)
+}
+
+// sqlVisitorAdapter 把 SqlVisitor[R] 适配为 SqlNodeVisitor，供 SqlNode.Accept 使用
+type sqlVisitorAdapter[R any] struct {
+	visitor SqlVisitor[R]
+}
+
+func (a sqlVisitorAdapter[R]) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
+	return a.visitor.VisitIdentifier(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitLiteral(node *SqlLiteral) (interface{}, error) {
+	return a.visitor.VisitLiteral(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitCall(node *SqlCall) (interface{}, error) {
+	return a.visitor.VisitCall(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitSelect(node *SqlSelect) (interface{}, error) {
+	return a.visitor.VisitSelect(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitJoin(node *SqlJoin) (interface{}, error) {
+	return a.visitor.VisitJoin(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitBasicCall(node *SqlBasicCall) (interface{}, error) {
+	return a.visitor.VisitBasicCall(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitNodeList(node *SqlNodeList) (interface{}, error) {
+	return a.visitor.VisitNodeList(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitHint(node *SqlHint) (interface{}, error) {
+	return a.visitor.VisitHint(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitLambda(node *SqlLambda) (interface{}, error) {
+	return a.visitor.VisitLambda(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitTableRef(node *SqlTableRef) (interface{}, error) {
+	return a.visitor.VisitTableRef(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitExplain(node *SqlExplain) (interface{}, error) {
+	return a.visitor.VisitExplain(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitDescribe(node *SqlDescribe) (interface{}, error) {
+	return a.visitor.VisitDescribe(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitShow(node *SqlShow) (interface{}, error) {
+	return a.visitor.VisitShow(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitUse(node *SqlUse) (interface{}, error) {
+	return a.visitor.VisitUse(node)
+}
+
+func (a sqlVisitorAdapter[R]) VisitError(node *SqlErrorNode) (interface{}, error) {
+	return a.visitor.VisitError(node)
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// =============================================================================
+// 批量解析
+// =============================================================================
+
+// BatchResult ParseBatch 中单条语句的解析结果
+type BatchResult struct {
+	Result *SQLParserResult
+	Err    error
+}
+
+// ParseBatch 使用默认选项并发解析多条SQL语句，返回结果的顺序与输入一致
+// 与 DefaultParseOptions 不同，批量解析默认不保留 ANTLR 解析树，避免大批量结果占用过多内存
+func ParseBatch(ctx context.Context, sqls []string, workers int) ([]BatchResult, error) {
+	opts := DefaultParseOptions()
+	opts.KeepAntlrTree = false
+	return ParseBatchWithOptions(ctx, sqls, workers, opts)
+}
+
+// ParseBatchWithOptions 按指定选项并发解析多条SQL语句，返回结果的顺序与输入一致
+// workers <= 0 时使用 runtime.GOMAXPROCS(0) 个协程。
+// ctx 取消后正在进行的解析尽快中止，尚未完成的语句的 Err 为 ctx.Err()，同时返回 ctx.Err()
+func ParseBatchWithOptions(ctx context.Context, sqls []string, workers int, opts ParseOptions) ([]BatchResult, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(sqls) {
+		workers = len(sqls)
+	}
+
+	results := make([]BatchResult, len(sqls))
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(sqls) {
+					return
+				}
+				if err := ctx.Err(); err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Result, results[i].Err = ParseWithContext(ctx, sqls[i], opts)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestParseBatch 测试批量解析结果顺序与输入一致
+func TestParseBatch(t *testing.T) {
+	sqls := make([]string, 50)
+	for i := range sqls {
+		sqls[i] = fmt.Sprintf("select t%d.a from t%d", i, i)
+	}
+	sqls[10] = "select from where"
+
+	results, err := ParseBatch(context.Background(), sqls, 4)
+	if err != nil {
+		t.Fatalf("批量解析失败: %v", err)
+	}
+	if len(results) != len(sqls) {
+		t.Fatalf("结果数量 = %d，期望 %d", len(results), len(sqls))
+	}
+
+	for i, r := range results {
+		if i == 10 {
+			var parseErr *ParseError
+			if !errors.As(r.Err, &parseErr) {
+				t.Errorf("第 %d 条期望 *ParseError，实际为 %v", i, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("第 %d 条解析失败: %v", i, r.Err)
+			continue
+		}
+		if r.Result.AntlrTree != nil {
+			t.Errorf("第 %d 条不应保留 ANTLR 解析树", i)
+		}
+		tables, _ := ExtractTableNames(r.Result.SqlNode)
+		if want := fmt.Sprintf("t%d", i); len(tables) != 1 || tables[0] != want {
+			t.Errorf("第 %d 条表名 = %v，期望 [%s]", i, tables, want)
+		}
+	}
+}
+
+// TestParseBatchCancelled 测试取消后不再解析
+func TestParseBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := ParseBatch(ctx, []string{"select t.a from t", "select t.b from t"}, 2)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望 context.Canceled，实际为 %v", err)
+	}
+	for i, r := range results {
+		if !errors.Is(r.Err, context.Canceled) || r.Result != nil {
+			t.Errorf("第 %d 条期望被取消，实际为 %+v", i, r)
+		}
+	}
+}
+
+// TestParseBatchWithOptions 测试批量解析使用指定的选项
+func TestParseBatchWithOptions(t *testing.T) {
+	sqls := []string{
+		"select t.a from t",
+		"select events.a from events lateral view explode(events.arr) t as x",
+	}
+	opts := DefaultParseOptions()
+	opts.Strict = true
+
+	results, err := ParseBatchWithOptions(context.Background(), sqls, 2, opts)
+	if err != nil {
+		t.Fatalf("批量解析失败: %v", err)
+	}
+	if results[0].Err != nil || results[0].Result.AntlrTree == nil {
+		t.Errorf("第 0 条应解析成功并保留 ANTLR 解析树，实际为 %+v", results[0])
+	}
+	var unsupportedErr *UnsupportedFeatureError
+	if !errors.As(results[1].Err, &unsupportedErr) {
+		t.Errorf("严格模式下第 1 条期望 *UnsupportedFeatureError，实际为 %v", results[1].Err)
+	}
+}
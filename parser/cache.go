@@ -0,0 +1,457 @@
+package parser
+
+import (
+	"container/list"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+	"go-job-service/parser/antlr"
+)
+
+// =============================================================================
+// ParseCache - LRU 解析缓存
+// =============================================================================
+
+// parseCacheNodeBytes 估算缓存占用时每个 SqlNode 节点计入的字节数
+const parseCacheNodeBytes = 128
+
+// ParseCache 并发安全的 LRU 解析缓存
+// 以去除注释、合并空白后的 SQL 文本和解析选项作为键，只缓存解析成功的结果。
+// 返回的结果都是缓存条目的深拷贝，调用方可以自由修改；
+// 缓存结果不包含 AntlrTree，SQL、位置信息和注释都对应本次调用传入的 SQL 文本
+type ParseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	order      *list.List // 最近使用的条目在前
+	entries    map[parseCacheKey]*list.Element
+	stats      ParseCacheStats
+}
+
+// parseCacheKey 缓存键
+type parseCacheKey struct {
+	sql  string
+	opts ParseOptions
+}
+
+// parseCacheEntry 缓存条目
+type parseCacheEntry struct {
+	key    parseCacheKey
+	result *SQLParserResult
+	size   int
+}
+
+// ParseCacheStats 缓存统计信息
+type ParseCacheStats struct {
+	Hits      uint64 // 命中次数
+	Misses    uint64 // 未命中次数
+	Evictions uint64 // 因超过容量被淘汰的条目数
+	Entries   int    // 当前条目数
+	Bytes     int    // 当前估算占用字节数
+}
+
+// HitRate 命中率，没有请求时为 0
+func (s ParseCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// NewParseCache 创建解析缓存
+// maxEntries 为最大条目数，maxBytes 为最大估算占用字节数，<= 0 表示不限制该项
+func NewParseCache(maxEntries, maxBytes int) *ParseCache {
+	return &ParseCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[parseCacheKey]*list.Element),
+	}
+}
+
+// Parse 使用默认选项解析，等价于带缓存的 ParseSQLWithAntlr
+func (c *ParseCache) Parse(sql string) (*SQLParserResult, error) {
+	return c.ParseWithOptions(sql, DefaultParseOptions())
+}
+
+// ParseWithOptions 带缓存的 ParseWithOptions
+func (c *ParseCache) ParseWithOptions(sql string, opts ParseOptions) (*SQLParserResult, error) {
+	// 键去掉了注释和多余空白，长度限制必须按本次传入的原始 SQL 检查
+	if err := checkInputLength(strings.TrimSpace(sql), opts); err != nil {
+		return nil, err
+	}
+	opts.KeepAntlrTree = false
+	key := parseCacheKey{sql: normalizeSQL(sql), opts: opts}
+
+	if cached, ok := c.get(key); ok {
+		if result, ok := rebindResult(cached, strings.TrimSpace(sql)); ok {
+			return result, nil
+		}
+		// 位置无法对应时退回完整解析，不更新缓存
+		return ParseWithOptions(sql, opts)
+	}
+
+	result, err := ParseWithOptions(sql, opts)
+	if err != nil {
+		return result, err
+	}
+	c.put(key, result)
+	return cloneParserResult(result), nil
+}
+
+// Stats 返回缓存统计信息
+func (c *ParseCache) Stats() ParseCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// Purge 清空缓存，统计信息保留
+func (c *ParseCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = make(map[parseCacheKey]*list.Element)
+	c.bytes = 0
+}
+
+// get 查找缓存，返回的条目不能修改，需经 rebindResult 克隆后返回给调用方
+func (c *ParseCache) get(key parseCacheKey) (*SQLParserResult, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		c.mu.Unlock()
+		return nil, false
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(elem)
+	result := elem.Value.(*parseCacheEntry).result
+	c.mu.Unlock()
+
+	// 缓存中的结果不会被修改，可以在锁外克隆
+	return result, true
+}
+
+// put 加入缓存并按容量淘汰最久未使用的条目
+func (c *ParseCache) put(key parseCacheKey, result *SQLParserResult) {
+	entry := &parseCacheEntry{key: key, result: result, size: estimateResultSize(key.sql, result)}
+	if c.maxBytes > 0 && entry.size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		// 并发未命中时其他调用已经写入
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += entry.size
+
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		oldest := c.order.Back()
+		evicted := c.order.Remove(oldest).(*parseCacheEntry)
+		delete(c.entries, evicted.key)
+		c.bytes -= evicted.size
+		c.stats.Evictions++
+	}
+}
+
+// estimateResultSize 估算缓存条目占用的字节数
+func estimateResultSize(sql string, result *SQLParserResult) int {
+	nodes := 0
+	Inspect(result.SqlNode, func(child SqlNode) bool {
+		if child != nil {
+			nodes++
+		}
+		return child != nil
+	})
+	return len(sql) + nodes*parseCacheNodeBytes
+}
+
+// cloneParserResult 深拷贝解析结果（不含 AntlrTree）
+func cloneParserResult(result *SQLParserResult) *SQLParserResult {
+	clone := &SQLParserResult{
+		Success:      result.Success,
+		ErrorMessage: result.ErrorMessage,
+		SQL:          result.SQL,
+		Errors:       append([]string{}, result.Errors...),
+		Diagnostics:  append([]*Diagnostic(nil), result.Diagnostics...),
+		Warnings:     append([]error(nil), result.Warnings...),
+	}
+	if result.SqlNode != nil {
+		clone.SqlNode = result.SqlNode.Clone()
+	}
+	return clone
+}
+
+// rebindResult 克隆缓存结果，并把 SQL、节点位置、注释和警告改为对应 sql
+// sql 与缓存条目规范化后相同，两者的 token 一一对应，只有空白和注释不同：
+// 位置经规范化文本换算到 sql 中，注释重新从 sql 中收集并附着。
+// 位置无法换算时返回 false
+func rebindResult(result *SQLParserResult, sql string) (*SQLParserResult, bool) {
+	clone := cloneParserResult(result)
+	if result.SQL == sql {
+		return clone, true
+	}
+	if len(result.Diagnostics) > 0 {
+		return nil, false
+	}
+
+	rebind := newPosRebinder(result.SQL, sql)
+	ok := true
+	Inspect(clone.SqlNode, func(node SqlNode) bool {
+		if node == nil || !ok {
+			return false
+		}
+		holder, isBase := node.(interface{ base() *BaseSqlNode })
+		if !isBase {
+			return true
+		}
+		base := holder.base()
+		base.Comments = nil
+		base.Pos, ok = rebind.pos(base.Pos)
+		return ok
+	})
+	if !ok {
+		return nil, false
+	}
+
+	warnings := clone.Warnings[:0]
+	for _, warning := range clone.Warnings {
+		switch w := warning.(type) {
+		case *MalformedHintWarning:
+			// 与注释一起重新收集
+			continue
+		case *UnsupportedFeatureError:
+			rebound := *w
+			if rebound.Pos, ok = rebind.pos(w.Pos); !ok {
+				return nil, false
+			}
+			if rebound.Pos != nil && rebound.Pos.LineNumber > 0 {
+				rebound.Text = sql[rebound.Pos.StartOffset:rebound.Pos.EndOffset]
+			}
+			warning = &rebound
+		}
+		warnings = append(warnings, warning)
+	}
+
+	comments, commentWarnings := lexComments(sql)
+	attachComments(clone.SqlNode, comments)
+	clone.Warnings = append(warnings, commentWarnings...)
+	clone.SQL = sql
+	return clone, true
+}
+
+// posRebinder 把一段 SQL 中的位置换算到规范化后相同的另一段 SQL 中
+type posRebinder struct {
+	toNormalized []int  // 旧 SQL 每个字节在规范化文本中的下标，被去除的字节为 -1
+	toNew        []int  // 规范化文本每个字节在新 SQL 中的偏移
+	sql          string // 新 SQL
+	lineStarts   []int  // 新 SQL 每行起始的字节偏移
+}
+
+// newPosRebinder 创建从 oldSQL 到 newSQL 的位置换算
+func newPosRebinder(oldSQL, newSQL string) *posRebinder {
+	_, oldOffsets := normalizeSQLWithOffsets(oldSQL, true)
+	_, newOffsets := normalizeSQLWithOffsets(newSQL, true)
+
+	toNormalized := make([]int, len(oldSQL))
+	for i := range toNormalized {
+		toNormalized[i] = -1
+	}
+	for i, offset := range oldOffsets {
+		if offset >= 0 && offset < len(oldSQL) {
+			toNormalized[offset] = i
+		}
+	}
+	lineStarts := []int{0}
+	for i := 0; i < len(newSQL); i++ {
+		if newSQL[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return &posRebinder{toNormalized: toNormalized, toNew: newOffsets, sql: newSQL, lineStarts: lineStarts}
+}
+
+// pos 换算位置，没有位置信息时原样返回
+func (r *posRebinder) pos(pos *SqlParserPos) (*SqlParserPos, bool) {
+	if pos == nil || pos.LineNumber == 0 {
+		return pos, true
+	}
+	start, ok := r.offset(pos.StartOffset)
+	if !ok {
+		return nil, false
+	}
+	end := start
+	if pos.EndOffset > pos.StartOffset {
+		last, ok := r.offset(pos.EndOffset - 1)
+		if !ok {
+			return nil, false
+		}
+		end = last + 1
+	}
+
+	startPoint, endPoint := r.point(start), r.point(end)
+	return &SqlParserPos{
+		LineNumber:   startPoint.line,
+		ColumnNumber: startPoint.column,
+		EndLine:      endPoint.line,
+		EndColumn:    endPoint.column,
+		StartOffset:  start,
+		EndOffset:    end,
+		Synthetic:    pos.Synthetic,
+	}, true
+}
+
+// offset 把旧 SQL 中的字节偏移换算为新 SQL 中的字节偏移
+func (r *posRebinder) offset(old int) (int, bool) {
+	if old < 0 || old >= len(r.toNormalized) {
+		return 0, false
+	}
+	i := r.toNormalized[old]
+	if i < 0 || i >= len(r.toNew) || r.toNew[i] < 0 {
+		return 0, false
+	}
+	return r.toNew[i], true
+}
+
+// point 计算新 SQL 中字节偏移对应的行号和列号（字符）
+func (r *posRebinder) point(offset int) sourcePoint {
+	line := sort.SearchInts(r.lineStarts, offset+1) - 1
+	return sourcePoint{line + 1, utf8.RuneCountInString(r.sql[r.lineStarts[line]:offset])}
+}
+
+// lexComments 只做词法分析，收集 sql 中的注释
+func lexComments(sql string) ([]pendingComment, []error) {
+	lexer := antlr.NewSqlBaseLexer(antlr4.NewInputStream(sql))
+	lexer.RemoveErrorListeners()
+	stream := antlr4.NewCommonTokenStream(lexer, antlr4.TokenDefaultChannel)
+	stream.Fill()
+	return collectComments(stream)
+}
+
+// =============================================================================
+// SQL 文本规范化
+// =============================================================================
+
+// normalizeSQL 去除注释并把连续空白合并为一个空格，字符串、反引号标识符和 hint 保持不变
+func normalizeSQL(sql string) string {
+	normalized, _ := normalizeSQLWithOffsets(sql, false)
+	return normalized
+}
+
+// normalizeSQLWithOffsets 规范化 SQL，withOffsets 为 true 时同时返回规范化文本中
+// 每个字节在原文中的字节偏移，合并空白和注释后插入的空格为 -1
+func normalizeSQLWithOffsets(sql string, withOffsets bool) (string, []int) {
+	var sb strings.Builder
+	sb.Grow(len(sql))
+
+	runes := []rune(sql)
+	var runeStarts, offsets []int
+	if withOffsets {
+		runeStarts = make([]int, 0, len(runes))
+		for offset := range sql {
+			runeStarts = append(runeStarts, offset)
+		}
+		offsets = make([]int, 0, len(sql))
+	}
+
+	// emit 原样输出第 i 个字符
+	emit := func(i int) {
+		n, _ := sb.WriteRune(runes[i])
+		for k := 0; withOffsets && k < n; k++ {
+			offsets = append(offsets, runeStarts[i]+k)
+		}
+	}
+	pendingSpace := false
+	writeRune := func(i int) {
+		if pendingSpace && sb.Len() > 0 {
+			sb.WriteRune(' ')
+			if withOffsets {
+				offsets = append(offsets, -1)
+			}
+		}
+		pendingSpace = false
+		emit(i)
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			pendingSpace = true
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// 单行注释
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			pendingSpace = true
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*' && !(i+2 < len(runes) && runes[i+2] == '+'):
+			// 块注释，支持嵌套
+			depth := 0
+			for ; i < len(runes); i++ {
+				if runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '*' {
+					depth++
+					i++
+				} else if runes[i] == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+					depth--
+					i++
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			pendingSpace = true
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			// hint 原样保留
+			end := i + 2
+			for end < len(runes) && !(runes[end] == '*' && end+1 < len(runes) && runes[end+1] == '/') {
+				end++
+			}
+			end = min(end+2, len(runes))
+			for ; i < end; i++ {
+				writeRune(i)
+			}
+			i--
+
+		case r == '\'' || r == '"' || r == '`':
+			// 字符串和反引号标识符原样保留
+			writeRune(i)
+			for i++; i < len(runes); i++ {
+				emit(i)
+				if runes[i] == '\\' && r != '`' && i+1 < len(runes) {
+					i++
+					emit(i)
+					continue
+				}
+				if runes[i] == r {
+					break
+				}
+			}
+
+		default:
+			writeRune(i)
+		}
+	}
+
+	return sb.String(), offsets
+}
@@ -0,0 +1,219 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestNormalizeSQL 测试缓存键的 SQL 规范化
+func TestNormalizeSQL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{name: "合并空白", sql: "select  a\n\tfrom   t ", expected: "select a from t"},
+		{name: "去除单行注释", sql: "select a -- 注释\nfrom t", expected: "select a from t"},
+		{name: "去除块注释", sql: "select /* 注释 */ a from t", expected: "select a from t"},
+		{name: "嵌套块注释", sql: "select /* a /* b */ c */ a from t", expected: "select a from t"},
+		{name: "保留 hint", sql: "select /*+  JOIN(TEE) */ a from t", expected: "select /*+  JOIN(TEE) */ a from t"},
+		{name: "保留字符串", sql: "select 'a  -- b' from t", expected: "select 'a  -- b' from t"},
+		{name: "保留转义引号", sql: "select 'it\\'s  x' from t", expected: "select 'it\\'s  x' from t"},
+		{name: "保留反引号标识符", sql: "select `a  b` from t", expected: "select `a  b` from t"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := normalizeSQL(tc.sql); got != tc.expected {
+				t.Errorf("normalizeSQL(%q) = %q，期望 %q", tc.sql, got, tc.expected)
+			}
+		})
+	}
+}
+
+// TestParseCacheHitAndMiss 测试缓存命中统计
+func TestParseCacheHitAndMiss(t *testing.T) {
+	cache := NewParseCache(10, 0)
+
+	for _, sql := range []string{
+		"select t.a from t",
+		"select t.a   from t -- 注释",
+		"SELECT t.a from t",
+	} {
+		if _, err := cache.Parse(sql); err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("统计信息 = %+v，期望 1 次命中、2 次未命中、2 个条目", stats)
+	}
+
+	// 选项不同时不命中
+	if _, err := cache.ParseWithOptions("select t.a from t", ParseOptions{IdentifierCase: IdentifierCaseUpper}); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if stats := cache.Stats(); stats.Misses != 3 {
+		t.Errorf("不同选项应不命中，统计信息 = %+v", stats)
+	}
+}
+
+// TestParseCacheChecksInputLength 测试命中缓存时仍按原始 SQL 检查输入长度
+func TestParseCacheChecksInputLength(t *testing.T) {
+	cache := NewParseCache(10, 0)
+	opts := ParseOptions{MaxInputLength: 20}
+	if _, err := cache.ParseWithOptions("select t.a from t", opts); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	// 规范化后与缓存键相同，但原始 SQL 超过长度限制
+	_, err := cache.ParseWithOptions("select t.a from t -- 很长的注释", opts)
+	var limitErr *LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitBytes {
+		t.Errorf("期望 LimitBytes 错误，实际得到: %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits != 0 {
+		t.Errorf("超过限制的 SQL 不应命中缓存，统计信息 = %+v", stats)
+	}
+}
+
+// TestParseCacheReturnsClones 测试修改返回结果不影响缓存
+func TestParseCacheReturnsClones(t *testing.T) {
+	cache := NewParseCache(10, 0)
+	sql := "select t.a from t where t.a = 1"
+
+	first, err := cache.Parse(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	sel := first.SqlNode.(*SqlSelect)
+	sel.Where = nil
+	sel.SelectList[0].(*SqlIdentifier).Names[1] = "changed"
+
+	second, err := cache.Parse(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if got := second.SqlNode.ToString(); got != "SELECT t.a FROM t WHERE t.a = 1" {
+		t.Errorf("缓存条目被修改: %s", got)
+	}
+}
+
+// TestParseCacheEviction 测试按条目数淘汰
+func TestParseCacheEviction(t *testing.T) {
+	cache := NewParseCache(2, 0)
+	for _, sql := range []string{"select a.x from a", "select b.x from b", "select a.x from a", "select c.x from c"} {
+		if _, err := cache.Parse(sql); err != nil {
+			t.Fatalf("解析失败: %v", err)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.Evictions != 1 {
+		t.Errorf("统计信息 = %+v，期望 2 个条目、1 次淘汰", stats)
+	}
+
+	// b 最久未使用，已被淘汰
+	if _, err := cache.Parse("select b.x from b"); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if stats := cache.Stats(); stats.Misses != 4 {
+		t.Errorf("被淘汰的条目应不命中，统计信息 = %+v", stats)
+	}
+}
+
+// TestNormalizeSQLOffsets 测试规范化文本到原文的字节偏移
+func TestNormalizeSQLOffsets(t *testing.T) {
+	sql := "select  /* 注释 */ '名字'\n\tfrom t"
+	normalized, offsets := normalizeSQLWithOffsets(sql, true)
+	if normalized != "select '名字' from t" || len(offsets) != len(normalized) {
+		t.Fatalf("规范化结果 = %q，偏移数 = %d", normalized, len(offsets))
+	}
+	for i := range normalized {
+		if offsets[i] < 0 {
+			if normalized[i] != ' ' {
+				t.Errorf("第 %d 个字节 %q 没有对应的原文偏移", i, normalized[i])
+			}
+			continue
+		}
+		if sql[offsets[i]] != normalized[i] {
+			t.Errorf("第 %d 个字节 %q 对应原文偏移 %d 处的 %q", i, normalized[i], offsets[i], sql[offsets[i]])
+		}
+	}
+}
+
+// TestPosRebinder 测试把位置换算到空白和注释不同的 SQL 中
+func TestPosRebinder(t *testing.T) {
+	oldSQL := "select '名字' from t"
+	newSQL := "select\n  /* 注释 */ '名字'\n  from   t"
+	rebind := newPosRebinder(oldSQL, newSQL)
+
+	start := strings.Index(oldSQL, "'")
+	pos, ok := rebind.pos(&SqlParserPos{LineNumber: 1, ColumnNumber: 7, EndLine: 1, EndColumn: 11,
+		StartOffset: start, EndOffset: start + len("'名字'"), Synthetic: true})
+	if !ok {
+		t.Fatal("位置换算失败")
+	}
+	want := &SqlParserPos{LineNumber: 2, ColumnNumber: 11, EndLine: 2, EndColumn: 15,
+		StartOffset: strings.Index(newSQL, "'"), EndOffset: strings.Index(newSQL, "'") + len("'名字'"), Synthetic: true}
+	if *pos != *want {
+		t.Errorf("换算后的位置 = %+v，期望 %+v", *pos, *want)
+	}
+	if got := newSQL[pos.StartOffset:pos.EndOffset]; got != "'名字'" {
+		t.Errorf("换算后的原文 = %q", got)
+	}
+
+	// 空白在新 SQL 中没有对应的字节
+	if _, ok := rebind.pos(&SqlParserPos{LineNumber: 1, StartOffset: 6, EndOffset: 7}); ok {
+		t.Error("空白处的位置应换算失败")
+	}
+}
+
+// TestParseCacheRebindsToCallerSQL 测试命中缓存时 SQL、原文和注释对应本次传入的 SQL
+func TestParseCacheRebindsToCallerSQL(t *testing.T) {
+	cache := NewParseCache(10, 0)
+	first, err := cache.Parse("select t.a from t -- 第一次")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	sql := "select t.a\n  from   t /* 第二次 */"
+	second, err := cache.Parse("  " + sql + "\n")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if stats := cache.Stats(); stats.Hits != 1 {
+		t.Fatalf("期望命中缓存，统计信息 = %+v", stats)
+	}
+	if second.SQL != sql {
+		t.Errorf("SQL = %q，期望 %q", second.SQL, sql)
+	}
+
+	from := second.SqlNode.(*SqlSelect).From
+	if got := second.SourceText(from); got != "t" {
+		t.Errorf("FROM 的原文 = %q，期望 \"t\"", got)
+	}
+	if pos := from.GetPos(); pos.LineNumber != 2 || pos.ColumnNumber != 9 {
+		t.Errorf("FROM 的位置 = %d:%d，期望 2:9", pos.LineNumber, pos.ColumnNumber)
+	}
+
+	var comments []string
+	Inspect(second.SqlNode, func(node SqlNode) bool {
+		if c := NodeComments(node); c != nil {
+			for _, comment := range append(c.Leading, c.Trailing...) {
+				comments = append(comments, comment.Text)
+			}
+		}
+		return node != nil
+	})
+	if len(comments) != 1 || comments[0] != "/* 第二次 */" {
+		t.Errorf("注释 = %q，期望只有本次 SQL 中的注释", comments)
+	}
+
+	// 缓存条目和第一次的结果不受影响
+	if first.SQL != "select t.a from t -- 第一次" || first.SourceText(first.SqlNode.(*SqlSelect).From) != "t" {
+		t.Errorf("第一次的结果被修改: %q", first.SQL)
+	}
+}
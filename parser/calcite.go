@@ -0,0 +1,671 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Calcite 兼容导出 - 与 Java 版本 SqlNodeBuilderV2 的输出对比
+// =============================================================================
+
+// CalciteOptions Calcite 格式导出选项
+type CalciteOptions struct {
+	IncludePositions bool // 输出 Calcite 的 SqlParserPos（列号从 1 开始，结束列包含在内）
+}
+
+// ExportCalcite 把语法树导出为 Calcite SqlNode 形式的 JSON：
+//   - class 为 Calcite 的节点类名，kind 为 Calcite 的 SqlKind 名称，调用节点带 Calcite 的操作符名称
+//   - 别名表示为 AS 调用，DESC / NULLS FIRST 等与 Calcite 相同，为包裹表达式的后缀调用
+//   - SqlJoin 按 Calcite 的 left / natural / joinType / right / conditionType / condition 输出
+//   - 标识符中的 * 与 Calcite 相同，表示为空字符串
+//
+// Calcite 中没有对应节点的语句（SHOW、USE 等）保留本包的类名和字段，kind 为 OTHER。
+// 恢复模式产生的 SqlErrorNode 无法导出
+func ExportCalcite(node SqlNode, opts CalciteOptions) ([]byte, error) {
+	value, err := (&calciteExporter{opts: opts}).export(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// calciteObject 导出的节点，encoding/json 按键排序输出
+type calciteObject = map[string]interface{}
+
+// calciteExporter 把节点转换为 Calcite 形式的通用 JSON 值
+type calciteExporter struct {
+	opts CalciteOptions
+}
+
+// export 转换可能为 nil 的节点，nil 导出为 null
+func (e *calciteExporter) export(node SqlNode) (interface{}, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	var obj calciteObject
+	var err error
+	switch n := node.(type) {
+	case *SqlIdentifier:
+		obj = e.identifier(n)
+	case *SqlLiteral:
+		obj = calciteLiteral(n)
+	case *SqlCall:
+		obj, err = e.call(n)
+	case *SqlHint:
+		obj, err = e.hint(n)
+	case *SqlSelect:
+		obj, err = e.selectNode(n)
+	case *SqlJoin:
+		obj, err = e.join(n)
+	case *SqlBasicCall:
+		obj, err = e.alias(n)
+	case *SqlNodeList:
+		obj, err = e.nodeList(n.List, n.Pos)
+	case *SqlLambda:
+		obj, err = e.lambda(n)
+	case *SqlTableRef:
+		return e.tableRef(n)
+	case *SqlExplain:
+		obj, err = e.explain(n)
+	case *SqlDescribe:
+		obj, err = e.describe(n)
+	case *SqlShow:
+		obj, err = e.fields(calciteObject{"class": "SqlShow", "kind": "OTHER", "target": n.Target, "pattern": n.Pattern},
+			map[string]SqlNode{"object": identifierOrNil(n.Object), "namespace": identifierOrNil(n.Namespace)})
+	case *SqlUse:
+		obj, err = e.fields(calciteObject{"class": "SqlUse", "kind": "OTHER", "statement": string(n.Kind), "namespaceType": n.NamespaceType},
+			map[string]SqlNode{"namespace": identifierOrNil(n.Namespace)})
+	case *SqlErrorNode:
+		return nil, fmt.Errorf("语法错误节点无法导出为 Calcite 格式: %q", n.Text)
+	default:
+		return nil, fmt.Errorf("不支持导出为 Calcite 格式的节点类型: %T", node)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.setPos(obj, node.GetPos())
+	return obj, nil
+}
+
+// setPos 按 Calcite 的约定输出位置：列号从 1 开始，结束位置包含在内
+func (e *calciteExporter) setPos(obj calciteObject, pos *SqlParserPos) {
+	if !e.opts.IncludePositions || pos == nil || pos.LineNumber == 0 {
+		return
+	}
+	obj["pos"] = calciteObject{
+		"lineNumber":      pos.LineNumber,
+		"columnNumber":    pos.ColumnNumber + 1,
+		"endLineNumber":   pos.EndLine,
+		"endColumnNumber": pos.EndColumn,
+	}
+}
+
+// exportList 转换节点列表
+func (e *calciteExporter) exportList(nodes []SqlNode) ([]interface{}, error) {
+	list := make([]interface{}, 0, len(nodes))
+	for _, node := range nodes {
+		value, err := e.export(node)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+// fields 依次转换子节点并设置到 obj 上
+func (e *calciteExporter) fields(obj calciteObject, children map[string]SqlNode) (calciteObject, error) {
+	for name, child := range children {
+		value, err := e.export(child)
+		if err != nil {
+			return nil, err
+		}
+		obj[name] = value
+	}
+	return obj, nil
+}
+
+// nodeList 转换为 Calcite 的 SqlNodeList
+func (e *calciteExporter) nodeList(nodes []SqlNode, pos *SqlParserPos) (calciteObject, error) {
+	list, err := e.exportList(nodes)
+	if err != nil {
+		return nil, err
+	}
+	obj := calciteObject{"class": "SqlNodeList", "kind": "OTHER", "list": list}
+	e.setPos(obj, pos)
+	return obj, nil
+}
+
+// optionalList 空列表导出为 null，与 Calcite 中没有 GROUP BY / ORDER BY 时一致
+func (e *calciteExporter) optionalList(nodes []SqlNode) (interface{}, error) {
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+	return e.nodeList(nodes, nil)
+}
+
+// basicCall 构造 Calcite 的 SqlBasicCall
+func (e *calciteExporter) basicCall(kind, operator string, operands []SqlNode) (calciteObject, error) {
+	list, err := e.exportList(operands)
+	if err != nil {
+		return nil, err
+	}
+	return calciteObject{"class": "SqlBasicCall", "kind": kind, "operator": operator, "operands": list}, nil
+}
+
+func (e *calciteExporter) identifier(n *SqlIdentifier) calciteObject {
+	names := make([]string, len(n.Names))
+	for i, name := range n.Names {
+		if name != "*" {
+			names[i] = name
+		}
+	}
+	return calciteObject{"class": "SqlIdentifier", "kind": "IDENTIFIER", "names": names}
+}
+
+// calciteLiteral 按 Calcite 的字面量子类和 SqlTypeName 导出，值为 SqlLiteral.toValue 的字符串形式
+func calciteLiteral(n *SqlLiteral) calciteObject {
+	class, typeName := "SqlLiteral", ""
+	switch n.ValueType {
+	case LiteralNull:
+		typeName = "NULL"
+	case LiteralBoolean:
+		typeName = "BOOLEAN"
+	case LiteralInteger, LiteralDecimal:
+		class, typeName = "SqlNumericLiteral", "DECIMAL"
+	case LiteralString:
+		class, typeName = "SqlCharStringLiteral", "CHAR"
+	case LiteralDate:
+		class, typeName = "SqlDateLiteral", "DATE"
+	case LiteralTime:
+		class, typeName = "SqlTimeLiteral", "TIME"
+	case LiteralTimestamp:
+		class, typeName = "SqlTimestampLiteral", "TIMESTAMP"
+	case LiteralInterval:
+		class, typeName = "SqlIntervalLiteral", "INTERVAL_"+strings.ToUpper(n.TypeName)
+	case LiteralSymbol:
+		typeName = "SYMBOL"
+	}
+
+	var value interface{}
+	switch v := n.Value.(type) {
+	case nil:
+	case bool:
+		value = strings.ToUpper(strconv.FormatBool(v))
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		value = fmt.Sprintf("%v", v)
+	}
+	return calciteObject{"class": class, "kind": "LITERAL", "typeName": typeName, "value": value}
+}
+
+// calciteSymbol 构造 Calcite 的符号字面量，如 JoinType.INNER
+func calciteSymbol(value string) calciteObject {
+	return calciteObject{"class": "SqlLiteral", "kind": "LITERAL", "typeName": "SYMBOL", "value": value}
+}
+
+// calciteOperatorNames 与 Calcite 写法不同的操作符名称
+var calciteOperatorNames = map[string]string{
+	"==": "=",
+	"!=": "<>",
+	".":  "DOT",
+}
+
+// calciteOperatorKinds 本包没有单独 SqlKind（为 OTHER）的操作符在 Calcite 中的 SqlKind
+var calciteOperatorKinds = map[string]string{
+	"%":           "MOD",
+	"IS NULL":     "IS_NULL",
+	"IS NOT NULL": "IS_NOT_NULL",
+}
+
+// calciteFunctionKinds 在 Calcite 中有专门 SqlKind 的函数，其余函数为 OTHER_FUNCTION
+var calciteFunctionKinds = map[string]string{
+	"COUNT":    "COUNT",
+	"SUM":      "SUM",
+	"AVG":      "AVG",
+	"MIN":      "MIN",
+	"MAX":      "MAX",
+	"COALESCE": "COALESCE",
+	"TRY_CAST": "SAFE_CAST",
+}
+
+// calciteOperator 返回调用在 Calcite 中的 SqlKind 和操作符名称
+func calciteOperator(op *SqlOperator) (kind, name string) {
+	name = strings.ToUpper(op.Name)
+	if mapped, ok := calciteOperatorNames[name]; ok {
+		name = mapped
+	}
+
+	switch {
+	case calciteFunctionKinds[name] != "":
+		kind = calciteFunctionKinds[name]
+	case op.Kind == SqlKindCall || op.Kind == SqlKindStruct || (op.Kind == SqlKindOther && op.Syntax == SyntaxFunction):
+		kind = "OTHER_FUNCTION"
+	case op.Kind == SqlKindOther:
+		kind = calciteOperatorKinds[name]
+		if kind == "" {
+			kind = "OTHER"
+		}
+	case op.Syntax == SyntaxPrefix && (op.Kind == SqlKindMinus || op.Kind == SqlKindPlus):
+		kind = string(op.Kind) + "_PREFIX"
+	default:
+		kind = string(op.Kind)
+	}
+	return kind, name
+}
+
+func (e *calciteExporter) call(n *SqlCall) (calciteObject, error) {
+	if n.Operator == nil {
+		return nil, fmt.Errorf("调用缺少操作符")
+	}
+	kind, name := calciteOperator(n.Operator)
+	obj, err := e.basicCall(kind, name, n.Operands)
+	if err != nil {
+		return nil, err
+	}
+	// CAST 的目标类型在 Calcite 中为 SqlDataTypeSpec
+	if n.Operator.Kind == SqlKindCast && len(n.Operands) == 2 {
+		if target, ok := n.Operands[1].(*SqlLiteral); ok && target.ValueType == LiteralSymbol {
+			obj["operands"].([]interface{})[1] = calciteObject{"class": "SqlDataTypeSpec", "kind": "OTHER", "typeName": fmt.Sprintf("%v", target.Value)}
+		}
+	}
+	return obj, nil
+}
+
+func (e *calciteExporter) hint(n *SqlHint) (calciteObject, error) {
+	options, err := e.nodeList(n.Parameters, nil)
+	if err != nil {
+		return nil, err
+	}
+	return calciteObject{"class": "SqlHint", "kind": "HINT", "name": n.Name, "options": options, "optionFormat": calciteHintFormat(n.Parameters)}, nil
+}
+
+// calciteHintFormat 按参数推断 Calcite 的 SqlHint.HintOptionFormat
+func calciteHintFormat(parameters []SqlNode) string {
+	if len(parameters) == 0 {
+		return "EMPTY"
+	}
+	identifiers, literals := true, true
+	for _, param := range parameters {
+		_, isIdentifier := param.(*SqlIdentifier)
+		_, isLiteral := param.(*SqlLiteral)
+		identifiers = identifiers && isIdentifier
+		literals = literals && isLiteral
+	}
+	switch {
+	case identifiers:
+		return "ID_LIST"
+	case literals:
+		return "LITERAL_LIST"
+	}
+	return "KV_LIST"
+}
+
+// selectNode 按 Calcite SqlSelect 的操作数导出，没有 GROUP BY / ORDER BY 时为 null
+func (e *calciteExporter) selectNode(n *SqlSelect) (calciteObject, error) {
+	keywords := make([]SqlNode, len(n.KeywordList))
+	for i, keyword := range n.KeywordList {
+		keywords[i] = NewSqlLiteral(strings.ToUpper(keyword), LiteralSymbol, nil)
+	}
+	hints := make([]SqlNode, len(n.Hints))
+	for i, hint := range n.Hints {
+		hints[i] = hint
+	}
+
+	obj := calciteObject{"class": "SqlSelect", "kind": "SELECT"}
+	lists := []struct {
+		name     string
+		nodes    []SqlNode
+		optional bool
+	}{
+		{"keywordList", keywords, false},
+		{"selectList", n.SelectList, false},
+		{"groupBy", n.GroupBy, true},
+		{"windowDecls", n.WindowDecls, false},
+		{"orderBy", n.OrderBy, true},
+		{"hints", hints, false},
+	}
+	for _, l := range lists {
+		var value interface{}
+		var err error
+		if l.optional {
+			value, err = e.optionalList(l.nodes)
+		} else {
+			value, err = e.nodeList(l.nodes, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		obj[l.name] = value
+	}
+	return e.fields(obj, map[string]SqlNode{
+		"from": n.From, "where": n.Where, "having": n.Having, "offset": n.Offset, "fetch": n.Fetch,
+	})
+}
+
+// join 按 Calcite SqlJoin 的操作数导出，USING 的列表作为条件，conditionType 为 USING
+func (e *calciteExporter) join(n *SqlJoin) (calciteObject, error) {
+	obj := calciteObject{
+		"class":    "SqlJoin",
+		"kind":     "JOIN",
+		"natural":  false,
+		"joinType": string(n.JoinType),
+	}
+	var condition interface{}
+	var err error
+	switch {
+	case len(n.Using) > 0:
+		obj["conditionType"] = "USING"
+		condition, err = e.nodeList(n.Using, nil)
+	case n.Condition != nil:
+		obj["conditionType"] = "ON"
+		condition, err = e.export(n.Condition)
+	default:
+		obj["conditionType"] = "NONE"
+	}
+	if err != nil {
+		return nil, err
+	}
+	obj["condition"] = condition
+	return e.fields(obj, map[string]SqlNode{"left": n.Left, "right": n.Right})
+}
+
+// alias 别名在 Calcite 中为 AS 调用，第二个操作数为别名标识符
+func (e *calciteExporter) alias(n *SqlBasicCall) (calciteObject, error) {
+	return e.basicCall("AS", "AS", []SqlNode{n.Operand, NewSqlIdentifier([]string{n.Alias}, nil)})
+}
+
+func (e *calciteExporter) lambda(n *SqlLambda) (calciteObject, error) {
+	params := make([]SqlNode, len(n.Parameters))
+	for i, param := range n.Parameters {
+		params[i] = param
+	}
+	parameters, err := e.nodeList(params, nil)
+	if err != nil {
+		return nil, err
+	}
+	return e.fields(calciteObject{"class": "SqlLambda", "kind": "LAMBDA", "parameters": parameters},
+		map[string]SqlNode{"expression": n.Body})
+}
+
+// tableRef 时间旅行导出为 Calcite 的 SqlSnapshot，抽样导出为 TABLESAMPLE 调用
+// Calcite 中没有对应的抽样方式（ROWS、BUCKET、BYTES）保留本包的字段
+func (e *calciteExporter) tableRef(n *SqlTableRef) (interface{}, error) {
+	table, err := e.export(identifierOrNil(n.Name))
+	if err != nil {
+		return nil, err
+	}
+	if t := n.Temporal; t != nil {
+		snapshot, err := e.fields(calciteObject{"class": "SqlSnapshot", "kind": "SNAPSHOT", "tableRef": table, "periodType": string(t.Type)},
+			map[string]SqlNode{"period": t.Value})
+		if err != nil {
+			return nil, err
+		}
+		e.setPos(snapshot, n.Pos)
+		table = snapshot
+	}
+	if s := n.Sample; s != nil {
+		spec := calciteObject{"class": "SqlSampleSpec", "method": string(s.Method), "repeatable": s.Seed != nil}
+		if s.Seed != nil {
+			spec["repeatableSeed"] = *s.Seed
+		}
+		if s.Method == SampleBucket {
+			spec["numerator"], spec["denominator"] = s.Numerator, s.Denominator
+		}
+		if spec, err = e.fields(spec, map[string]SqlNode{"value": s.Value, "bucketOn": s.BucketOn}); err != nil {
+			return nil, err
+		}
+		sample := calciteObject{"class": "SqlBasicCall", "kind": "TABLESAMPLE", "operator": "TABLESAMPLE",
+			"operands": []interface{}{table, calciteObject{"class": "SqlLiteral", "kind": "LITERAL", "typeName": "SYMBOL", "value": spec}}}
+		e.setPos(sample, n.Pos)
+		table = sample
+	}
+	return table, nil
+}
+
+// calciteExplainLevels Spark 的 EXPLAIN 模式对应的 Calcite SqlExplainLevel 和 SqlExplain.Depth
+var calciteExplainLevels = map[string][2]string{
+	"":          {"EXPPLAN_ATTRIBUTES", "PHYSICAL"},
+	"LOGICAL":   {"EXPPLAN_ATTRIBUTES", "LOGICAL"},
+	"EXTENDED":  {"ALL_ATTRIBUTES", "PHYSICAL"},
+	"FORMATTED": {"ALL_ATTRIBUTES", "PHYSICAL"},
+	"CODEGEN":   {"ALL_ATTRIBUTES", "PHYSICAL"},
+	"COST":      {"ALL_ATTRIBUTES", "PHYSICAL"},
+}
+
+func (e *calciteExporter) explain(n *SqlExplain) (calciteObject, error) {
+	level, ok := calciteExplainLevels[strings.ToUpper(n.Mode)]
+	if !ok {
+		return nil, fmt.Errorf("未知的 EXPLAIN 模式: %s", n.Mode)
+	}
+	return e.explainOf(n.Statement, level[0], level[1])
+}
+
+// explainOf 构造 Calcite 的 SqlExplain，输出格式总是 TEXT
+func (e *calciteExporter) explainOf(statement SqlNode, detailLevel, depth string) (calciteObject, error) {
+	return e.fields(calciteObject{"class": "SqlExplain", "kind": "EXPLAIN", "detailLevel": detailLevel, "depth": depth, "format": "TEXT"},
+		map[string]SqlNode{"explicandum": statement})
+}
+
+// describe DESCRIBE QUERY 在 Calcite 中为深度为 TYPE 的 SqlExplain（DESCRIBE STATEMENT）
+func (e *calciteExporter) describe(n *SqlDescribe) (calciteObject, error) {
+	if n.Query != nil {
+		return e.explainOf(n.Query, "EXPPLAN_ATTRIBUTES", "TYPE")
+	}
+	return e.fields(calciteObject{"class": "SqlDescribeTable", "kind": "DESCRIBE_TABLE", "option": n.Option},
+		map[string]SqlNode{"table": identifierOrNil(n.Table), "column": identifierOrNil(n.Column)})
+}
+
+// identifierOrNil 避免 nil 指针变为非 nil 接口
+func identifierOrNil(identifier *SqlIdentifier) SqlNode {
+	if identifier == nil {
+		return nil
+	}
+	return identifier
+}
+
+// =============================================================================
+// 对比 - 报告 Go 与 Java 输出的差异
+// =============================================================================
+
+// CalciteDiff 两棵树在某个位置上的差异，值为紧凑的 JSON 文本，缺少时为空字符串
+type CalciteDiff struct {
+	Path string // 如 $.where.operands[0].names[1]
+	Java string
+	Go   string
+}
+
+func (d CalciteDiff) String() string {
+	return fmt.Sprintf("%s: java=%s go=%s", d.Path, orMissing(d.Java), orMissing(d.Go))
+}
+
+func orMissing(value string) string {
+	if value == "" {
+		return "<缺少>"
+	}
+	return value
+}
+
+// CompareCalcite 把语法树与 Java 端导出的 JSON 对比，返回所有差异，完全一致时返回 nil
+// class 或 kind 不同的节点只报告一次，不再比较其子节点；缺少的字段与 null 视为相同
+func CompareCalcite(node SqlNode, expected []byte, opts CalciteOptions) ([]CalciteDiff, error) {
+	actual, err := ExportCalcite(node, opts)
+	if err != nil {
+		return nil, err
+	}
+	javaValue, err := decodeCalcite(expected)
+	if err != nil {
+		return nil, fmt.Errorf("解析 Java 端输出失败: %w", err)
+	}
+	goValue, err := decodeCalcite(actual)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []CalciteDiff
+	diffCalcite("$", javaValue, goValue, &diffs)
+	return diffs, nil
+}
+
+// decodeCalcite 解码为通用 JSON 值，数字保留原始文本
+func decodeCalcite(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// diffCalcite 递归比较两个通用 JSON 值
+func diffCalcite(path string, java, goValue interface{}, diffs *[]CalciteDiff) {
+	javaObj, javaIsObj := java.(map[string]interface{})
+	goObj, goIsObj := goValue.(map[string]interface{})
+	if javaIsObj && goIsObj {
+		if javaObj["class"] != goObj["class"] || javaObj["kind"] != goObj["kind"] {
+			*diffs = append(*diffs, CalciteDiff{Path: path, Java: nodeSummary(javaObj), Go: nodeSummary(goObj)})
+			return
+		}
+		keys := map[string]bool{}
+		for key := range javaObj {
+			keys[key] = true
+		}
+		for key := range goObj {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			diffCalcite(path+"."+key, javaObj[key], goObj[key], diffs)
+		}
+		return
+	}
+
+	javaList, javaIsList := java.([]interface{})
+	goList, goIsList := goValue.([]interface{})
+	if javaIsList && goIsList {
+		for i := 0; i < len(javaList) || i < len(goList); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(goList):
+				*diffs = append(*diffs, CalciteDiff{Path: elemPath, Java: compactJSON(javaList[i])})
+			case i >= len(javaList):
+				*diffs = append(*diffs, CalciteDiff{Path: elemPath, Go: compactJSON(goList[i])})
+			default:
+				diffCalcite(elemPath, javaList[i], goList[i], diffs)
+			}
+		}
+		return
+	}
+
+	if javaText, goText := compactJSON(java), compactJSON(goValue); javaText != goText {
+		*diffs = append(*diffs, CalciteDiff{Path: path, Java: javaText, Go: goText})
+	}
+}
+
+// nodeSummary 节点的简短描述，用于 class 或 kind 不同时
+func nodeSummary(obj map[string]interface{}) string {
+	return fmt.Sprintf("%v(%v)", obj["class"], obj["kind"])
+}
+
+// compactJSON 输出紧凑的 JSON 文本，nil 输出为空字符串
+func compactJSON(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// =============================================================================
+// 语料对比 - 批量对比 Java 端导出的测试数据
+// =============================================================================
+
+// CalciteCorpusResult 一条查询的对比结果
+type CalciteCorpusResult struct {
+	Name  string // 文件名（不含扩展名）
+	SQL   string
+	Diffs []CalciteDiff
+	Err   error // 读取、解析或导出失败
+}
+
+// OK 是否与 Java 端完全一致
+func (r CalciteCorpusResult) OK() bool {
+	return r.Err == nil && len(r.Diffs) == 0
+}
+
+// CompareCalciteCorpus 对比目录下的所有查询：每条查询为一个 name.sql 文件，
+// Java 端导出的 JSON 为同名的 name.json 文件。结果按文件名排序
+func CompareCalciteCorpus(dir string, opts CalciteOptions) ([]CalciteCorpusResult, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	results := make([]CalciteCorpusResult, 0, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sql")
+		result := CalciteCorpusResult{Name: name}
+		result.Diffs, result.Err = compareCalciteFile(file, strings.TrimSuffix(file, ".sql")+".json", opts, &result.SQL)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// compareCalciteFile 解析 sqlFile 并与 jsonFile 对比
+func compareCalciteFile(sqlFile, jsonFile string, opts CalciteOptions, sql *string) ([]CalciteDiff, error) {
+	data, err := os.ReadFile(sqlFile)
+	if err != nil {
+		return nil, err
+	}
+	*sql = strings.TrimSpace(string(data))
+	expected, err := os.ReadFile(jsonFile)
+	if err != nil {
+		return nil, fmt.Errorf("缺少 Java 端的输出: %w", err)
+	}
+	result, err := ParseSQLWithAntlr(*sql)
+	if err != nil {
+		return nil, fmt.Errorf("解析失败: %w", err)
+	}
+	return CompareCalcite(result.SqlNode, expected, opts)
+}
+
+// FormatCalciteReport 输出对比报告，每条查询一行结果，之后列出差异，最后为汇总
+func FormatCalciteReport(results []CalciteCorpusResult) string {
+	var sb strings.Builder
+	passed, failed, errored := 0, 0, 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			errored++
+			fmt.Fprintf(&sb, "ERROR %s: %v\n", result.Name, result.Err)
+		case len(result.Diffs) > 0:
+			failed++
+			fmt.Fprintf(&sb, "FAIL  %s (%d 处差异)\n", result.Name, len(result.Diffs))
+			for _, diff := range result.Diffs {
+				fmt.Fprintf(&sb, "      %s\n", diff)
+			}
+		default:
+			passed++
+			fmt.Fprintf(&sb, "PASS  %s\n", result.Name)
+		}
+	}
+	fmt.Fprintf(&sb, "共 %d 条查询: %d 条一致, %d 条不一致, %d 条出错\n", len(results), passed, failed, errored)
+	return sb.String()
+}
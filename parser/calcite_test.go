@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+// TestCalciteOperator 测试操作符的 Calcite SqlKind 和名称
+func TestCalciteOperator(t *testing.T) {
+	tests := []struct {
+		op   *SqlOperator
+		kind string
+		name string
+	}{
+		{NewSqlOperator("=", SqlKindEquals, SyntaxBinary), "EQUALS", "="},
+		{NewSqlOperator("!=", SqlKindNotEquals, SyntaxBinary), "NOT_EQUALS", "<>"},
+		{NewSqlOperator(">=", SqlKindGreaterThanOrEqual, SyntaxBinary), "GREATER_THAN_OR_EQUAL", ">="},
+		{NewSqlOperator("<=", SqlKindLessThanOrEqual, SyntaxBinary), "LESS_THAN_OR_EQUAL", "<="},
+		{NewSqlOperator("IS NULL", SqlKindOther, SyntaxPostfix), "IS_NULL", "IS NULL"},
+		{NewSqlOperator("-", SqlKindMinus, SyntaxPrefix), "MINUS_PREFIX", "-"},
+		{NewSqlOperator("-", SqlKindMinus, SyntaxBinary), "MINUS", "-"},
+		{NewSqlOperator("count", SqlKindCall, SyntaxFunction), "COUNT", "COUNT"},
+		{NewSqlOperator("NVL", SqlKindCall, SyntaxFunction), "OTHER_FUNCTION", "NVL"},
+		{NewSqlOperator("TRY_CAST", SqlKindCast, SyntaxSpecial), "SAFE_CAST", "TRY_CAST"},
+		{NewSqlOperator(".", SqlKindDot, SyntaxSpecial), "DOT", "DOT"},
+		{NewSqlOperator("DESC", SqlKindDescending, SyntaxPostfix), "DESCENDING", "DESC"},
+		{NewSqlOperator("||", SqlKindOther, SyntaxBinary), "OTHER", "||"},
+	}
+	for _, tt := range tests {
+		kind, name := calciteOperator(tt.op)
+		if kind != tt.kind || name != tt.name {
+			t.Errorf("calciteOperator(%s) = %s, %s, 期望 %s, %s", tt.op.Name, kind, name, tt.kind, tt.name)
+		}
+	}
+}
+
+// TestExportCalcite 测试导出的节点结构
+func TestExportCalcite(t *testing.T) {
+	join := NewSqlJoin(writerIdent("a"), writerAlias(writerIdent("db", "b"), "x"), JoinLeft, nil, nil)
+	join.Using = []SqlNode{writerIdent("id")}
+	sel := NewSqlSelect(&SqlParserPos{LineNumber: 1, ColumnNumber: 0, EndLine: 1, EndColumn: 30})
+	sel.SelectList = []SqlNode{writerIdent("a", "*")}
+	sel.From = join
+
+	data, err := ExportCalcite(sel, CalciteOptions{IncludePositions: true})
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("输出不是合法的 JSON: %v", err)
+	}
+
+	if pos := got["pos"].(map[string]interface{}); pos["columnNumber"] != 1.0 || pos["endColumnNumber"] != 30.0 {
+		t.Errorf("位置应转换为从 1 开始的列号, 实际为 %v", pos)
+	}
+	if got["groupBy"] != nil || got["orderBy"] != nil {
+		t.Errorf("没有 GROUP BY / ORDER BY 时应为 null")
+	}
+	star := got["selectList"].(map[string]interface{})["list"].([]interface{})[0].(map[string]interface{})
+	if names := star["names"].([]interface{}); names[1] != "" {
+		t.Errorf("* 应表示为空字符串, 实际为 %v", names)
+	}
+
+	from := got["from"].(map[string]interface{})
+	if from["joinType"] != "LEFT" || from["conditionType"] != "USING" || from["natural"] != false {
+		t.Errorf("JOIN 的字段 = %v", from)
+	}
+	right := from["right"].(map[string]interface{})
+	if right["kind"] != "AS" || right["operator"] != "AS" || len(right["operands"].([]interface{})) != 2 {
+		t.Errorf("别名应导出为 AS 调用, 实际为 %v", right)
+	}
+
+	if _, err := ExportCalcite(NewSqlErrorNode("selec", nil), CalciteOptions{}); err == nil {
+		t.Errorf("错误节点应无法导出")
+	}
+}
+
+// TestCompareCalcite 测试差异的位置和内容
+func TestCompareCalcite(t *testing.T) {
+	tree := buildDialectTestTree()
+	expected, err := ExportCalcite(tree, CalciteOptions{})
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+	if diffs, err := CompareCalcite(tree.Clone(), expected, CalciteOptions{}); err != nil || diffs != nil {
+		t.Fatalf("相同的树应没有差异, 实际为 %v, %v", diffs, err)
+	}
+
+	changed := tree.Clone().(*SqlSelect)
+	changed.SelectList[2] = writerIdent("t", "other")
+	changed.From = writerAlias(writerIdent("t"), "x")
+	changed.Fetch = nil
+	diffs, err := CompareCalcite(changed, expected, CalciteOptions{})
+	if err != nil {
+		t.Fatalf("对比失败: %v", err)
+	}
+	want := []string{
+		`$.fetch: java={"class":"SqlNumericLiteral","kind":"LITERAL","typeName":"DECIMAL","value":"10"} go=<缺少>`,
+		`$.from: java=SqlIdentifier(IDENTIFIER) go=SqlBasicCall(AS)`,
+		`$.selectList.list[2].names[1]: java="` + "`Mixed`" + `" go="other"`,
+	}
+	if len(diffs) != len(want) {
+		t.Fatalf("差异 = %v", diffs)
+	}
+	for i := range want {
+		if diffs[i].String() != want[i] {
+			t.Errorf("差异[%d] = %s, 期望 %s", i, diffs[i], want[i])
+		}
+	}
+
+	if _, err := CompareCalcite(tree, []byte("{"), CalciteOptions{}); err == nil {
+		t.Errorf("Java 端输出格式错误时应返回错误")
+	}
+}
+
+// TestFormatCalciteReport 测试对比报告
+func TestFormatCalciteReport(t *testing.T) {
+	report := FormatCalciteReport([]CalciteCorpusResult{
+		{Name: "a"},
+		{Name: "b", Diffs: []CalciteDiff{{Path: "$.where", Java: `"x"`}}},
+		{Name: "c", Err: errors.New("解析失败")},
+	})
+	expected := "PASS  a\n" +
+		"FAIL  b (1 处差异)\n" +
+		"      $.where: java=\"x\" go=<缺少>\n" +
+		"ERROR c: 解析失败\n" +
+		"共 3 条查询: 1 条一致, 1 条不一致, 1 条出错\n"
+	if report != expected {
+		t.Errorf("报告 =\n%s\n期望\n%s", report, expected)
+	}
+}
+
+// TestCalciteCorpus 与 testdata/calcite 中 Java 端导出的语料对比
+// 缺少 Java 端输出的查询跳过；目前语料还没有任何 Java 端输出，见 testdata/calcite/README.md
+func TestCalciteCorpus(t *testing.T) {
+	results, err := CompareCalciteCorpus("testdata/calcite", CalciteOptions{})
+	if err != nil {
+		t.Fatalf("读取语料失败: %v", err)
+	}
+
+	compared := results[:0]
+	for _, result := range results {
+		if errors.Is(result.Err, fs.ErrNotExist) {
+			t.Logf("跳过 %s: 缺少 Java 端的输出", result.Name)
+			continue
+		}
+		compared = append(compared, result)
+	}
+	if len(compared) == 0 {
+		t.Skip("与 Java 端的对比尚未交付: testdata/calcite 中没有 Java 端导出的 JSON")
+	}
+	for _, result := range compared {
+		if !result.OK() {
+			t.Errorf("与 Java 端的语法树不一致:\n%s", strings.TrimSpace(FormatCalciteReport(compared)))
+			return
+		}
+	}
+}
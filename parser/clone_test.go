@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"testing"
+)
+
+// buildCloneTestTree 手工构建覆盖各类节点的语法树
+func buildCloneTestTree() *SqlSelect {
+	pos := func(line int) *SqlParserPos {
+		return &SqlParserPos{LineNumber: line, ColumnNumber: 1, EndLine: line, EndColumn: 10}
+	}
+	eq := func(left, right SqlNode) SqlNode {
+		return NewSqlCall(&SqlOperator{Name: "=", Kind: SqlKindEquals, Syntax: SyntaxBinary}, []SqlNode{left, right}, pos(3))
+	}
+
+	literal := NewSqlLiteral(int64(1), LiteralInteger, pos(2))
+	literal.TypeName = "INTEGER"
+
+	lambda := NewSqlLambda(
+		[]*SqlIdentifier{NewSqlIdentifier([]string{"x"}, pos(2))},
+		NewSqlCall(&SqlOperator{Name: "+", Kind: SqlKindPlus, Syntax: SyntaxBinary},
+			[]SqlNode{NewSqlIdentifier([]string{"x"}, pos(2)), literal}, pos(2)),
+		pos(2),
+	)
+	transform := NewSqlCall(&SqlOperator{Name: "TRANSFORM", Kind: SqlKindCall, Syntax: SyntaxFunction},
+		[]SqlNode{NewSqlIdentifier([]string{"a", "arr"}, pos(2)), lambda}, pos(2))
+
+	seed := int64(42)
+	tableRef := NewSqlTableRef(NewSqlIdentifier([]string{"db", "b"}, pos(4)),
+		&SqlTemporalSpec{Type: TemporalVersion, Value: NewSqlLiteral(int64(3), LiteralInteger, pos(4))},
+		&SqlSampleSpec{Method: SamplePercent, Value: NewSqlLiteral(int64(10), LiteralInteger, pos(4)), Seed: &seed},
+		pos(4))
+
+	join := NewSqlJoin(NewSqlIdentifier([]string{"a"}, pos(4)), tableRef, JoinInner,
+		eq(NewSqlIdentifier([]string{"a", "id"}, pos(4)), NewSqlIdentifier([]string{"b", "id"}, pos(4))), pos(4))
+	join.Using = []SqlNode{NewSqlIdentifier([]string{"id"}, pos(4))}
+	cross := NewSqlJoin(join, NewSqlIdentifier([]string{"c"}, pos(4)), JoinCross, nil, pos(4))
+
+	sel := NewSqlSelect(pos(1))
+	sel.Hints = []*SqlHint{NewSqlHint("JOIN", []SqlNode{NewSqlIdentifier([]string{"TEE"}, pos(1))}, pos(1))}
+	sel.KeywordList = []string{"DISTINCT"}
+	alias := NewSqlCall(&SqlOperator{Name: "AS", Kind: SqlKindAs, Syntax: SyntaxSpecial},
+		[]SqlNode{NewSqlIdentifier([]string{"a", "x"}, pos(2)), NewSqlIdentifier([]string{"ax"}, pos(2))}, pos(2))
+	sel.SelectList = []SqlNode{transform, alias}
+	sel.From = cross
+	sel.Where = eq(NewSqlIdentifier([]string{"a", "x"}, pos(5)), NewSqlLiteral("v", LiteralString, pos(5)))
+	sel.GroupBy = []SqlNode{NewSqlIdentifier([]string{"a", "x"}, pos(6))}
+	sel.Having = eq(NewSqlIdentifier([]string{"a", "y"}, pos(6)), NewSqlLiteral(int64(2), LiteralInteger, pos(6)))
+	sel.WindowDecls = []SqlNode{NewSqlNodeList([]SqlNode{NewSqlIdentifier([]string{"w"}, pos(7))}, pos(7))}
+	sel.OrderBy = []SqlNode{NewSqlIdentifier([]string{"a", "x"}, pos(8))}
+	sel.Offset = NewSqlLiteral(int64(5), LiteralInteger, pos(8))
+	sel.Fetch = NewSqlLiteral(int64(10), LiteralInteger, pos(8))
+	return sel
+}
+
+// TestCloneIsDeep 测试克隆后修改副本不影响原树
+func TestCloneIsDeep(t *testing.T) {
+	original := buildCloneTestTree()
+	before := original.ToString()
+
+	clone := original.Clone().(*SqlSelect)
+	if clone == original {
+		t.Fatalf("Clone 返回了同一个节点")
+	}
+	if got := clone.ToString(); got != before {
+		t.Fatalf("克隆结果 = %q，期望 %q", got, before)
+	}
+
+	// 修改副本的各个部分
+	clone.Pos.LineNumber = 100
+	clone.Hints[0].Parameters[0].(*SqlIdentifier).Names[0] = "CHANGED"
+	clone.KeywordList[0] = "ALL"
+	transform := clone.SelectList[0].(*SqlCall)
+	transform.Operator.Name = "CHANGED"
+	lambda := transform.Operands[1].(*SqlLambda)
+	lambda.Parameters[0].Names[0] = "y"
+	lambda.Body.(*SqlCall).Operands[1].(*SqlLiteral).TypeName = "BIGINT"
+	clone.SelectList[1].(*SqlCall).Operands[1].(*SqlIdentifier).Names[0] = "changed"
+	cross := clone.From.(*SqlJoin)
+	join := cross.Left.(*SqlJoin)
+	join.Using[0].(*SqlIdentifier).Names[0] = "changed"
+	join.Condition.(*SqlCall).Operands[0].(*SqlIdentifier).Names[0] = "changed"
+	tableRef := join.Right.(*SqlTableRef)
+	tableRef.Name.Names[0] = "changed"
+	tableRef.Temporal.Value.(*SqlLiteral).Value = int64(4)
+	*tableRef.Sample.Seed = 7
+	clone.Where.(*SqlCall).Operands[1].(*SqlLiteral).Value = "changed"
+	clone.GroupBy[0].(*SqlIdentifier).Names[1] = "changed"
+	clone.Having.(*SqlCall).Operands[0].(*SqlIdentifier).Pos.LineNumber = 100
+	clone.WindowDecls[0].(*SqlNodeList).List[0].(*SqlIdentifier).Names[0] = "changed"
+	clone.OrderBy[0].(*SqlIdentifier).Names[1] = "changed"
+	clone.Fetch.(*SqlLiteral).Value = int64(20)
+
+	if got := original.ToString(); got != before {
+		t.Errorf("修改副本影响了原树:\n得到 %q\n期望 %q", got, before)
+	}
+	if original.Pos.LineNumber != 1 {
+		t.Errorf("位置信息被共享")
+	}
+	origTransform := original.SelectList[0].(*SqlCall)
+	if origTransform.Operator.Name != "TRANSFORM" {
+		t.Errorf("操作符被共享")
+	}
+	origLiteral := origTransform.Operands[1].(*SqlLambda).Body.(*SqlCall).Operands[1].(*SqlLiteral)
+	if origLiteral.TypeName != "INTEGER" {
+		t.Errorf("TypeName 被共享或丢失: %q", origLiteral.TypeName)
+	}
+	origJoin := original.From.(*SqlJoin).Left.(*SqlJoin)
+	if origJoin.Using[0].(*SqlIdentifier).Names[0] != "id" {
+		t.Errorf("USING 列表被共享")
+	}
+	if *origJoin.Right.(*SqlTableRef).Sample.Seed != 42 {
+		t.Errorf("采样种子被共享")
+	}
+	if original.Having.(*SqlCall).Operands[0].(*SqlIdentifier).Pos.LineNumber != 6 {
+		t.Errorf("子节点位置信息被共享")
+	}
+}
+
+// TestClonePreservesFields 测试克隆保留所有字段
+func TestClonePreservesFields(t *testing.T) {
+	literal := NewSqlLiteral("2024-01-01", LiteralDate, &SqlParserPos{LineNumber: 1})
+	literal.TypeName = "DATE"
+	clone := literal.Clone().(*SqlLiteral)
+	if clone.TypeName != "DATE" || clone.ValueType != LiteralDate || clone.Value != "2024-01-01" {
+		t.Errorf("字面量字段丢失: %+v", clone)
+	}
+
+	use := NewSqlUse(SqlKindSetCatalog, NewSqlIdentifier([]string{"hive"}, nil), nil)
+	if got := use.Clone().GetKind(); got != SqlKindSetCatalog {
+		t.Errorf("Kind = %s，期望 %s", got, SqlKindSetCatalog)
+	}
+
+	// CROSS JOIN 没有条件
+	cross := NewSqlJoin(NewSqlIdentifier([]string{"a"}, nil), NewSqlIdentifier([]string{"b"}, nil), JoinCross, nil, nil)
+	if got := cross.Clone().(*SqlJoin); got.Condition != nil || got.ToString() != cross.ToString() {
+		t.Errorf("CROSS JOIN 克隆结果 = %q", got.ToString())
+	}
+}
+
+// TestCloneNilElements 测试列表中的 nil 元素克隆后仍为 nil
+func TestCloneNilElements(t *testing.T) {
+	sel := NewSqlSelect(nil)
+	sel.Hints = []*SqlHint{nil}
+	sel.SelectList = []SqlNode{nil}
+	clone := sel.Clone().(*SqlSelect)
+	if len(clone.Hints) != 1 || clone.Hints[0] != nil || len(clone.SelectList) != 1 || clone.SelectList[0] != nil {
+		t.Errorf("SELECT 克隆结果 = %+v", clone)
+	}
+
+	lambda := NewSqlLambda([]*SqlIdentifier{nil, NewSqlIdentifier([]string{"x"}, nil)}, writerIdent("x"), nil)
+	cloned := lambda.Clone().(*SqlLambda)
+	if len(cloned.Parameters) != 2 || cloned.Parameters[0] != nil || cloned.Parameters[1].GetSimple() != "x" {
+		t.Errorf("lambda 参数克隆结果 = %v", cloned.Parameters)
+	}
+	if names := cloned.ParameterNames(); len(names) != 1 || !names["x"] {
+		t.Errorf("ParameterNames = %v", names)
+	}
+}
+
+// TestCloneWithPos 测试克隆时替换根节点位置
+func TestCloneWithPos(t *testing.T) {
+	original := buildCloneTestTree()
+	newPos := &SqlParserPos{LineNumber: 42, ColumnNumber: 7}
+
+	clone := CloneWithPos(original, newPos).(*SqlSelect)
+	if clone.Pos != newPos {
+		t.Errorf("根节点位置 = %+v，期望 %+v", clone.Pos, newPos)
+	}
+	if original.Pos.LineNumber != 1 {
+		t.Errorf("原树位置被修改")
+	}
+	if got := clone.Where.GetPos().LineNumber; got != 3 {
+		t.Errorf("子节点位置 = %d，期望保留原位置 3", got)
+	}
+
+	if CloneWithPos(nil, newPos) != nil {
+		t.Errorf("nil 节点应返回 nil")
+	}
+}
@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"strings"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+	"go-job-service/parser/antlr"
+)
+
+// =============================================================================
+// SqlComment - 普通注释
+// =============================================================================
+
+// SqlComment 解析时保留下来的 -- 或 /* */ 注释，hint（/*+ ... */）不属于注释
+type SqlComment struct {
+	Text string        // 注释原文，包括 -- 或 /* */，不含行尾换行
+	Pos  *SqlParserPos // 注释位置
+}
+
+// IsLineComment 是否为 -- 形式的行注释
+func (c *SqlComment) IsLineComment() bool {
+	return strings.HasPrefix(c.Text, "--")
+}
+
+// SqlComments 附着在节点上的注释
+// 节点前的注释为 Leading，同一行中紧跟在节点后的注释为 Trailing。
+// 注释不属于语法结构，Equal 和 Hash 不比较注释
+type SqlComments struct {
+	Leading  []*SqlComment
+	Trailing []*SqlComment
+}
+
+// Clone 复制注释，nil 时返回 nil
+func (c *SqlComments) Clone() *SqlComments {
+	if c == nil {
+		return nil
+	}
+	return &SqlComments{Leading: cloneComments(c.Leading), Trailing: cloneComments(c.Trailing)}
+}
+
+// cloneComments 复制注释列表
+func cloneComments(comments []*SqlComment) []*SqlComment {
+	if comments == nil {
+		return nil
+	}
+	cloned := make([]*SqlComment, len(comments))
+	for i, comment := range comments {
+		cloned[i] = &SqlComment{Text: comment.Text, Pos: comment.Pos.Clone()}
+	}
+	return cloned
+}
+
+// NodeComments 获取附着在节点上的注释，没有注释时返回 nil
+func NodeComments(node SqlNode) *SqlComments {
+	if commented, ok := node.(interface{ GetComments() *SqlComments }); ok {
+		return commented.GetComments()
+	}
+	return nil
+}
+
+// =============================================================================
+// 注释收集与附着
+// =============================================================================
+
+// sourcePoint 源码中的位置，行号从 1 开始，列号从 0 开始
+type sourcePoint struct {
+	line, column int
+}
+
+func (p sourcePoint) before(q sourcePoint) bool {
+	return p.line < q.line || (p.line == q.line && p.column < q.column)
+}
+
+// pendingComment 尚未附着到节点的注释
+type pendingComment struct {
+	comment *SqlComment
+	prevEnd sourcePoint // 注释前最后一个非注释 token 的结束位置，没有时为零值
+}
+
+// collectComments 从已读完的 token 流中收集隐藏通道上的注释
+// 形如 /* + ... */ 的注释很可能是写错的 hint，同时返回对应的警告
+func collectComments(stream *antlr4.CommonTokenStream) ([]pendingComment, []error) {
+	var comments []pendingComment
+	var warnings []error
+	var prevEnd sourcePoint
+	var source *sourceIndex
+
+	for _, token := range stream.GetAllTokens() {
+		switch token.GetTokenType() {
+		case antlr4.TokenEOF:
+		case antlr.SqlBaseLexerSIMPLE_COMMENT, antlr.SqlBaseLexerBRACKETED_COMMENT:
+			if source == nil {
+				source = newSourceIndex(token.GetInputStream())
+			}
+			comment := newSqlComment(token, source)
+			comments = append(comments, pendingComment{comment: comment, prevEnd: prevEnd})
+			if looksLikeHint(comment.Text) {
+				warnings = append(warnings, &MalformedHintWarning{Text: comment.Text, Pos: comment.Pos})
+			}
+		default:
+			if token.GetChannel() == antlr4.TokenDefaultChannel {
+				prevEnd = tokenEnd(token)
+			}
+		}
+	}
+	return comments, warnings
+}
+
+// newSqlComment 根据注释 token 创建注释，行注释去掉行尾换行
+func newSqlComment(token antlr4.Token, source *sourceIndex) *SqlComment {
+	text := strings.TrimRight(token.GetText(), "\r\n")
+	start := sourcePoint{token.GetLine(), token.GetColumn()}
+	end := textEnd(start, text)
+	offset := source.byteOffset(token.GetStart())
+	return &SqlComment{Text: text, Pos: &SqlParserPos{
+		LineNumber:   start.line,
+		ColumnNumber: start.column,
+		EndLine:      end.line,
+		EndColumn:    end.column,
+		StartOffset:  offset,
+		EndOffset:    offset + len(text),
+	}}
+}
+
+// looksLikeHint 判断块注释是否为多写了空白的 hint，如 /* + JOIN(TEE) */
+func looksLikeHint(text string) bool {
+	if !strings.HasPrefix(text, "/*") || strings.HasPrefix(text, "/*+") {
+		return false
+	}
+	body := strings.TrimLeft(text[2:], " \t\r\n")
+	return strings.HasPrefix(body, "+")
+}
+
+// positionedNode 带位置信息的节点，order 为前序遍历的序号，祖先节点在前
+type positionedNode struct {
+	node       SqlNode
+	start, end sourcePoint
+	order      int
+}
+
+// attachComments 把注释附着到最近的节点上：
+//   - 行注释附着到同一行中在它之前结束的节点，作为 Trailing
+//   - 块注释紧跟在节点之后（中间没有其他 token）且在同一行时，附着到该节点，作为 Trailing
+//   - 其余注释附着到其后开始的第一个节点，作为 Leading
+//
+// 多个节点在同一位置开始或结束时选择最外层的节点，找不到节点时附着到根节点
+func attachComments(root SqlNode, comments []pendingComment) {
+	if root == nil || len(comments) == 0 {
+		return
+	}
+
+	var nodes []positionedNode
+	Inspect(root, func(node SqlNode) bool {
+		if node == nil {
+			return false
+		}
+		// hint 在注释内部输出，不附着注释
+		if _, ok := node.(*SqlHint); ok {
+			return false
+		}
+		if pos := node.GetPos(); pos != nil && pos.LineNumber > 0 {
+			nodes = append(nodes, positionedNode{
+				node:  node,
+				start: sourcePoint{pos.LineNumber, pos.ColumnNumber},
+				end:   sourcePoint{pos.EndLine, pos.EndColumn},
+				order: len(nodes),
+			})
+		}
+		return true
+	})
+
+	for _, pending := range comments {
+		comment := pending.comment
+		start := sourcePoint{comment.Pos.LineNumber, comment.Pos.ColumnNumber}
+		end := sourcePoint{comment.Pos.EndLine, comment.Pos.EndColumn}
+
+		if target := trailingTarget(nodes, pending, start); target != nil {
+			commentsOf(target).Trailing = append(commentsOf(target).Trailing, comment)
+		} else if target := leadingTarget(nodes, end); target != nil {
+			commentsOf(target).Leading = append(commentsOf(target).Leading, comment)
+		} else {
+			commentsOf(root).Trailing = append(commentsOf(root).Trailing, comment)
+		}
+	}
+}
+
+// trailingTarget 查找注释应作为 Trailing 附着的节点
+func trailingTarget(nodes []positionedNode, pending pendingComment, start sourcePoint) SqlNode {
+	var best *positionedNode
+	for i := range nodes {
+		n := &nodes[i]
+		if n.end.line != start.line || start.before(n.end) {
+			continue
+		}
+		if !pending.comment.IsLineComment() && n.end != pending.prevEnd {
+			continue
+		}
+		if best == nil || best.end.before(n.end) ||
+			(n.end == best.end && (n.start.before(best.start) || (n.start == best.start && n.order < best.order))) {
+			best = n
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.node
+}
+
+// leadingTarget 查找在 end 之后最先开始的最外层节点
+func leadingTarget(nodes []positionedNode, end sourcePoint) SqlNode {
+	var best *positionedNode
+	for i := range nodes {
+		n := &nodes[i]
+		if n.start.before(end) {
+			continue
+		}
+		if best == nil || n.start.before(best.start) ||
+			(n.start == best.start && (best.end.before(n.end) || (n.end == best.end && n.order < best.order))) {
+			best = n
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.node
+}
+
+// commentsOf 获取节点的注释，没有时创建
+func commentsOf(node SqlNode) *SqlComments {
+	holder, ok := node.(interface{ base() *BaseSqlNode })
+	if !ok {
+		return &SqlComments{}
+	}
+	base := holder.base()
+	if base.Comments == nil {
+		base.Comments = &SqlComments{}
+	}
+	return base.Comments
+}
+
+// =============================================================================
+// SqlWriter 的注释输出
+// =============================================================================
+
+// writeLeadingComments 在节点前输出注释
+// 格式化时行注释之后换行，单行输出时行注释改写为块注释，避免注释掉后面的 SQL
+func (w *SqlWriter) writeLeadingComments(comments []*SqlComment) {
+	for _, comment := range comments {
+		if w.format != nil && comment.IsLineComment() {
+			w.write(comment.Text)
+			w.newline()
+			continue
+		}
+		w.write(blockComment(comment.Text))
+		w.write(" ")
+	}
+}
+
+// writeTrailingComments 在节点后输出注释，格式化时行注释推迟到行尾输出，之后的内容另起一行
+func (w *SqlWriter) writeTrailingComments(comments []*SqlComment) {
+	for _, comment := range comments {
+		if w.format != nil && comment.IsLineComment() {
+			w.lineComments = append(w.lineComments, comment.Text)
+			continue
+		}
+		w.write(" ")
+		w.write(blockComment(comment.Text))
+	}
+}
+
+// flushLineComments 在接下来要输出的 s 之前输出等待中的行注释并换行，返回 s 剩余的部分
+// 紧跟节点的逗号留在注释之前，s 本身以换行开始时不再额外换行
+func (w *SqlWriter) flushLineComments(s string) string {
+	comments := w.lineComments
+	w.lineComments = nil
+	if strings.HasPrefix(s, ",") {
+		w.write(",")
+		s = s[1:]
+	}
+	for _, comment := range comments {
+		w.write(" ")
+		w.write(comment)
+	}
+	if s != "" && s[0] != '\n' {
+		w.newline()
+		s = strings.TrimLeft(s, " ")
+	}
+	return s
+}
+
+// blockComment 把行注释改写为块注释，块注释保持不变
+func blockComment(text string) string {
+	if !strings.HasPrefix(text, "--") {
+		return text
+	}
+	body := strings.TrimSpace(strings.TrimPrefix(text, "--"))
+	return "/* " + strings.ReplaceAll(body, "*/", "* /") + " */"
+}
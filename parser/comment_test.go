@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf8"
+)
+
+// commentAt 构造位于 line 行 column 列的单行注释
+func commentAt(text string, line, column int) *SqlComment {
+	end := column + utf8.RuneCountInString(text)
+	return &SqlComment{Text: text, Pos: &SqlParserPos{LineNumber: line, ColumnNumber: column, EndLine: line, EndColumn: end}}
+}
+
+// positioned 设置节点位置
+func positioned(node SqlNode, line, column, endLine, endColumn int) SqlNode {
+	node.(interface{ setPos(*SqlParserPos) }).setPos(&SqlParserPos{LineNumber: line, ColumnNumber: column, EndLine: endLine, EndColumn: endColumn})
+	return node
+}
+
+// TestAttachComments 测试注释附着到最近的节点
+func TestAttachComments(t *testing.T) {
+	// -- 查询说明
+	// SELECT a, -- 第一列
+	//   /* 第二列 */ b /* b 之后 */
+	// FROM t
+	a := positioned(writerIdent("a"), 2, 7, 2, 8)
+	b := positioned(writerIdent("b"), 3, 12, 3, 13)
+	table := positioned(writerIdent("t"), 4, 5, 4, 6)
+	sel := NewSqlSelect(&SqlParserPos{LineNumber: 2, ColumnNumber: 0, EndLine: 4, EndColumn: 6})
+	sel.SelectList = []SqlNode{a, b}
+	sel.From = table
+
+	doc := commentAt("-- 查询说明", 1, 0)
+	first := commentAt("-- 第一列", 2, 10)
+	second := commentAt("/* 第二列 */", 3, 2)
+	after := commentAt("/* b 之后 */", 3, 14)
+	attachComments(sel, []pendingComment{
+		{comment: doc},
+		{comment: first, prevEnd: sourcePoint{2, 9}},
+		{comment: second, prevEnd: sourcePoint{2, 9}},
+		{comment: after, prevEnd: sourcePoint{3, 13}},
+	})
+
+	tests := []struct {
+		name     string
+		node     SqlNode
+		leading  []*SqlComment
+		trailing []*SqlComment
+	}{
+		{"查询", sel, []*SqlComment{doc}, nil},
+		{"a", a, nil, []*SqlComment{first}},
+		{"b", b, []*SqlComment{second}, []*SqlComment{after}},
+	}
+	for _, tt := range tests {
+		comments := NodeComments(tt.node)
+		if comments == nil {
+			t.Fatalf("%s 没有注释", tt.name)
+		}
+		if len(comments.Leading) != len(tt.leading) || len(comments.Trailing) != len(tt.trailing) {
+			t.Fatalf("%s 的注释 = %+v", tt.name, comments)
+		}
+		for i := range tt.leading {
+			if comments.Leading[i] != tt.leading[i] {
+				t.Errorf("%s 的 Leading[%d] = %q", tt.name, i, comments.Leading[i].Text)
+			}
+		}
+		for i := range tt.trailing {
+			if comments.Trailing[i] != tt.trailing[i] {
+				t.Errorf("%s 的 Trailing[%d] = %q", tt.name, i, comments.Trailing[i].Text)
+			}
+		}
+	}
+	if NodeComments(table) != nil {
+		t.Errorf("t 不应有注释")
+	}
+
+	// 输出时保留注释，单行输出时行注释改写为块注释
+	expected := "/* 查询说明 */ SELECT a /* 第一列 */, /* 第二列 */ b /* b 之后 */ FROM t"
+	if got := Unparse(sel); got != expected {
+		t.Errorf("Unparse = %q, 期望 %q", got, expected)
+	}
+	expected = "-- 查询说明\n" +
+		"SELECT\n" +
+		"  a, -- 第一列\n" +
+		"  /* 第二列 */ b /* b 之后 */\n" +
+		"FROM t"
+	if got := Format(sel, FormatOptions{SelectList: BreakAlways}); got != expected {
+		t.Errorf("Format =\n%s\n期望\n%s", got, expected)
+	}
+
+	// 克隆时复制注释
+	clone := sel.Clone().(*SqlSelect)
+	clone.Comments.Leading[0].Text = "-- changed"
+	if doc.Text != "-- 查询说明" {
+		t.Errorf("修改副本的注释影响了原节点")
+	}
+}
+
+// TestCommentHelpers 测试疑似 hint 的判断和行注释改写
+func TestCommentHelpers(t *testing.T) {
+	hints := map[string]bool{
+		"/* + JOIN(TEE) */": true,
+		"/*\n+ LOCAL */":    true,
+		"/*+ JOIN(TEE) */":  false,
+		"/* a + b */":       false,
+		"-- + JOIN(TEE)":    false,
+	}
+	for text, expected := range hints {
+		if got := looksLikeHint(text); got != expected {
+			t.Errorf("looksLikeHint(%q) = %v", text, got)
+		}
+	}
+
+	blocks := map[string]string{
+		"-- note":      "/* note */",
+		"--a */ b":     "/* a * / b */",
+		"/* block */":  "/* block */",
+		"--":           "/*  */",
+		"/* 多行\n注释 */": "/* 多行\n注释 */",
+	}
+	for text, expected := range blocks {
+		if got := blockComment(text); got != expected {
+			t.Errorf("blockComment(%q) = %q, 期望 %q", text, got, expected)
+		}
+	}
+}
+
+// TestParseComments 测试解析时保留注释并对写错的 hint 给出警告
+func TestParseComments(t *testing.T) {
+	sql := "-- 每日订单\n" +
+		"select /* + JOIN(TEE) */ o.id, -- 订单号\n" +
+		"  o.amount\n" +
+		"from orders o\n" +
+		"where o.amount > 0 -- 排除退款\n" +
+		"  and o.day = '2024-01-01'"
+
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	var warning *MalformedHintWarning
+	found := false
+	for _, w := range result.Warnings {
+		if errors.As(w, &warning) {
+			found = true
+		}
+	}
+	if !found || warning.Text != "/* + JOIN(TEE) */" || warning.Pos.LineNumber != 2 {
+		t.Errorf("应对写错的 hint 给出警告, Warnings = %v", result.Warnings)
+	}
+
+	count := 0
+	Inspect(result.SqlNode, func(node SqlNode) bool {
+		if comments := NodeComments(node); comments != nil {
+			count += len(comments.Leading) + len(comments.Trailing)
+		}
+		return true
+	})
+	if count != 4 {
+		t.Errorf("应保留 4 条注释, 实际为 %d", count)
+	}
+
+	// 格式化结果保留注释，且再次格式化不变
+	first := Format(result.SqlNode, FormatOptions{})
+	second, err := FormatSQL(first, FormatOptions{})
+	if err != nil {
+		t.Fatalf("重新解析 %q 失败: %v", first, err)
+	}
+	if first != second {
+		t.Errorf("格式化不是幂等的:\n%s\n再次格式化为\n%s", first, second)
+	}
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestComplexTypeExpressions 测试 lambda、元素访问、STRUCT 和行构造
+func TestComplexTypeExpressions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sql      string
+		kind     SqlKind
+		toString string
+	}{
+		{
+			name:     "单参数lambda",
+			sql:      "select transform(events.arr, x -> x + 1) from events",
+			kind:     SqlKindCall,
+			toString: "TRANSFORM(events.arr, x -> x + 1)",
+		},
+		{
+			name:     "多参数lambda",
+			sql:      "select map_filter(events.m, (k, v) -> v > 0) from events",
+			kind:     SqlKindCall,
+			toString: "MAP_FILTER(events.m, (k, v) -> v > 0)",
+		},
+		{
+			name:     "map元素访问",
+			sql:      "select events.m['key'] from events",
+			kind:     SqlKindItem,
+			toString: "events.m['key']",
+		},
+		{
+			name:     "数组元素访问后取字段",
+			sql:      "select events.arr[0].name from events",
+			kind:     SqlKindDot,
+			toString: "events.arr[0].name",
+		},
+		{
+			name:     "STRUCT构造",
+			sql:      "select struct(events.a, events.b) from events",
+			kind:     SqlKindStruct,
+			toString: "STRUCT(events.a, events.b)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseSQLWithAntlr(tc.sql)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+
+			sqlSelect, ok := result.SqlNode.(*SqlSelect)
+			if !ok {
+				t.Fatalf("期望 SqlSelect，实际得到: %T", result.SqlNode)
+			}
+			if len(sqlSelect.SelectList) != 1 {
+				t.Fatalf("期望 1 个 SELECT 项，实际得到: %d", len(sqlSelect.SelectList))
+			}
+
+			item := sqlSelect.SelectList[0]
+			if item.GetKind() != tc.kind {
+				t.Errorf("期望类型 %s，实际得到: %s", tc.kind, item.GetKind())
+			}
+			if item.ToString() != tc.toString {
+				t.Errorf("期望 %q，实际得到: %q", tc.toString, item.ToString())
+			}
+		})
+	}
+}
+
+// TestRowConstructorInList 测试行构造与 IN 列表
+func TestRowConstructorInList(t *testing.T) {
+	sql := "select events.a from events where (events.a, events.b) in ((1, 2), (3, 4))"
+
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	sqlSelect := result.SqlNode.(*SqlSelect)
+	in, ok := sqlSelect.Where.(*SqlCall)
+	if !ok || in.GetKind() != SqlKindIn {
+		t.Fatalf("期望 IN 条件，实际得到: %v", sqlSelect.Where)
+	}
+	if in.Operands[0].GetKind() != SqlKindRow {
+		t.Errorf("期望左侧为行构造，实际得到: %s", in.Operands[0].GetKind())
+	}
+	list, ok := in.Operands[1].(*SqlNodeList)
+	if !ok || len(list.List) != 2 {
+		t.Fatalf("期望 2 个 IN 项，实际得到: %v", in.Operands[1])
+	}
+	if got := in.ToString(); got != "(events.a, events.b) IN ((1, 2), (3, 4))" {
+		t.Errorf("ToString 不符合预期: %s", got)
+	}
+}
+
+// TestLambdaParametersAreNotColumns 测试 lambda 参数不会被当作列记录
+func TestLambdaParametersAreNotColumns(t *testing.T) {
+	sql := "select transform(events.arr, x -> x + events.offset) from events"
+
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	columns, err := ExtractColumns(result.SqlNode)
+	if err != nil {
+		t.Fatalf("提取列名失败: %v", err)
+	}
+	for _, col := range columns {
+		if col == "x" {
+			t.Errorf("lambda 参数 x 不应作为列名: %v", columns)
+		}
+	}
+}
+
+// TestExtractColumnsInLambdaBody 测试 lambda 体内的列会被提取，只排除 lambda 自身的参数
+func TestExtractColumnsInLambdaBody(t *testing.T) {
+	// x -> x IN (SELECT x, t.c FROM t)
+	sub := NewSqlSelect(nil)
+	sub.SelectList = []SqlNode{writerIdent("x"), writerIdent("t", "c")}
+	sub.From = writerIdent("t")
+	in := NewSqlCall(NewSqlOperator("IN", SqlKindIn, SyntaxBinary), []SqlNode{writerIdent("x"), sub}, nil)
+	lambda := NewSqlLambda([]*SqlIdentifier{NewSqlIdentifier([]string{"x"}, nil)}, in, nil)
+
+	columns, err := ExtractColumns(lambda)
+	if err != nil {
+		t.Fatalf("提取列名失败: %v", err)
+	}
+	if len(columns) != 1 || columns[0] != "t.c" {
+		t.Errorf("期望 [t.c]，实际得到: %v", columns)
+	}
+}
@@ -0,0 +1,276 @@
+package parser
+
+import (
+	"strings"
+)
+
+// =============================================================================
+// SqlDialect - 目标方言
+// =============================================================================
+
+// SqlDialect 控制 SqlWriter 输出的 SQL 方言，类似 Calcite 的 SqlDialect
+// 解析器按 Spark SQL 解析，通过方言把语法树中的片段输出为各参与方数据库能执行的 SQL
+type SqlDialect interface {
+	// Name 方言名称
+	Name() string
+	// QuoteIdentifier 括起需要引号的标识符
+	QuoteIdentifier(name string) string
+	// QuoteString 输出字符串字面量
+	QuoteString(value string) string
+	// BooleanLiteral 输出布尔字面量
+	BooleanLiteral(value bool) string
+	// DatetimeLiteral 输出 DATE / TIME / TIMESTAMP 字面量
+	DatetimeLiteral(valueType SqlLiteralType, value string) string
+	// IntervalLiteral 输出时间间隔字面量，value 为数量，unit 为单位，如 DAY
+	IntervalLiteral(value, unit string) string
+	// CastTypeName 把 Spark 的类型名转换为 CAST 中使用的类型名
+	CastTypeName(typeName string) string
+	// FunctionName 把 Spark 的函数名转换为方言中的函数名
+	FunctionName(name string) string
+	// LimitStyle 分页子句的写法
+	LimitStyle() LimitStyle
+	// SupportsNullsOrdering 是否支持 NULLS FIRST / NULLS LAST，不支持时按 x IS NULL 排序模拟
+	SupportsNullsOrdering() bool
+}
+
+// LimitStyle 分页子句的写法
+type LimitStyle int
+
+const (
+	LimitOffset LimitStyle = iota // LIMIT n OFFSET m
+	OffsetFetch                   // OFFSET m ROWS FETCH NEXT n ROWS ONLY
+	LimitComma                    // LIMIT m, n
+)
+
+// IntervalStyle 时间间隔字面量的写法
+type IntervalStyle int
+
+const (
+	IntervalQuotedValue IntervalStyle = iota // INTERVAL '1' DAY
+	IntervalQuotedAll                        // INTERVAL '1 DAY'
+)
+
+// BaseSqlDialect 由配置驱动的方言实现，内置方言都基于它
+// 需要定制个别行为时可以嵌入 BaseSqlDialect 并覆盖对应方法
+type BaseSqlDialect struct {
+	DialectName      string
+	IdentifierQuote  byte              // 标识符引号，` 或 "
+	BackslashEscapes bool              // 字符串字面量是否使用反斜杠转义，否则只把 ' 写为 ''
+	Limit            LimitStyle        // 分页子句写法
+	Interval         IntervalStyle     // 时间间隔字面量写法
+	NullsOrdering    bool              // 是否支持 NULLS FIRST / NULLS LAST
+	CastTypes        map[string]string // Spark 类型名（大写）到方言类型名
+	Functions        map[string]string // Spark 函数名（大写）到方言函数名
+}
+
+func (d *BaseSqlDialect) Name() string {
+	return d.DialectName
+}
+
+func (d *BaseSqlDialect) QuoteIdentifier(name string) string {
+	quote := string(d.IdentifierQuote)
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+func (d *BaseSqlDialect) QuoteString(value string) string {
+	if d.BackslashEscapes {
+		return QuoteString(value)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (d *BaseSqlDialect) BooleanLiteral(value bool) string {
+	if value {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+func (d *BaseSqlDialect) DatetimeLiteral(valueType SqlLiteralType, value string) string {
+	keyword := "TIMESTAMP"
+	switch valueType {
+	case LiteralDate:
+		keyword = "DATE"
+	case LiteralTime:
+		keyword = "TIME"
+	}
+	return keyword + " " + d.QuoteString(value)
+}
+
+func (d *BaseSqlDialect) IntervalLiteral(value, unit string) string {
+	if d.Interval == IntervalQuotedAll {
+		return "INTERVAL " + d.QuoteString(value+" "+unit)
+	}
+	return "INTERVAL " + d.QuoteString(value) + " " + unit
+}
+
+// CastTypeName 只替换类型的基本名称，保留 DECIMAL(10, 2)、ARRAY<INT> 等参数部分
+func (d *BaseSqlDialect) CastTypeName(typeName string) string {
+	base, params := typeName, ""
+	if i := strings.IndexAny(typeName, "(<"); i >= 0 {
+		base, params = typeName[:i], typeName[i:]
+	}
+	if mapped, ok := d.CastTypes[strings.ToUpper(strings.TrimSpace(base))]; ok {
+		return mapped + params
+	}
+	return typeName
+}
+
+func (d *BaseSqlDialect) FunctionName(name string) string {
+	if mapped, ok := d.Functions[strings.ToUpper(name)]; ok {
+		return mapped
+	}
+	return name
+}
+
+func (d *BaseSqlDialect) LimitStyle() LimitStyle {
+	return d.Limit
+}
+
+func (d *BaseSqlDialect) SupportsNullsOrdering() bool {
+	return d.NullsOrdering
+}
+
+// =============================================================================
+// 内置方言
+// =============================================================================
+
+var (
+	// SparkDialect Spark SQL，SqlWriter 的默认方言，输出与解析器接受的语法一致
+	SparkDialect SqlDialect = &BaseSqlDialect{
+		DialectName:      "spark",
+		IdentifierQuote:  '`',
+		BackslashEscapes: true,
+		Limit:            LimitOffset,
+		Interval:         IntervalQuotedValue,
+		NullsOrdering:    true,
+	}
+
+	// HiveDialect Hive SQL
+	HiveDialect SqlDialect = &BaseSqlDialect{
+		DialectName:      "hive",
+		IdentifierQuote:  '`',
+		BackslashEscapes: true,
+		Limit:            LimitComma,
+		Interval:         IntervalQuotedValue,
+		NullsOrdering:    true,
+		CastTypes: map[string]string{
+			"BYTE":  "TINYINT",
+			"SHORT": "SMALLINT",
+			"LONG":  "BIGINT",
+			"REAL":  "FLOAT",
+		},
+		Functions: map[string]string{
+			"IFNULL":           "NVL",
+			"LEN":              "LENGTH",
+			"CHAR_LENGTH":      "LENGTH",
+			"CHARACTER_LENGTH": "LENGTH",
+		},
+	}
+
+	// TrinoDialect Trino / Presto
+	TrinoDialect SqlDialect = &BaseSqlDialect{
+		DialectName:     "trino",
+		IdentifierQuote: '"',
+		Limit:           OffsetFetch,
+		Interval:        IntervalQuotedValue,
+		NullsOrdering:   true,
+		CastTypes: map[string]string{
+			"STRING": "VARCHAR",
+			"BYTE":   "TINYINT",
+			"SHORT":  "SMALLINT",
+			"LONG":   "BIGINT",
+			"FLOAT":  "REAL",
+			"BINARY": "VARBINARY",
+		},
+		Functions: map[string]string{
+			"NVL":                   "COALESCE",
+			"IFNULL":                "COALESCE",
+			"SIZE":                  "CARDINALITY",
+			"ARRAY_CONTAINS":        "CONTAINS",
+			"COLLECT_LIST":          "ARRAY_AGG",
+			"APPROX_COUNT_DISTINCT": "APPROX_DISTINCT",
+			"LCASE":                 "LOWER",
+			"UCASE":                 "UPPER",
+			"LEN":                   "LENGTH",
+			"CHAR_LENGTH":           "LENGTH",
+			"CHARACTER_LENGTH":      "LENGTH",
+			"POW":                   "POWER",
+		},
+	}
+
+	// MySQLDialect MySQL 8
+	MySQLDialect SqlDialect = &BaseSqlDialect{
+		DialectName:      "mysql",
+		IdentifierQuote:  '`',
+		BackslashEscapes: true,
+		Limit:            LimitComma,
+		Interval:         IntervalQuotedValue,
+		NullsOrdering:    false,
+		CastTypes: map[string]string{
+			"STRING":    "CHAR",
+			"VARCHAR":   "CHAR",
+			"TINYINT":   "SIGNED",
+			"BYTE":      "SIGNED",
+			"SMALLINT":  "SIGNED",
+			"SHORT":     "SIGNED",
+			"INT":       "SIGNED",
+			"INTEGER":   "SIGNED",
+			"BIGINT":    "SIGNED",
+			"LONG":      "SIGNED",
+			"TIMESTAMP": "DATETIME",
+		},
+		Functions: map[string]string{
+			"NVL":    "COALESCE",
+			"LEN":    "CHAR_LENGTH",
+			"LENGTH": "CHAR_LENGTH", // MySQL 的 LENGTH 返回字节数
+		},
+	}
+
+	// PostgreSQLDialect PostgreSQL
+	PostgreSQLDialect SqlDialect = &BaseSqlDialect{
+		DialectName:     "postgresql",
+		IdentifierQuote: '"',
+		Limit:           LimitOffset,
+		Interval:        IntervalQuotedAll,
+		NullsOrdering:   true,
+		CastTypes: map[string]string{
+			"STRING":  "TEXT",
+			"TINYINT": "SMALLINT",
+			"BYTE":    "SMALLINT",
+			"SHORT":   "SMALLINT",
+			"LONG":    "BIGINT",
+			"FLOAT":   "REAL",
+			"DOUBLE":  "DOUBLE PRECISION",
+			"BINARY":  "BYTEA",
+		},
+		Functions: map[string]string{
+			"NVL":          "COALESCE",
+			"IFNULL":       "COALESCE",
+			"RAND":         "RANDOM",
+			"LCASE":        "LOWER",
+			"UCASE":        "UPPER",
+			"LEN":          "LENGTH",
+			"COLLECT_LIST": "ARRAY_AGG",
+			"SIZE":         "CARDINALITY",
+			"POW":          "POWER",
+		},
+	}
+)
+
+// dialects 按名称查找内置方言，包含常用别名
+var dialects = map[string]SqlDialect{
+	"spark":      SparkDialect,
+	"hive":       HiveDialect,
+	"trino":      TrinoDialect,
+	"presto":     TrinoDialect,
+	"mysql":      MySQLDialect,
+	"postgresql": PostgreSQLDialect,
+	"postgres":   PostgreSQLDialect,
+}
+
+// LookupDialect 按名称（不区分大小写）查找内置方言
+func LookupDialect(name string) (SqlDialect, bool) {
+	dialect, ok := dialects[strings.ToLower(name)]
+	return dialect, ok
+}
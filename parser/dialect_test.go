@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"testing"
+)
+
+// buildDialectTestTree 构造覆盖各方言差异的查询：
+// SELECT NVL(t.a, 0) AS `Total`, CAST(t.b AS STRING), t.`Mixed` FROM t
+// WHERE t.s = "it's" AND t.flag = TRUE AND t.d > DATE '2024-01-01' + INTERVAL '1' DAY
+// ORDER BY t.a DESC NULLS LAST LIMIT 10 OFFSET 5
+func buildDialectTestTree() *SqlSelect {
+	binary := func(name string, kind SqlKind, l, r SqlNode) SqlNode {
+		return NewSqlCall(NewSqlOperator(name, kind, SyntaxBinary), []SqlNode{l, r}, nil)
+	}
+	interval := NewSqlLiteral("1", LiteralInterval, nil)
+	interval.TypeName = "DAY"
+
+	sel := NewSqlSelect(nil)
+	sel.SelectList = []SqlNode{
+		writerAlias(NewSqlCall(NewSqlOperator("NVL", SqlKindCall, SyntaxFunction),
+			[]SqlNode{writerIdent("t", "a"), NewSqlLiteral(int64(0), LiteralInteger, nil)}, nil), "`Total`"),
+		NewSqlCall(NewSqlOperator("CAST", SqlKindCast, SyntaxSpecial),
+			[]SqlNode{writerIdent("t", "b"), NewSqlLiteral("STRING", LiteralSymbol, nil)}, nil),
+		writerIdent("t", "`Mixed`"),
+	}
+	sel.From = writerIdent("t")
+	sel.Where = binary("AND", SqlKindAnd,
+		binary("AND", SqlKindAnd,
+			binary("=", SqlKindEquals, writerIdent("t", "s"), NewSqlLiteral("it's", LiteralString, nil)),
+			binary("=", SqlKindEquals, writerIdent("t", "flag"), NewSqlLiteral(true, LiteralBoolean, nil))),
+		binary(">", SqlKindGreaterThan, writerIdent("t", "d"),
+			binary("+", SqlKindPlus, NewSqlLiteral("2024-01-01", LiteralDate, nil), interval)))
+	desc := NewSqlCall(NewSqlOperator("DESC", SqlKindDescending, SyntaxPostfix), []SqlNode{writerIdent("t", "a")}, nil)
+	sel.OrderBy = []SqlNode{NewSqlCall(NewSqlOperator("NULLS LAST", SqlKindNullsLast, SyntaxPostfix), []SqlNode{desc}, nil)}
+	sel.Fetch = NewSqlLiteral(int64(10), LiteralInteger, nil)
+	sel.Offset = NewSqlLiteral(int64(5), LiteralInteger, nil)
+	return sel
+}
+
+// TestUnparseWithDialect 测试各内置方言的输出
+func TestUnparseWithDialect(t *testing.T) {
+	tree := buildDialectTestTree()
+
+	tests := []struct {
+		dialect  SqlDialect
+		expected string
+	}{
+		{
+			dialect: SparkDialect,
+			expected: "SELECT NVL(t.a, 0) AS `Total`, CAST(t.b AS STRING), t.`Mixed` FROM t" +
+				" WHERE t.s = 'it\\'s' AND t.flag = TRUE AND t.d > DATE '2024-01-01' + INTERVAL '1' DAY" +
+				" ORDER BY t.a DESC NULLS LAST LIMIT 10 OFFSET 5",
+		},
+		{
+			dialect: HiveDialect,
+			expected: "SELECT NVL(t.a, 0) AS `Total`, CAST(t.b AS STRING), t.`Mixed` FROM t" +
+				" WHERE t.s = 'it\\'s' AND t.flag = TRUE AND t.d > DATE '2024-01-01' + INTERVAL '1' DAY" +
+				" ORDER BY t.a DESC NULLS LAST LIMIT 5, 10",
+		},
+		{
+			dialect: TrinoDialect,
+			expected: "SELECT COALESCE(t.a, 0) AS \"Total\", CAST(t.b AS VARCHAR), t.\"Mixed\" FROM t" +
+				" WHERE t.s = 'it''s' AND t.flag = TRUE AND t.d > DATE '2024-01-01' + INTERVAL '1' DAY" +
+				" ORDER BY t.a DESC NULLS LAST OFFSET 5 ROWS FETCH NEXT 10 ROWS ONLY",
+		},
+		{
+			dialect: MySQLDialect,
+			expected: "SELECT COALESCE(t.a, 0) AS `Total`, CAST(t.b AS CHAR), t.`Mixed` FROM t" +
+				" WHERE t.s = 'it\\'s' AND t.flag = TRUE AND t.d > DATE '2024-01-01' + INTERVAL '1' DAY" +
+				" ORDER BY t.a IS NULL, t.a DESC LIMIT 5, 10",
+		},
+		{
+			dialect: PostgreSQLDialect,
+			expected: "SELECT COALESCE(t.a, 0) AS \"Total\", CAST(t.b AS TEXT), t.\"Mixed\" FROM t" +
+				" WHERE t.s = 'it''s' AND t.flag = TRUE AND t.d > DATE '2024-01-01' + INTERVAL '1 DAY'" +
+				" ORDER BY t.a DESC NULLS LAST LIMIT 10 OFFSET 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name(), func(t *testing.T) {
+			if got := UnparseWithDialect(tree, tt.dialect); got != tt.expected {
+				t.Errorf("输出 =\n%s\n期望\n%s", got, tt.expected)
+			}
+		})
+	}
+
+	if got := Unparse(tree); got != UnparseWithDialect(tree, SparkDialect) {
+		t.Errorf("默认方言应为 Spark")
+	}
+}
+
+// TestDialectDetails 测试类型名、引号和分页的细节
+func TestDialectDetails(t *testing.T) {
+	if got := PostgreSQLDialect.CastTypeName("double"); got != "DOUBLE PRECISION" {
+		t.Errorf("PostgreSQL DOUBLE = %q", got)
+	}
+	if got := MySQLDialect.CastTypeName("VARCHAR(10)"); got != "CHAR(10)" {
+		t.Errorf("MySQL VARCHAR(10) = %q", got)
+	}
+	if got := TrinoDialect.CastTypeName("ARRAY<INT>"); got != "ARRAY<INT>" {
+		t.Errorf("未映射的类型应保持不变, 实际为 %q", got)
+	}
+	if got := TrinoDialect.QuoteIdentifier(`a"b`); got != `"a""b"` {
+		t.Errorf("Trino 引号转义 = %q", got)
+	}
+
+	// NULLS FIRST 在 MySQL 中按 IS NULL 降序模拟
+	nullsFirst := NewSqlCall(NewSqlOperator("NULLS FIRST", SqlKindNullsFirst, SyntaxPostfix), []SqlNode{writerIdent("a")}, nil)
+	sel := NewSqlSelect(nil)
+	sel.SelectList = []SqlNode{writerIdent("a")}
+	sel.From = writerIdent("t")
+	sel.OrderBy = []SqlNode{nullsFirst}
+	sel.Offset = NewSqlLiteral(int64(5), LiteralInteger, nil)
+	if got := UnparseWithDialect(sel, MySQLDialect); got != "SELECT a FROM t ORDER BY a IS NULL DESC, a LIMIT 5, "+maxLimit {
+		t.Errorf("MySQL = %q", got)
+	}
+	if got := UnparseWithDialect(sel, TrinoDialect); got != "SELECT a FROM t ORDER BY a NULLS FIRST OFFSET 5 ROWS" {
+		t.Errorf("Trino = %q", got)
+	}
+
+	if dialect, ok := LookupDialect("Presto"); !ok || dialect != TrinoDialect {
+		t.Errorf("presto 应映射到 Trino 方言")
+	}
+	if _, ok := LookupDialect("oracle"); ok {
+		t.Errorf("未知方言不应找到")
+	}
+}
+
+// TestParsedCastTypeName 测试多个单词组成的类型名保留空格
+func TestParsedCastTypeName(t *testing.T) {
+	result, err := ParseSQLStrict("select cast(t.a as interval  day\n to second), cast(t.b as decimal(10, 2)) from t")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	sel := result.SqlNode.(*SqlSelect)
+	for i, expected := range []string{"interval day to second", "decimal(10, 2)"} {
+		typeName := sel.SelectList[i].(*SqlCall).Operands[1].(*SqlLiteral)
+		if typeName.Value != expected {
+			t.Errorf("类型 = %q, 期望 %q", typeName.Value, expected)
+		}
+	}
+	if got := UnparseWithDialect(sel, TrinoDialect); got != "SELECT CAST(t.a AS interval day to second), CAST(t.b AS decimal(10, 2)) FROM t" {
+		t.Errorf("Trino = %q", got)
+	}
+}
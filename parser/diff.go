@@ -0,0 +1,549 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// 结构差异 - 比较同一作业修改前后的查询
+// =============================================================================
+
+// ChangeAction 变化的类型
+type ChangeAction string
+
+const (
+	ChangeAdded    ChangeAction = "ADDED"
+	ChangeRemoved  ChangeAction = "REMOVED"
+	ChangeModified ChangeAction = "MODIFIED"
+)
+
+// ChangeKind 发生变化的语法成分
+type ChangeKind string
+
+const (
+	ChangeStatement     ChangeKind = "STATEMENT"      // 语句类型或无法细分的整条语句
+	ChangeSelectItem    ChangeKind = "SELECT_ITEM"    // 查询列
+	ChangeDistinct      ChangeKind = "DISTINCT"       // SELECT 关键字，如 DISTINCT
+	ChangeTable         ChangeKind = "TABLE"          // FROM 中的表或子查询
+	ChangeJoinType      ChangeKind = "JOIN_TYPE"      // 连接类型
+	ChangeJoinCondition ChangeKind = "JOIN_CONDITION" // ON / USING 条件
+	ChangePredicate     ChangeKind = "PREDICATE"      // WHERE / HAVING 中 AND 连接的条件
+	ChangeGroupBy       ChangeKind = "GROUP_BY"
+	ChangeOrderBy       ChangeKind = "ORDER_BY"
+	ChangeWindow        ChangeKind = "WINDOW"
+	ChangeLimit         ChangeKind = "LIMIT" // LIMIT / OFFSET
+	ChangeHint          ChangeKind = "HINT"
+)
+
+// Change 两个查询之间的一处语义变化
+type Change struct {
+	Action ChangeAction
+	Kind   ChangeKind
+	Path   string        // 变化所在位置，如 where[1]、from[u].joinType、from[s].selectList[0]
+	Old    string        // 修改前的 SQL 片段，新增时为空
+	New    string        // 修改后的 SQL 片段，删除时为空
+	OldPos *SqlParserPos // 修改前片段在旧 SQL 中的位置，可为 nil
+	NewPos *SqlParserPos // 修改后片段在新 SQL 中的位置，可为 nil
+
+	// NewAccess 修改后片段引用、而修改前的整个查询中从未引用过的表和列（小写）
+	NewAccess []string
+}
+
+// AddsDataAccess 变化是否引入了新的数据访问
+func (c Change) AddsDataAccess() bool {
+	return len(c.NewAccess) > 0
+}
+
+// String 单行文本形式，如 "+ SELECT_ITEM selectList[2]: t.c [2:8]"
+func (c Change) String() string {
+	var sb strings.Builder
+	switch c.Action {
+	case ChangeAdded:
+		fmt.Fprintf(&sb, "+ %s %s: %s%s", c.Kind, c.Path, c.New, formatChangePos(c.NewPos))
+	case ChangeRemoved:
+		fmt.Fprintf(&sb, "- %s %s: %s%s", c.Kind, c.Path, c.Old, formatChangePos(c.OldPos))
+	default:
+		fmt.Fprintf(&sb, "~ %s %s: %s%s -> %s%s", c.Kind, c.Path, c.Old, formatChangePos(c.OldPos), c.New, formatChangePos(c.NewPos))
+	}
+	if c.AddsDataAccess() {
+		fmt.Fprintf(&sb, " (新增访问: %s)", strings.Join(c.NewAccess, ", "))
+	}
+	return sb.String()
+}
+
+// formatChangePos 输出 [行:列]，列号从 1 开始，没有位置时为空
+func formatChangePos(pos *SqlParserPos) string {
+	if pos == nil || pos.LineNumber == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%d:%d]", pos.LineNumber, pos.ColumnNumber+1)
+}
+
+// MarshalJSON 位置与 MarshalNode 的编码相同
+func (c Change) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Action    ChangeAction `json:"action"`
+		Kind      ChangeKind   `json:"kind"`
+		Path      string       `json:"path"`
+		Old       string       `json:"old,omitempty"`
+		New       string       `json:"new,omitempty"`
+		OldPos    *jsonPos     `json:"oldPos,omitempty"`
+		NewPos    *jsonPos     `json:"newPos,omitempty"`
+		NewAccess []string     `json:"newAccess,omitempty"`
+	}{c.Action, c.Kind, c.Path, c.Old, c.New, toJSONPos(c.OldPos), toJSONPos(c.NewPos), c.NewAccess})
+}
+
+// FormatChanges 文本形式的差异，每行一处变化
+func FormatChanges(changes []Change) string {
+	var sb strings.Builder
+	for _, change := range changes {
+		sb.WriteString(change.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// AddsDataAccess 是否有任何变化引入了新的数据访问，只有这类修改需要重新审批
+func AddsDataAccess(changes []Change) bool {
+	for _, change := range changes {
+		if change.AddsDataAccess() {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff 比较修改前后的两个查询，返回语义上的变化，没有变化时返回 nil
+//
+// 比较忽略位置信息和注释。查询列按别名（没有别名时按文本）对应，FROM 中的表按别名或表名对应，
+// WHERE / HAVING 按 AND 拆分为条件后对应，引用相同列的条件视为修改。
+// 只调整顺序不算作变化。FROM 中同名的子查询递归比较，路径带上子查询的前缀
+func Diff(old, new SqlNode) []Change {
+	d := &differ{oldAccess: accessedNames(old)}
+	d.diffStatement("", old, new)
+	return d.changes
+}
+
+// differ 收集变化
+type differ struct {
+	changes   []Change
+	oldAccess map[string]bool // 旧查询引用过的表和列
+}
+
+// sameNode 忽略位置比较两个节点
+func sameNode(a, b SqlNode) bool {
+	return Equal(a, b, EqualOptions{IgnorePositions: true})
+}
+
+// diffText 变化中的 SQL 片段
+func diffText(node SqlNode) string {
+	if node == nil {
+		return ""
+	}
+	return Unparse(node)
+}
+
+func nodePos(node SqlNode) *SqlParserPos {
+	if node == nil {
+		return nil
+	}
+	return node.GetPos()
+}
+
+// add 记录一处变化，old / new 为变化两侧的节点
+func (d *differ) add(action ChangeAction, kind ChangeKind, path string, old, new SqlNode) {
+	change := Change{
+		Action: action,
+		Kind:   kind,
+		Path:   path,
+		Old:    diffText(old),
+		New:    diffText(new),
+		OldPos: nodePos(old),
+		NewPos: nodePos(new),
+	}
+	for name := range accessedNames(new) {
+		if !d.oldAccess[name] {
+			change.NewAccess = append(change.NewAccess, name)
+		}
+	}
+	sort.Strings(change.NewAccess)
+	d.changes = append(d.changes, change)
+}
+
+// diffNode 比较一对可能为 nil 的节点，整体记录为新增、删除或修改
+func (d *differ) diffNode(kind ChangeKind, path string, old, new SqlNode) {
+	switch {
+	case old == nil && new == nil:
+	case old == nil:
+		d.add(ChangeAdded, kind, path, nil, new)
+	case new == nil:
+		d.add(ChangeRemoved, kind, path, old, nil)
+	case !sameNode(old, new):
+		d.add(ChangeModified, kind, path, old, new)
+	}
+}
+
+// diffStatement 比较两条语句，SELECT 逐个子句比较，EXPLAIN 比较被解释的语句
+func (d *differ) diffStatement(path string, old, new SqlNode) {
+	switch o := old.(type) {
+	case *SqlSelect:
+		if n, ok := new.(*SqlSelect); ok {
+			d.diffSelect(path, o, n)
+			return
+		}
+	case *SqlExplain:
+		if n, ok := new.(*SqlExplain); ok && o.Mode == n.Mode {
+			d.diffStatement(joinPath(path, "explain"), o.Statement, n.Statement)
+			return
+		}
+	}
+	d.diffNode(ChangeStatement, path, old, new)
+}
+
+// joinPath 连接路径
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func (d *differ) diffSelect(path string, old, new *SqlSelect) {
+	d.diffHints(joinPath(path, "hints"), old.Hints, new.Hints)
+	d.diffNode(ChangeDistinct, joinPath(path, "keywords"), keywordNode(old), keywordNode(new))
+	d.diffKeyed(ChangeSelectItem, joinPath(path, "selectList"), old.SelectList, new.SelectList, selectItemKey)
+	d.diffFrom(joinPath(path, "from"), old.From, new.From)
+	d.diffPredicates(joinPath(path, "where"), old.Where, new.Where)
+	d.diffList(ChangeGroupBy, joinPath(path, "groupBy"), old.GroupBy, new.GroupBy)
+	d.diffPredicates(joinPath(path, "having"), old.Having, new.Having)
+	d.diffList(ChangeWindow, joinPath(path, "windowDecls"), old.WindowDecls, new.WindowDecls)
+	d.diffList(ChangeOrderBy, joinPath(path, "orderBy"), old.OrderBy, new.OrderBy)
+	d.diffNode(ChangeLimit, joinPath(path, "offset"), old.Offset, new.Offset)
+	d.diffNode(ChangeLimit, joinPath(path, "fetch"), old.Fetch, new.Fetch)
+}
+
+// keywordNode 把 SELECT 关键字表示为符号字面量，没有关键字时为 nil
+func keywordNode(sel *SqlSelect) SqlNode {
+	if len(sel.KeywordList) == 0 {
+		return nil
+	}
+	return NewSqlLiteral(strings.ToUpper(strings.Join(sel.KeywordList, " ")), LiteralSymbol, nil)
+}
+
+// diffList 整体比较列表，如 GROUP BY
+func (d *differ) diffList(kind ChangeKind, path string, old, new []SqlNode) {
+	var oldList, newList SqlNode
+	if len(old) > 0 {
+		oldList = NewSqlNodeList(old, syntheticPos(old...))
+	}
+	if len(new) > 0 {
+		newList = NewSqlNodeList(new, syntheticPos(new...))
+	}
+	d.diffNode(kind, path, oldList, newList)
+}
+
+// keyedNode 按键对应的节点，index 为在原列表中的下标
+type keyedNode struct {
+	key   string
+	node  SqlNode
+	index int
+}
+
+// keyNodes 计算每个节点的键，重复的键加上序号区分
+func keyNodes(nodes []SqlNode, key func(SqlNode) string) []keyedNode {
+	seen := make(map[string]int)
+	keyed := make([]keyedNode, len(nodes))
+	for i, node := range nodes {
+		k := key(node)
+		if seen[k]++; seen[k] > 1 {
+			k = fmt.Sprintf("%s#%d", k, seen[k])
+		}
+		keyed[i] = keyedNode{key: k, node: node, index: i}
+	}
+	return keyed
+}
+
+// diffKeyed 按键对应两侧的节点，逐个比较
+func (d *differ) diffKeyed(kind ChangeKind, path string, old, new []SqlNode, key func(SqlNode) string) {
+	oldKeyed := keyNodes(old, key)
+	byKey := make(map[string]keyedNode, len(oldKeyed))
+	for _, k := range oldKeyed {
+		byKey[k.key] = k
+	}
+
+	matched := make(map[string]bool)
+	for _, n := range keyNodes(new, key) {
+		itemPath := fmt.Sprintf("%s[%d]", path, n.index)
+		if o, ok := byKey[n.key]; ok {
+			matched[n.key] = true
+			d.diffNode(kind, itemPath, o.node, n.node)
+		} else {
+			d.add(ChangeAdded, kind, itemPath, nil, n.node)
+		}
+	}
+	for _, o := range oldKeyed {
+		if !matched[o.key] {
+			d.add(ChangeRemoved, kind, fmt.Sprintf("%s[%d]", path, o.index), o.node, nil)
+		}
+	}
+}
+
+// selectItemKey 查询列的键：别名，或者列名，其余表达式为其文本
+func selectItemKey(node SqlNode) string {
+	if _, alias := splitAlias(node); alias != "" {
+		return alias
+	}
+	return strings.ToLower(Unparse(node))
+}
+
+// splitAlias 拆开别名，返回被命名的节点和小写的别名，没有别名时原样返回节点
+// 解析器把别名表示为 AS 调用 [expr, alias]，也兼容 SqlBasicCall
+func splitAlias(node SqlNode) (SqlNode, string) {
+	switch n := node.(type) {
+	case *SqlCall:
+		if n.Operator != nil && n.Operator.Kind == SqlKindAs && len(n.Operands) == 2 {
+			if alias, ok := n.Operands[1].(*SqlIdentifier); ok {
+				return n.Operands[0], strings.ToLower(alias.GetSimple())
+			}
+		}
+	case *SqlBasicCall:
+		if n.Alias != "" {
+			return n.Operand, strings.ToLower(n.Alias)
+		}
+	}
+	return node, ""
+}
+
+// diffHints 按名称对应 hint
+func (d *differ) diffHints(path string, old, new []*SqlHint) {
+	toNodes := func(hints []*SqlHint) []SqlNode {
+		nodes := make([]SqlNode, len(hints))
+		for i, hint := range hints {
+			nodes[i] = hint
+		}
+		return nodes
+	}
+	d.diffKeyed(ChangeHint, path, toNodes(old), toNodes(new), func(node SqlNode) string {
+		return strings.ToUpper(node.(*SqlHint).Name)
+	})
+}
+
+// =============================================================================
+// FROM 的比较
+// =============================================================================
+
+// fromSource FROM 中的一个表，join 为把它连接进来的 JOIN，第一个表没有
+type fromSource struct {
+	key  string
+	node SqlNode
+	join *SqlJoin
+}
+
+// flattenFrom 按出现顺序展开 JOIN 树
+func flattenFrom(node SqlNode) []fromSource {
+	if node == nil {
+		return nil
+	}
+	if join, ok := node.(*SqlJoin); ok {
+		sources := flattenFrom(join.Left)
+		for i, source := range flattenFrom(join.Right) {
+			if i == 0 {
+				source.join = join
+			}
+			sources = append(sources, source)
+		}
+		return sources
+	}
+	return []fromSource{{key: sourceKey(node), node: node}}
+}
+
+// sourceKey 表的键：别名，或者表名
+func sourceKey(node SqlNode) string {
+	if _, alias := splitAlias(node); alias != "" {
+		return alias
+	}
+	switch n := node.(type) {
+	case *SqlTableRef:
+		if n.Name != nil {
+			return strings.ToLower(n.Name.ToString())
+		}
+	}
+	return strings.ToLower(Unparse(node))
+}
+
+// diffFrom 按键对应 FROM 中的表，比较表本身、连接类型和连接条件
+func (d *differ) diffFrom(path string, old, new SqlNode) {
+	oldSources := flattenFrom(old)
+	byKey := make(map[string]fromSource, len(oldSources))
+	for _, source := range oldSources {
+		byKey[source.key] = source
+	}
+
+	matched := make(map[string]bool)
+	for _, n := range flattenFrom(new) {
+		sourcePath := fmt.Sprintf("%s[%s]", path, n.key)
+		o, ok := byKey[n.key]
+		if !ok {
+			d.addSource(ChangeAdded, sourcePath, n)
+			continue
+		}
+		matched[n.key] = true
+		d.diffSource(sourcePath, o.node, n.node)
+		d.diffJoin(sourcePath, o.join, n.join)
+	}
+	for _, o := range oldSources {
+		if !matched[o.key] {
+			d.addSource(ChangeRemoved, fmt.Sprintf("%s[%s]", path, o.key), o)
+		}
+	}
+}
+
+// addSource 记录新增或删除的表，片段连同连接条件一起输出，如 LEFT JOIN u ON t.id = u.id
+// JOIN 的位置包含左侧的表，片段的位置改为覆盖右侧的表和连接条件
+func (d *differ) addSource(action ChangeAction, path string, source fromSource) {
+	node := source.node
+	if source.join != nil {
+		covered := append([]SqlNode{source.node, source.join.Condition}, source.join.Using...)
+		join := NewSqlJoin(nil, source.node, source.join.JoinType, source.join.Condition, syntheticPos(covered...))
+		join.Using = source.join.Using
+		node = join
+	}
+	if action == ChangeAdded {
+		d.add(action, ChangeTable, path, nil, node)
+	} else {
+		d.add(action, ChangeTable, path, node, nil)
+	}
+
+	// 没有左侧时 JOIN 以空格或逗号开始
+	change := &d.changes[len(d.changes)-1]
+	change.Old = strings.TrimLeft(change.Old, " ,")
+	change.New = strings.TrimLeft(change.New, " ,")
+}
+
+// diffSource 比较对应的表，同名子查询递归比较
+func (d *differ) diffSource(path string, old, new SqlNode) {
+	if sameNode(old, new) {
+		return
+	}
+	oldQuery, oldAlias := splitAlias(old)
+	newQuery, newAlias := splitAlias(new)
+	if oldSel, ok := oldQuery.(*SqlSelect); ok && oldAlias == newAlias {
+		if newSel, ok := newQuery.(*SqlSelect); ok {
+			d.diffSelect(path, oldSel, newSel)
+			return
+		}
+	}
+	d.add(ChangeModified, ChangeTable, path, old, new)
+}
+
+
+// diffJoin 比较把同一个表连接进来的 JOIN
+func (d *differ) diffJoin(path string, old, new *SqlJoin) {
+	if old == nil || new == nil {
+		if old != new {
+			d.add(ChangeModified, ChangeJoinType, joinPath(path, "joinType"), joinTypeNode(old), joinTypeNode(new))
+		}
+		return
+	}
+	if old.JoinType != new.JoinType {
+		d.add(ChangeModified, ChangeJoinType, joinPath(path, "joinType"), joinTypeNode(old), joinTypeNode(new))
+	}
+	d.diffNode(ChangeJoinCondition, joinPath(path, "on"), old.Condition, new.Condition)
+	d.diffList(ChangeJoinCondition, joinPath(path, "using"), old.Using, new.Using)
+}
+
+// joinTypeNode 把连接类型表示为符号字面量，位置为整个 JOIN；第一个表没有连接类型
+func joinTypeNode(join *SqlJoin) SqlNode {
+	if join == nil {
+		return nil
+	}
+	return NewSqlLiteral(string(join.JoinType), LiteralSymbol, join.Pos)
+}
+
+// =============================================================================
+// 条件的比较
+// =============================================================================
+
+// diffPredicates 按 AND 拆分后对应条件：文本相同的条件不变，
+// 剩下的条件中引用相同列的视为修改，其余为新增或删除
+func (d *differ) diffPredicates(path string, old, new SqlNode) {
+	oldTerms := splitAndConjuncts(old)
+	newTerms := splitAndConjuncts(new)
+
+	oldUsed := make([]bool, len(oldTerms))
+	var added []int
+	for j, n := range newTerms {
+		found := false
+		for i, o := range oldTerms {
+			if !oldUsed[i] && sameNode(o, n) {
+				oldUsed[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			added = append(added, j)
+		}
+	}
+
+	for _, j := range added {
+		n := newTerms[j]
+		columns := columnSet(n)
+		paired := -1
+		for i, o := range oldTerms {
+			if !oldUsed[i] && columnSet(o) == columns {
+				paired = i
+				break
+			}
+		}
+		itemPath := fmt.Sprintf("%s[%d]", path, j)
+		if paired < 0 {
+			d.add(ChangeAdded, ChangePredicate, itemPath, nil, n)
+			continue
+		}
+		oldUsed[paired] = true
+		d.add(ChangeModified, ChangePredicate, itemPath, oldTerms[paired], n)
+	}
+	for i, o := range oldTerms {
+		if !oldUsed[i] {
+			d.add(ChangeRemoved, ChangePredicate, fmt.Sprintf("%s[%d]", path, i), o, nil)
+		}
+	}
+}
+
+// columnSet 条件引用的列，排序后连接为字符串
+func columnSet(node SqlNode) string {
+	var names []string
+	for name := range accessedNames(node) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// accessedNames 子树中引用的表和列（标识符的小写全名），别名不算引用
+func accessedNames(node SqlNode) map[string]bool {
+	names := make(map[string]bool)
+	var collect func(SqlNode)
+	collect = func(node SqlNode) {
+		Inspect(node, func(child SqlNode) bool {
+			switch n := child.(type) {
+			case nil:
+				return false
+			case *SqlHint:
+				return false
+			case *SqlIdentifier:
+				names[strings.ToLower(strings.Join(n.Names, "."))] = true
+			}
+			if operand, alias := splitAlias(child); alias != "" {
+				collect(operand)
+				return false
+			}
+			return true
+		})
+	}
+	collect(node)
+	return names
+}
@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// buildDiffTestTree 构造
+// SELECT /*+ JOIN(TEE) */ t.id, t.name AS n FROM t LEFT JOIN u ON t.id = u.id WHERE t.day = '2024-01-01' AND t.amount > 0
+func buildDiffTestTree() *SqlSelect {
+	binary := func(name string, kind SqlKind, l, r SqlNode) SqlNode {
+		return NewSqlCall(NewSqlOperator(name, kind, SyntaxBinary), []SqlNode{l, r}, nil)
+	}
+	sel := NewSqlSelect(nil)
+	sel.Hints = []*SqlHint{NewSqlHint("JOIN", []SqlNode{writerIdent("TEE")}, nil)}
+	sel.SelectList = []SqlNode{writerIdent("t", "id"), writerAlias(writerIdent("t", "name"), "n")}
+	sel.From = NewSqlJoin(writerIdent("t"), writerIdent("u"), JoinLeft,
+		binary("=", SqlKindEquals, writerIdent("t", "id"), writerIdent("u", "id")), nil)
+	sel.Where = binary("AND", SqlKindAnd,
+		binary("=", SqlKindEquals, writerIdent("t", "day"), NewSqlLiteral("2024-01-01", LiteralString, nil)),
+		binary(">", SqlKindGreaterThan, writerIdent("t", "amount"), NewSqlLiteral(int64(0), LiteralInteger, nil)))
+	return sel
+}
+
+// TestDiffNoChanges 测试只有位置、注释和条件顺序不同时没有变化
+func TestDiffNoChanges(t *testing.T) {
+	old := buildDiffTestTree()
+	new := buildDiffTestTree()
+	new.Pos = &SqlParserPos{LineNumber: 3}
+	new.Comments = &SqlComments{Leading: []*SqlComment{{Text: "-- 注释"}}}
+	where := new.Where.(*SqlCall)
+	where.Operands[0], where.Operands[1] = where.Operands[1], where.Operands[0]
+	new.SelectList[0], new.SelectList[1] = new.SelectList[1], new.SelectList[0]
+
+	if changes := Diff(old, new); changes != nil {
+		t.Errorf("不应有变化:\n%s", FormatChanges(changes))
+	}
+}
+
+// TestDiff 测试各类变化
+func TestDiff(t *testing.T) {
+	binary := func(name string, kind SqlKind, l, r SqlNode) SqlNode {
+		return NewSqlCall(NewSqlOperator(name, kind, SyntaxBinary), []SqlNode{l, r}, nil)
+	}
+	old := buildDiffTestTree()
+	new := buildDiffTestTree()
+
+	new.Hints[0] = NewSqlHint("JOIN", []SqlNode{writerIdent("PSI")}, nil)
+	new.KeywordList = []string{"DISTINCT"}
+	new.SelectList = []SqlNode{
+		new.SelectList[0],
+		positioned(writerAlias(writerIdent("t", "nick"), "n"), 1, 15, 1, 26),
+		positioned(writerIdent("v", "phone"), 1, 30, 1, 37),
+	}
+	join := new.From.(*SqlJoin)
+	join.JoinType = JoinInner
+	new.From = NewSqlJoin(join, writerIdent("v"), JoinInner, binary("=", SqlKindEquals, writerIdent("t", "id"), writerIdent("v", "id")), nil)
+	new.Where = binary("AND", SqlKindAnd,
+		binary("=", SqlKindEquals, writerIdent("t", "day"), NewSqlLiteral("2024-01-02", LiteralString, nil)),
+		binary("=", SqlKindEquals, writerIdent("t", "status"), NewSqlLiteral(int64(1), LiteralInteger, nil)))
+	new.Fetch = NewSqlLiteral(int64(10), LiteralInteger, nil)
+
+	expected := "" +
+		"~ HINT hints[0]: /*+ JOIN(TEE) */ -> /*+ JOIN(PSI) */\n" +
+		"+ DISTINCT keywords: DISTINCT\n" +
+		"~ SELECT_ITEM selectList[1]: t.name AS n -> t.nick AS n [1:16] (新增访问: t.nick)\n" +
+		"+ SELECT_ITEM selectList[2]: v.phone [1:31] (新增访问: v.phone)\n" +
+		"~ JOIN_TYPE from[u].joinType: LEFT -> INNER\n" +
+		"+ TABLE from[v]: INNER JOIN v ON t.id = v.id (新增访问: v, v.id)\n" +
+		"~ PREDICATE where[0]: t.day = '2024-01-01' -> t.day = '2024-01-02'\n" +
+		"+ PREDICATE where[1]: t.status = 1 (新增访问: t.status)\n" +
+		"- PREDICATE where[1]: t.amount > 0\n" +
+		"+ LIMIT fetch: 10\n"
+	changes := Diff(old, new)
+	if got := FormatChanges(changes); got != expected {
+		t.Errorf("变化 =\n%s\n期望\n%s", got, expected)
+	}
+	if !AddsDataAccess(changes) {
+		t.Errorf("新增了表和列，应需要重新审批")
+	}
+
+	data, err := json.Marshal(changes[3])
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	expectedJSON := `{"action":"ADDED","kind":"SELECT_ITEM","path":"selectList[2]","new":"v.phone",` +
+		`"newPos":{"line":1,"column":30,"endLine":1,"endColumn":37,"startOffset":0,"endOffset":0},"newAccess":["v.phone"]}`
+	if string(data) != expectedJSON {
+		t.Errorf("JSON =\n%s\n期望\n%s", data, expectedJSON)
+	}
+}
+
+// TestDiffWithoutNewAccess 测试只收紧条件时不需要重新审批
+func TestDiffWithoutNewAccess(t *testing.T) {
+	old := buildDiffTestTree()
+	new := buildDiffTestTree()
+	new.SelectList = new.SelectList[:1]
+	new.Where = new.Where.(*SqlCall).Operands[0]
+	new.From = writerIdent("t")
+
+	changes := Diff(old, new)
+	if AddsDataAccess(changes) {
+		t.Errorf("删除列、条件和表不应需要重新审批:\n%s", FormatChanges(changes))
+	}
+	expected := "- SELECT_ITEM selectList[1]: t.name AS n\n" +
+		"- TABLE from[u]: LEFT JOIN u ON t.id = u.id\n" +
+		"- PREDICATE where[1]: t.amount > 0\n"
+	if got := FormatChanges(changes); got != expected {
+		t.Errorf("变化 =\n%s\n期望\n%s", got, expected)
+	}
+}
+
+// TestDiffSubqueryAndStatement 测试子查询递归比较和语句类型变化
+func TestDiffSubqueryAndStatement(t *testing.T) {
+	old := NewSqlSelect(nil)
+	old.SelectList = []SqlNode{writerIdent("s", "id")}
+	old.From = writerAlias(buildDiffTestTree(), "s")
+
+	new := old.Clone().(*SqlSelect)
+	inner := new.From.(*SqlCall).Operands[0].(*SqlSelect)
+	inner.SelectList = append(inner.SelectList, writerIdent("t", "ssn"))
+
+	changes := Diff(old, new)
+	if len(changes) != 1 || changes[0].Path != "from[s].selectList[2]" || changes[0].NewAccess[0] != "t.ssn" {
+		t.Errorf("变化 =\n%s", FormatChanges(changes))
+	}
+
+	explain := NewSqlExplain("", old, nil)
+	if changes := Diff(explain, NewSqlExplain("", new, nil)); len(changes) != 1 || changes[0].Path != "explain.from[s].selectList[2]" {
+		t.Errorf("EXPLAIN 的变化 =\n%s", FormatChanges(changes))
+	}
+
+	changes = Diff(old, NewSqlUse(SqlKindUse, NewSqlIdentifier([]string{"db"}, nil), nil))
+	if len(changes) != 1 || changes[0].Kind != ChangeStatement || !strings.HasPrefix(changes[0].New, "USE") {
+		t.Errorf("语句类型的变化 =\n%s", FormatChanges(changes))
+	}
+}
+
+// TestDiffParsed 测试解析结果的差异带有两侧的源码位置
+func TestDiffParsed(t *testing.T) {
+	oldResult, err := ParseSQLWithAntlr("select t.a\nfrom t\nwhere t.b > 0")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	newResult, err := ParseSQLWithAntlr("select t.a, u.c\nfrom t join u on t.id = u.id\nwhere t.b > 1")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	// 新增的表从 u 开始定位，JOIN 本身的位置包含左侧的 t
+	changes := Diff(oldResult.SqlNode, newResult.SqlNode)
+	expected := "+ SELECT_ITEM selectList[1]: u.c [1:13] (新增访问: u.c)\n" +
+		"+ TABLE from[u]: INNER JOIN u ON t.id = u.id [2:13] (新增访问: t.id, u, u.id)\n" +
+		"~ PREDICATE where[0]: t.b > 0 [3:7] -> t.b > 1 [3:7]\n"
+	if got := FormatChanges(changes); got != expected {
+		t.Errorf("变化 =\n%s\n期望\n%s", got, expected)
+	}
+}
+
+// TestDiffAliases 测试解析器形状的别名（AS 调用）按别名对应，且别名不算数据访问
+func TestDiffAliases(t *testing.T) {
+	build := func(measure string, alias string, inner ...SqlNode) *SqlSelect {
+		sub := NewSqlSelect(nil)
+		sub.SelectList = inner
+		sub.From = writerIdent("t")
+		sel := NewSqlSelect(nil)
+		sel.SelectList = []SqlNode{writerAlias(writerCall("SUM", SqlKindCall, SyntaxFunction, writerIdent("s", measure)), alias)}
+		sel.From = writerAlias(sub, "s")
+		return sel
+	}
+	old := build("x", "tot", writerIdent("t", "x"))
+
+	changes := Diff(old, build("y", "tot", writerIdent("t", "x"), writerIdent("t", "y")))
+	expected := "~ SELECT_ITEM selectList[0]: SUM(s.x) AS tot -> SUM(s.y) AS tot (新增访问: s.y)\n" +
+		"+ SELECT_ITEM from[s].selectList[1]: t.y (新增访问: t.y)\n"
+	if got := FormatChanges(changes); got != expected {
+		t.Errorf("变化 =\n%s\n期望\n%s", got, expected)
+	}
+
+	changes = Diff(old, build("x", "total", writerIdent("t", "x")))
+	if AddsDataAccess(changes) {
+		t.Errorf("只修改别名不应需要重新审批:\n%s", FormatChanges(changes))
+	}
+}
+
+// TestDiffParsedAliases 测试解析结果中带别名的查询列和子查询
+func TestDiffParsedAliases(t *testing.T) {
+	oldResult, err := ParseSQLWithAntlr("select sum(s.x) as tot from (select t.x from t) s")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	newResult, err := ParseSQLWithAntlr("select sum(s.y) as tot from (select t.x, t.y from t) s")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	expected := "~ SELECT_ITEM selectList[0]: SUM(s.x) AS tot [1:8] -> SUM(s.y) AS tot [1:8] (新增访问: s.y)\n" +
+		"+ SELECT_ITEM from[s].selectList[1]: t.y [1:42] (新增访问: t.y)\n"
+	if got := FormatChanges(Diff(oldResult.SqlNode, newResult.SqlNode)); got != expected {
+		t.Errorf("变化 =\n%s\n期望\n%s", got, expected)
+	}
+
+	renamed, err := ParseSQLWithAntlr("select sum(s.x) as total from (select t.x from t) s")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if changes := Diff(oldResult.SqlNode, renamed.SqlNode); AddsDataAccess(changes) {
+		t.Errorf("只修改别名不应需要重新审批:\n%s", FormatChanges(changes))
+	}
+}
@@ -0,0 +1,264 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// 查询指纹 - 只有常量不同的查询得到相同的指纹
+// =============================================================================
+
+// 规范化 SQL 中的占位符
+const (
+	fingerprintPlaceholder     = "?"   // 替换单个字面量
+	fingerprintListPlaceholder = "..." // 替换 IN 后由字面量组成的整个列表
+)
+
+// QueryFingerprint 查询指纹
+type QueryFingerprint struct {
+	SQL      string        // 规范化的 SQL
+	Hash     string        // SQL 的 SHA-256 十六进制表示
+	Literals []*SqlLiteral // 被替换的字面量，按规范化后语法树的前序顺序，IN 列表中的字面量依次展开
+}
+
+// Fingerprint 计算查询指纹，返回规范化的 SQL 及其哈希
+//
+// 规范化规则：
+//   - 字面量替换为 ?，IN / NOT IN 后只有字面量的列表不论长短都替换为 (...)
+//   - 标识符和别名转为小写，函数名转为大写，去掉注释，空白按 Unparse 统一
+//   - AND / OR 连接的条件按规范化后的文本排序，a = 1 AND b = 2 与 b = 2 AND a = 1 相同
+//
+// 与 NodeFingerprint 不同，指纹忽略常量和书写差异，用于按查询形态分组；
+// NodeFingerprint 区分常量和大小写，用于识别完全相同的子树。nil 返回两个空字符串
+func Fingerprint(node SqlNode) (normalizedSQL, hash string) {
+	f := FingerprintQuery(node)
+	return f.SQL, f.Hash
+}
+
+// FingerprintQuery 计算查询指纹，同时返回被替换的字面量，不修改 node
+func FingerprintQuery(node SqlNode) *QueryFingerprint {
+	if node == nil {
+		return &QueryFingerprint{}
+	}
+
+	n := &fingerprintNormalizer{replaced: make(map[*SqlLiteral][]*SqlLiteral)}
+	n.SqlShuttle = NewSqlShuttle(n)
+	// 改写在副本上原地进行，且不改变节点类型，不会返回错误
+	normalized, _ := Rewrite(node.Clone(), n)
+
+	f := &QueryFingerprint{}
+	Inspect(normalized, func(child SqlNode) bool {
+		if holder, ok := child.(interface{ base() *BaseSqlNode }); ok {
+			holder.base().Comments = nil
+		}
+		if literal, ok := child.(*SqlLiteral); ok {
+			f.Literals = append(f.Literals, n.replaced[literal]...)
+		}
+		return child != nil
+	})
+	f.SQL = Unparse(normalized)
+	sum := sha256.Sum256([]byte(f.SQL))
+	f.Hash = hex.EncodeToString(sum[:])
+	return f
+}
+
+// fingerprintNormalizer 把语法树改写为规范形式
+// 改写对象是调用方的副本，节点直接原地修改
+type fingerprintNormalizer struct {
+	*SqlShuttle
+	replaced map[*SqlLiteral][]*SqlLiteral // 占位符 -> 被替换的字面量
+}
+
+// VisitIdentifier 标识符转为小写
+func (n *fingerprintNormalizer) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
+	for i, name := range node.Names {
+		node.Names[i] = strings.ToLower(name)
+	}
+	return node, nil
+}
+
+// VisitLiteral 字面量替换为占位符，CAST 的目标类型等符号保持不变
+func (n *fingerprintNormalizer) VisitLiteral(node *SqlLiteral) (interface{}, error) {
+	if node.ValueType == LiteralSymbol {
+		return node, nil
+	}
+	return n.placeholder(fingerprintPlaceholder, node.Pos, node), nil
+}
+
+// VisitBasicCall 别名转为小写
+func (n *fingerprintNormalizer) VisitBasicCall(node *SqlBasicCall) (interface{}, error) {
+	result, err := n.SqlShuttle.VisitBasicCall(node)
+	if err != nil {
+		return nil, err
+	}
+	call := result.(*SqlBasicCall)
+	call.Alias = strings.ToLower(call.Alias)
+	return call, nil
+}
+
+// VisitCall 统一函数名大小写，合并 IN 列表，排序 AND / OR 的条件
+func (n *fingerprintNormalizer) VisitCall(node *SqlCall) (interface{}, error) {
+	result, err := n.SqlShuttle.VisitCall(node)
+	if err != nil {
+		return nil, err
+	}
+	call := result.(*SqlCall)
+	if call.Operator == nil {
+		return call, nil
+	}
+
+	switch {
+	case call.Operator.Syntax == SyntaxFunction:
+		call.Operator.Name = strings.ToUpper(call.Operator.Name)
+	case call.Operator.Kind == SqlKindIn || call.Operator.Kind == SqlKindNotIn:
+		n.collapseInList(call)
+	case call.Operator.Kind == SqlKindAnd || call.Operator.Kind == SqlKindOr:
+		return n.sortConjuncts(call), nil
+	}
+	return call, nil
+}
+
+// placeholder 创建替换 literals 的占位符
+func (n *fingerprintNormalizer) placeholder(text string, pos *SqlParserPos, literals ...*SqlLiteral) *SqlLiteral {
+	placeholder := NewSqlLiteral(text, LiteralSymbol, pos)
+	n.replaced[placeholder] = literals
+	return placeholder
+}
+
+// collapseInList 列表中只有字面量时整个替换为一个列表占位符
+func (n *fingerprintNormalizer) collapseInList(call *SqlCall) {
+	if len(call.Operands) != 2 {
+		return
+	}
+	list, ok := call.Operands[1].(*SqlNodeList)
+	if !ok || len(list.List) == 0 {
+		return
+	}
+	var literals []*SqlLiteral
+	for _, item := range list.List {
+		placeholder, ok := item.(*SqlLiteral)
+		if !ok || placeholder.Value != fingerprintPlaceholder || n.replaced[placeholder] == nil {
+			return
+		}
+		literals = append(literals, n.replaced[placeholder]...)
+		delete(n.replaced, placeholder)
+	}
+	list.List = []SqlNode{n.placeholder(fingerprintListPlaceholder, list.Pos, literals...)}
+}
+
+// sortConjuncts 展开同一操作符连接的条件，按规范化后的文本排序后重新组成左深树
+func (n *fingerprintNormalizer) sortConjuncts(call *SqlCall) SqlNode {
+	var terms []SqlNode
+	var flatten func(node SqlNode)
+	flatten = func(node SqlNode) {
+		if c, ok := node.(*SqlCall); ok && c.Operator != nil && c.Operator.Kind == call.Operator.Kind && len(c.Operands) == 2 {
+			flatten(c.Operands[0])
+			flatten(c.Operands[1])
+			return
+		}
+		terms = append(terms, node)
+	}
+	flatten(call)
+
+	keys := make(map[SqlNode]string, len(terms))
+	for _, term := range terms {
+		keys[term] = Unparse(term)
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		return keys[terms[i]] < keys[terms[j]]
+	})
+
+	result := terms[0]
+	for _, term := range terms[1:] {
+		result = NewSqlCall(call.Operator, []SqlNode{result, term}, call.Pos)
+	}
+	return result
+}
+
+// =============================================================================
+// DigestAggregator - 按指纹汇总查询
+// =============================================================================
+
+// QueryDigest 一类查询的汇总
+type QueryDigest struct {
+	Hash    string // 指纹哈希
+	SQL     string // 规范化的 SQL
+	Count   int    // 出现次数
+	Example string // 第一次出现时的原始 SQL
+}
+
+// DigestAggregator 并发安全的查询汇总器，按指纹统计各类查询的出现次数
+type DigestAggregator struct {
+	mu      sync.Mutex
+	digests map[string]*QueryDigest
+	total   int
+}
+
+// NewDigestAggregator 创建查询汇总器
+func NewDigestAggregator() *DigestAggregator {
+	return &DigestAggregator{digests: make(map[string]*QueryDigest)}
+}
+
+// Add 记录一条已解析的查询，example 为原始 SQL，返回该类查询当前的汇总
+func (a *DigestAggregator) Add(node SqlNode, example string) QueryDigest {
+	normalized, hash := Fingerprint(node)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	digest, ok := a.digests[hash]
+	if !ok {
+		digest = &QueryDigest{Hash: hash, SQL: normalized, Example: example}
+		a.digests[hash] = digest
+	}
+	digest.Count++
+	a.total++
+	return *digest
+}
+
+// AddSQL 解析并记录一条查询，解析失败时不记录并返回错误
+func (a *DigestAggregator) AddSQL(sql string) (QueryDigest, error) {
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		return QueryDigest{}, err
+	}
+	return a.Add(result.SqlNode, sql), nil
+}
+
+// Total 已记录的查询总数
+func (a *DigestAggregator) Total() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total
+}
+
+// Digests 返回所有汇总，按出现次数从多到少排序，次数相同时按规范化的 SQL 排序
+func (a *DigestAggregator) Digests() []QueryDigest {
+	a.mu.Lock()
+	digests := make([]QueryDigest, 0, len(a.digests))
+	for _, digest := range a.digests {
+		digests = append(digests, *digest)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(digests, func(i, j int) bool {
+		if digests[i].Count != digests[j].Count {
+			return digests[i].Count > digests[j].Count
+		}
+		return digests[i].SQL < digests[j].SQL
+	})
+	return digests
+}
+
+// Top 返回出现次数最多的 n 类查询，n <= 0 时返回全部
+func (a *DigestAggregator) Top(n int) []QueryDigest {
+	digests := a.Digests()
+	if n > 0 && n < len(digests) {
+		digests = digests[:n]
+	}
+	return digests
+}
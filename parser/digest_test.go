@@ -0,0 +1,165 @@
+package parser
+
+import (
+	"testing"
+)
+
+// buildDigestTestTree 构造 SELECT T.A AS X, nvl(b, 0) FROM T WHERE b = 'v' AND a IN (1, 2, 3) AND c > 1.5
+func buildDigestTestTree(inList []SqlNode, conditionsFirst bool) *SqlSelect {
+	binary := func(name string, kind SqlKind, l, r SqlNode) SqlNode {
+		return NewSqlCall(NewSqlOperator(name, kind, SyntaxBinary), []SqlNode{l, r}, nil)
+	}
+	in := NewSqlCall(NewSqlOperator("IN", SqlKindIn, SyntaxSpecial), []SqlNode{writerIdent("A"), NewSqlNodeList(inList, nil)}, nil)
+	eq := binary("=", SqlKindEquals, writerIdent("b"), NewSqlLiteral("v", LiteralString, nil))
+	gt := binary(">", SqlKindGreaterThan, writerIdent("c"), NewSqlLiteral(1.5, LiteralDecimal, nil))
+
+	sel := NewSqlSelect(nil)
+	sel.SelectList = []SqlNode{
+		writerAlias(writerIdent("T", "A"), "X"),
+		NewSqlCall(NewSqlOperator("nvl", SqlKindCall, SyntaxFunction), []SqlNode{writerIdent("b"), NewSqlLiteral(int64(0), LiteralInteger, nil)}, nil),
+	}
+	sel.From = writerIdent("T")
+	if conditionsFirst {
+		sel.Where = binary("AND", SqlKindAnd, binary("AND", SqlKindAnd, gt, in), eq)
+	} else {
+		sel.Where = binary("AND", SqlKindAnd, eq, binary("AND", SqlKindAnd, in, gt))
+	}
+	return sel
+}
+
+func digestLiterals(values ...interface{}) []SqlNode {
+	var nodes []SqlNode
+	for _, value := range values {
+		switch v := value.(type) {
+		case int64:
+			nodes = append(nodes, NewSqlLiteral(v, LiteralInteger, nil))
+		case string:
+			nodes = append(nodes, NewSqlLiteral(v, LiteralString, nil))
+		}
+	}
+	return nodes
+}
+
+// TestFingerprint 测试规范化规则
+func TestFingerprint(t *testing.T) {
+	tree := buildDigestTestTree(digestLiterals(int64(1), int64(2), int64(3)), false)
+	before := Unparse(tree)
+
+	f := FingerprintQuery(tree)
+	expected := "SELECT t.a AS x, NVL(b, ?) FROM t WHERE a IN (...) AND b = ? AND c > ?"
+	if f.SQL != expected {
+		t.Errorf("规范化的 SQL = %q, 期望 %q", f.SQL, expected)
+	}
+	if len(f.Hash) != 64 {
+		t.Errorf("哈希 = %q", f.Hash)
+	}
+	if Unparse(tree) != before {
+		t.Errorf("计算指纹修改了原树: %s", Unparse(tree))
+	}
+
+	var values []interface{}
+	for _, literal := range f.Literals {
+		values = append(values, literal.Value)
+	}
+	expectedValues := []interface{}{int64(0), int64(1), int64(2), int64(3), "v", 1.5}
+	if len(values) != len(expectedValues) {
+		t.Fatalf("字面量 = %v, 期望 %v", values, expectedValues)
+	}
+	for i := range values {
+		if values[i] != expectedValues[i] {
+			t.Errorf("字面量 = %v, 期望 %v", values, expectedValues)
+			break
+		}
+	}
+
+	// 常量、IN 列表长度、条件顺序、大小写和注释不同的查询得到相同的指纹
+	other := buildDigestTestTree(digestLiterals(int64(7)), true)
+	other.Comments = &SqlComments{Leading: []*SqlComment{{Text: "-- 注释"}}}
+	if sql, hash := Fingerprint(other); sql != f.SQL || hash != f.Hash {
+		t.Errorf("只有常量不同的查询指纹不同: %q", sql)
+	}
+
+	// 列表中有非字面量时不合并
+	mixed := buildDigestTestTree([]SqlNode{NewSqlLiteral(int64(1), LiteralInteger, nil), writerIdent("d")}, false)
+	if sql, _ := Fingerprint(mixed); sql != "SELECT t.a AS x, NVL(b, ?) FROM t WHERE a IN (?, d) AND b = ? AND c > ?" {
+		t.Errorf("规范化的 SQL = %q", sql)
+	}
+
+	// CAST 的目标类型不是常量
+	cast := NewSqlCall(NewSqlOperator("CAST", SqlKindCast, SyntaxSpecial),
+		[]SqlNode{NewSqlLiteral("1", LiteralString, nil), NewSqlLiteral("INT", LiteralSymbol, nil)}, nil)
+	if sql, _ := Fingerprint(cast); sql != "CAST(? AS INT)" {
+		t.Errorf("规范化的 SQL = %q", sql)
+	}
+
+	if sql, hash := Fingerprint(nil); sql != "" || hash != "" {
+		t.Errorf("nil 的指纹 = %q, %q", sql, hash)
+	}
+}
+
+// TestFingerprintOrConjuncts 测试 OR 的条件排序且不与 AND 混合
+func TestFingerprintOrConjuncts(t *testing.T) {
+	binary := func(name string, kind SqlKind, l, r SqlNode) SqlNode {
+		return NewSqlCall(NewSqlOperator(name, kind, SyntaxBinary), []SqlNode{l, r}, nil)
+	}
+	eq := func(column string) SqlNode {
+		return binary("=", SqlKindEquals, writerIdent(column), NewSqlLiteral(int64(1), LiteralInteger, nil))
+	}
+	a := binary("AND", SqlKindAnd, binary("OR", SqlKindOr, eq("z"), eq("y")), eq("x"))
+	b := binary("AND", SqlKindAnd, eq("x"), binary("OR", SqlKindOr, eq("y"), eq("z")))
+
+	sqlA, hashA := Fingerprint(a)
+	sqlB, hashB := Fingerprint(b)
+	if sqlA != "x = ? AND (y = ? OR z = ?)" || sqlA != sqlB || hashA != hashB {
+		t.Errorf("规范化的 SQL = %q, %q", sqlA, sqlB)
+	}
+}
+
+// TestDigestAggregator 测试按指纹汇总
+func TestDigestAggregator(t *testing.T) {
+	aggregator := NewDigestAggregator()
+	aggregator.Add(buildDigestTestTree(digestLiterals(int64(1)), false), "first")
+	aggregator.Add(buildDigestTestTree(digestLiterals(int64(2), int64(3)), true), "second")
+	aggregator.Add(writerIdent("a"), "select a")
+	digest := aggregator.Add(buildDigestTestTree(digestLiterals("x"), false), "third")
+
+	if digest.Count != 3 || digest.Example != "first" {
+		t.Errorf("汇总 = %+v", digest)
+	}
+	if aggregator.Total() != 4 {
+		t.Errorf("总数 = %d", aggregator.Total())
+	}
+
+	digests := aggregator.Digests()
+	if len(digests) != 2 || digests[0].Count != 3 || digests[1].Count != 1 || digests[1].SQL != "a" {
+		t.Errorf("汇总 = %+v", digests)
+	}
+	if top := aggregator.Top(1); len(top) != 1 || top[0].Hash != digest.Hash {
+		t.Errorf("Top(1) = %+v", top)
+	}
+}
+
+// TestDigestAggregatorSQL 测试解析并汇总 SQL 文本
+func TestDigestAggregatorSQL(t *testing.T) {
+	aggregator := NewDigestAggregator()
+	for _, sql := range []string{
+		"select a from t where id in (1, 2) and day = '2024-01-01'",
+		"SELECT A FROM T -- 注释\nWHERE day = '2024-01-02' AND id IN (3)",
+		"select a, b from t",
+	} {
+		if _, err := aggregator.AddSQL(sql); err != nil {
+			t.Fatalf("解析 %q 失败: %v", sql, err)
+		}
+	}
+	if _, err := aggregator.AddSQL("select from"); err == nil {
+		t.Errorf("解析失败时应返回错误")
+	}
+
+	digests := aggregator.Digests()
+	if len(digests) != 2 || digests[0].Count != 2 || aggregator.Total() != 3 {
+		t.Fatalf("汇总 = %+v", digests)
+	}
+	if expected := "SELECT a FROM t WHERE day = ? AND id IN (...)"; digests[0].SQL != expected {
+		t.Errorf("规范化的 SQL = %q, 期望 %q", digests[0].SQL, expected)
+	}
+}
@@ -0,0 +1,479 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// =============================================================================
+// 结构相等 - 类似 Calcite 的 SqlNode.equalsDeep
+// =============================================================================
+
+// EqualOptions 结构比较选项，零值表示严格比较（包括位置信息）
+type EqualOptions struct {
+	IgnorePositions      bool // 忽略节点的位置信息
+	IgnoreAliases        bool // 忽略别名：AS 调用只比较被命名的表达式，SqlBasicCall 不比较 Alias
+	IgnoreIdentifierCase bool // 标识符、别名和函数名不区分大小写，反引号括起的部分仍区分大小写
+}
+
+// Equal 按结构比较两棵语法树
+// 与比较 ToString() 不同，括号结构、DISTINCT 等关键字、字面量类型都参与比较。
+// 列表为 nil 与为空视为相等。与 Hash 一致：Equal(a, b, opts) 为 true 时 Hash(a, opts) == Hash(b, opts)
+func Equal(a, b SqlNode, opts EqualOptions) bool {
+	c := nodeComparer{opts: opts}
+	return c.equal(a, b)
+}
+
+// nodeComparer 结构比较器
+type nodeComparer struct {
+	opts EqualOptions
+}
+
+// equal 比较两个可能为 nil 的节点
+func (c nodeComparer) equal(a, b SqlNode) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.GetKind() != b.GetKind() || !c.posEqual(a.GetPos(), b.GetPos()) {
+		return false
+	}
+
+	switch x := a.(type) {
+	case *SqlIdentifier:
+		y, ok := b.(*SqlIdentifier)
+		if !ok || len(x.Names) != len(y.Names) {
+			return false
+		}
+		for i := range x.Names {
+			if !c.nameEqual(x.Names[i], y.Names[i]) {
+				return false
+			}
+		}
+		return true
+	case *SqlLiteral:
+		y, ok := b.(*SqlLiteral)
+		return ok && x.ValueType == y.ValueType && x.TypeName == y.TypeName && reflect.DeepEqual(x.Value, y.Value)
+	case *SqlCall:
+		y, ok := b.(*SqlCall)
+		if !ok || !c.operatorEqual(x.Operator, y.Operator) {
+			return false
+		}
+		return c.listEqual(c.callOperands(x), c.callOperands(y))
+	case *SqlSelect:
+		y, ok := b.(*SqlSelect)
+		if !ok || len(x.Hints) != len(y.Hints) || !slices.Equal(x.KeywordList, y.KeywordList) {
+			return false
+		}
+		for i := range x.Hints {
+			if !c.equal(nodeOrNil(x.Hints[i]), nodeOrNil(y.Hints[i])) {
+				return false
+			}
+		}
+		return c.listEqual(x.SelectList, y.SelectList) &&
+			c.equal(x.From, y.From) &&
+			c.equal(x.Where, y.Where) &&
+			c.listEqual(x.GroupBy, y.GroupBy) &&
+			c.equal(x.Having, y.Having) &&
+			c.listEqual(x.WindowDecls, y.WindowDecls) &&
+			c.listEqual(x.OrderBy, y.OrderBy) &&
+			c.equal(x.Offset, y.Offset) &&
+			c.equal(x.Fetch, y.Fetch)
+	case *SqlJoin:
+		y, ok := b.(*SqlJoin)
+		return ok && x.JoinType == y.JoinType &&
+			c.equal(x.Left, y.Left) &&
+			c.equal(x.Right, y.Right) &&
+			c.equal(x.Condition, y.Condition) &&
+			c.listEqual(x.Using, y.Using)
+	case *SqlBasicCall:
+		y, ok := b.(*SqlBasicCall)
+		if !ok || !c.equal(x.Operand, y.Operand) {
+			return false
+		}
+		return c.opts.IgnoreAliases || c.nameEqual(x.Alias, y.Alias)
+	case *SqlNodeList:
+		y, ok := b.(*SqlNodeList)
+		return ok && c.listEqual(x.List, y.List)
+	case *SqlHint:
+		y, ok := b.(*SqlHint)
+		return ok && x.Name == y.Name && c.listEqual(x.Parameters, y.Parameters)
+	case *SqlLambda:
+		y, ok := b.(*SqlLambda)
+		if !ok || len(x.Parameters) != len(y.Parameters) {
+			return false
+		}
+		for i := range x.Parameters {
+			if !c.identifierEqual(x.Parameters[i], y.Parameters[i]) {
+				return false
+			}
+		}
+		return c.equal(x.Body, y.Body)
+	case *SqlTableRef:
+		y, ok := b.(*SqlTableRef)
+		return ok && c.identifierEqual(x.Name, y.Name) &&
+			c.temporalEqual(x.Temporal, y.Temporal) &&
+			c.sampleEqual(x.Sample, y.Sample)
+	case *SqlExplain:
+		y, ok := b.(*SqlExplain)
+		return ok && x.Mode == y.Mode && c.equal(x.Statement, y.Statement)
+	case *SqlDescribe:
+		y, ok := b.(*SqlDescribe)
+		return ok && x.Option == y.Option &&
+			c.identifierEqual(x.Table, y.Table) &&
+			c.identifierEqual(x.Column, y.Column) &&
+			c.equal(x.Query, y.Query)
+	case *SqlShow:
+		y, ok := b.(*SqlShow)
+		return ok && x.Target == y.Target && x.Pattern == y.Pattern &&
+			c.identifierEqual(x.Object, y.Object) &&
+			c.identifierEqual(x.Namespace, y.Namespace)
+	case *SqlUse:
+		y, ok := b.(*SqlUse)
+		return ok && x.NamespaceType == y.NamespaceType && c.identifierEqual(x.Namespace, y.Namespace)
+	case *SqlErrorNode:
+		y, ok := b.(*SqlErrorNode)
+		return ok && x.Text == y.Text
+	default:
+		// 未知节点类型退化为逐字段比较，位置信息等选项不生效
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// listEqual 逐个比较节点列表
+func (c nodeComparer) listEqual(a, b []SqlNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !c.equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// identifierEqual 比较可能为 nil 的标识符
+func (c nodeComparer) identifierEqual(a, b *SqlIdentifier) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return c.equal(a, b)
+}
+
+// nameEqual 比较标识符的一个部分或别名
+func (c nodeComparer) nameEqual(a, b string) bool {
+	return c.foldName(a) == c.foldName(b)
+}
+
+// foldName 按选项规范化名字的大小写，Equal 和 Hash 共用以保证一致
+func (c nodeComparer) foldName(name string) string {
+	if !c.opts.IgnoreIdentifierCase || strings.HasPrefix(name, "`") {
+		return name
+	}
+	return strings.ToUpper(name)
+}
+
+// operatorEqual 比较操作符，优先级由操作符决定，不参与比较
+func (c nodeComparer) operatorEqual(a, b *SqlOperator) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return c.nameEqual(a.Name, b.Name) && a.Kind == b.Kind && a.Syntax == b.Syntax
+}
+
+// callOperands 返回参与比较的操作数，忽略别名时 AS 调用只保留被命名的表达式
+func (c nodeComparer) callOperands(call *SqlCall) []SqlNode {
+	if c.opts.IgnoreAliases && call.Operator != nil && call.Operator.Kind == SqlKindAs && len(call.Operands) > 1 {
+		return call.Operands[:1]
+	}
+	return call.Operands
+}
+
+// posEqual 比较位置信息
+func (c nodeComparer) posEqual(a, b *SqlParserPos) bool {
+	if c.opts.IgnorePositions {
+		return true
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// temporalEqual 比较时间旅行子句
+func (c nodeComparer) temporalEqual(a, b *SqlTemporalSpec) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Type == b.Type && c.equal(a.Value, b.Value)
+}
+
+// sampleEqual 比较采样子句
+func (c nodeComparer) sampleEqual(a, b *SqlSampleSpec) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if (a.Seed == nil) != (b.Seed == nil) || (a.Seed != nil && *a.Seed != *b.Seed) {
+		return false
+	}
+	return a.Method == b.Method && a.Numerator == b.Numerator && a.Denominator == b.Denominator &&
+		c.equal(a.Value, b.Value) && c.equal(a.BucketOn, b.BucketOn)
+}
+
+// nodeOrNil 把 nil 指针转换为 nil 接口，避免 typed nil
+func nodeOrNil(hint *SqlHint) SqlNode {
+	if hint == nil {
+		return nil
+	}
+	return hint
+}
+
+// =============================================================================
+// 哈希与指纹
+// =============================================================================
+
+// Hash 计算与 Equal 一致的结构哈希：Equal(a, b, opts) 为 true 时两者的哈希相同
+// 哈希值只在当前进程内用于分桶，不保证跨版本稳定；需要持久化时使用 NodeFingerprint
+func Hash(node SqlNode, opts EqualOptions) uint64 {
+	h := fnv.New64a()
+	w := &nodeWriter{w: h, comparer: nodeComparer{opts: opts}}
+	w.child = w.writeFields
+	w.writeNode(node)
+	return h.Sum64()
+}
+
+// NodeFingerprint 返回子树的稳定指纹（SHA-256 的十六进制表示）
+// 指纹只由结构决定，忽略位置信息，区分别名和大小写；相同结构的子树在不同语句、
+// 不同进程中得到相同的指纹，可用于查询去重和持久化。nil 返回空字符串
+func NodeFingerprint(node SqlNode) string {
+	if node == nil {
+		return ""
+	}
+	f := newFingerprinter()
+	return hex.EncodeToString(f.digest(node))
+}
+
+// SubtreeFingerprints 一次遍历计算 node 及其所有子树的指纹，键为子树根节点
+// 指纹与 NodeFingerprint 相同，适合在改写中识别和缓存公共子表达式
+func SubtreeFingerprints(node SqlNode) map[SqlNode]string {
+	fingerprints := make(map[SqlNode]string)
+	if node == nil {
+		return fingerprints
+	}
+	f := newFingerprinter()
+	f.digest(node)
+	for subtree, digest := range f.digests {
+		fingerprints[subtree] = hex.EncodeToString(digest)
+	}
+	return fingerprints
+}
+
+// fingerprinter 自底向上计算指纹：节点指纹由自身字段和子节点指纹计算
+type fingerprinter struct {
+	digests map[SqlNode][]byte
+}
+
+func newFingerprinter() *fingerprinter {
+	return &fingerprinter{digests: make(map[SqlNode][]byte)}
+}
+
+// digest 计算并缓存节点指纹
+func (f *fingerprinter) digest(node SqlNode) []byte {
+	if digest, ok := f.digests[node]; ok {
+		return digest
+	}
+	h := sha256.New()
+	w := &nodeWriter{w: h, comparer: nodeComparer{opts: EqualOptions{IgnorePositions: true}}}
+	w.child = func(child SqlNode) {
+		h.Write(f.digest(child))
+	}
+	w.writeFields(node)
+	digest := h.Sum(nil)
+	f.digests[node] = digest
+	return digest
+}
+
+// nodeWriter 把节点按无歧义的规范形式写入哈希
+// 字符串带长度前缀，列表带元素个数，nil 与非 nil 节点用标记区分；
+// 参与哈希的内容与 nodeComparer 比较的内容保持一致
+type nodeWriter struct {
+	w        hash.Hash
+	comparer nodeComparer
+	child    func(SqlNode) // 写入非 nil 子节点：Hash 直接递归，指纹写入子节点指纹
+	buf      [binary.MaxVarintLen64]byte
+}
+
+func (w *nodeWriter) writeInt(v int64) {
+	n := binary.PutVarint(w.buf[:], v)
+	w.w.Write(w.buf[:n])
+}
+
+func (w *nodeWriter) writeString(s string) {
+	w.writeInt(int64(len(s)))
+	w.w.Write([]byte(s))
+}
+
+func (w *nodeWriter) writeBool(b bool) {
+	if b {
+		w.writeInt(1)
+	} else {
+		w.writeInt(0)
+	}
+}
+
+// writeNode 写入可能为 nil 的节点
+func (w *nodeWriter) writeNode(node SqlNode) {
+	w.writeBool(node != nil)
+	if node != nil {
+		w.child(node)
+	}
+}
+
+func (w *nodeWriter) writeNodes(nodes []SqlNode) {
+	w.writeInt(int64(len(nodes)))
+	for _, node := range nodes {
+		w.writeNode(node)
+	}
+}
+
+// writeIdentifier 写入可能为 nil 的标识符
+func (w *nodeWriter) writeIdentifier(identifier *SqlIdentifier) {
+	if identifier == nil {
+		w.writeNode(nil)
+		return
+	}
+	w.writeNode(identifier)
+}
+
+func (w *nodeWriter) writeName(name string) {
+	w.writeString(w.comparer.foldName(name))
+}
+
+func (w *nodeWriter) writePos(pos *SqlParserPos) {
+	if w.comparer.opts.IgnorePositions {
+		return
+	}
+	w.writeBool(pos != nil)
+	if pos != nil {
+		w.writeInt(int64(pos.LineNumber))
+		w.writeInt(int64(pos.ColumnNumber))
+		w.writeInt(int64(pos.EndLine))
+		w.writeInt(int64(pos.EndColumn))
+		w.writeInt(int64(pos.StartOffset))
+		w.writeInt(int64(pos.EndOffset))
+		w.writeBool(pos.Synthetic)
+	}
+}
+
+// writeFields 写入节点类型、公共字段和各类型特有的字段
+func (w *nodeWriter) writeFields(node SqlNode) {
+	w.writeString(fmt.Sprintf("%T", node))
+	w.writeString(string(node.GetKind()))
+	w.writePos(node.GetPos())
+
+	switch n := node.(type) {
+	case *SqlIdentifier:
+		w.writeInt(int64(len(n.Names)))
+		for _, name := range n.Names {
+			w.writeName(name)
+		}
+	case *SqlLiteral:
+		w.writeInt(int64(n.ValueType))
+		w.writeString(n.TypeName)
+		w.writeString(fmt.Sprintf("%T:%v", n.Value, n.Value))
+	case *SqlCall:
+		w.writeBool(n.Operator != nil)
+		if n.Operator != nil {
+			w.writeName(n.Operator.Name)
+			w.writeString(string(n.Operator.Kind))
+			w.writeInt(int64(n.Operator.Syntax))
+		}
+		w.writeNodes(w.comparer.callOperands(n))
+	case *SqlSelect:
+		w.writeInt(int64(len(n.Hints)))
+		for _, hint := range n.Hints {
+			w.writeNode(nodeOrNil(hint))
+		}
+		w.writeInt(int64(len(n.KeywordList)))
+		for _, keyword := range n.KeywordList {
+			w.writeString(keyword)
+		}
+		w.writeNodes(n.SelectList)
+		w.writeNode(n.From)
+		w.writeNode(n.Where)
+		w.writeNodes(n.GroupBy)
+		w.writeNode(n.Having)
+		w.writeNodes(n.WindowDecls)
+		w.writeNodes(n.OrderBy)
+		w.writeNode(n.Offset)
+		w.writeNode(n.Fetch)
+	case *SqlJoin:
+		w.writeString(string(n.JoinType))
+		w.writeNode(n.Left)
+		w.writeNode(n.Right)
+		w.writeNode(n.Condition)
+		w.writeNodes(n.Using)
+	case *SqlBasicCall:
+		w.writeNode(n.Operand)
+		if !w.comparer.opts.IgnoreAliases {
+			w.writeName(n.Alias)
+		}
+	case *SqlNodeList:
+		w.writeNodes(n.List)
+	case *SqlHint:
+		w.writeString(n.Name)
+		w.writeNodes(n.Parameters)
+	case *SqlLambda:
+		w.writeInt(int64(len(n.Parameters)))
+		for _, param := range n.Parameters {
+			w.writeIdentifier(param)
+		}
+		w.writeNode(n.Body)
+	case *SqlTableRef:
+		w.writeIdentifier(n.Name)
+		w.writeBool(n.Temporal != nil)
+		if n.Temporal != nil {
+			w.writeString(string(n.Temporal.Type))
+			w.writeNode(n.Temporal.Value)
+		}
+		w.writeBool(n.Sample != nil)
+		if n.Sample != nil {
+			w.writeString(string(n.Sample.Method))
+			w.writeNode(n.Sample.Value)
+			w.writeInt(n.Sample.Numerator)
+			w.writeInt(n.Sample.Denominator)
+			w.writeNode(n.Sample.BucketOn)
+			w.writeBool(n.Sample.Seed != nil)
+			if n.Sample.Seed != nil {
+				w.writeInt(*n.Sample.Seed)
+			}
+		}
+	case *SqlExplain:
+		w.writeString(n.Mode)
+		w.writeNode(n.Statement)
+	case *SqlDescribe:
+		w.writeString(n.Option)
+		w.writeIdentifier(n.Table)
+		w.writeIdentifier(n.Column)
+		w.writeNode(n.Query)
+	case *SqlShow:
+		w.writeString(n.Target)
+		w.writeString(n.Pattern)
+		w.writeIdentifier(n.Object)
+		w.writeIdentifier(n.Namespace)
+	case *SqlUse:
+		w.writeString(n.NamespaceType)
+		w.writeIdentifier(n.Namespace)
+	case *SqlErrorNode:
+		w.writeString(n.Text)
+	default:
+		w.writeString(node.ToString())
+	}
+}
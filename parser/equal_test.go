@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestEqualClone 测试克隆与原树结构相等且哈希相同
+func TestEqualClone(t *testing.T) {
+	tree := buildCloneTestTree()
+	clone := tree.Clone()
+
+	if !Equal(tree, clone, EqualOptions{}) {
+		t.Fatalf("克隆应与原树相等")
+	}
+	if Hash(tree, EqualOptions{}) != Hash(clone, EqualOptions{}) {
+		t.Errorf("相等的树哈希应相同")
+	}
+	if NodeFingerprint(tree) != NodeFingerprint(clone) {
+		t.Errorf("相等的树指纹应相同")
+	}
+}
+
+// TestEqualDetectsStructure 测试 ToString 相同但结构不同的树不相等
+func TestEqualDetectsStructure(t *testing.T) {
+	ident := func(name string) SqlNode { return NewSqlIdentifier([]string{name}, nil) }
+	and := func(l, r SqlNode) SqlNode {
+		return NewSqlCall(&SqlOperator{Name: "AND", Kind: SqlKindAnd, Syntax: SyntaxBinary}, []SqlNode{l, r}, nil)
+	}
+	not := func(operand SqlNode) SqlNode {
+		return NewSqlCall(&SqlOperator{Name: "NOT", Kind: SqlKindNot, Syntax: SyntaxPrefix}, []SqlNode{operand}, nil)
+	}
+
+	// NOT (a AND b) 与 (NOT a) AND b
+	left := not(and(ident("a"), ident("b")))
+	right := and(not(ident("a")), ident("b"))
+	if Equal(left, right, EqualOptions{}) {
+		t.Errorf("%s 与 %s 结构不同, 不应相等", left.ToString(), right.ToString())
+	}
+
+	// 字面量类型不同
+	if Equal(NewSqlLiteral("1", LiteralString, nil), NewSqlLiteral(int64(1), LiteralInteger, nil), EqualOptions{}) {
+		t.Errorf("'1' 与 1 不应相等")
+	}
+}
+
+// TestEqualOptions 测试各个忽略选项以及哈希与之一致
+func TestEqualOptions(t *testing.T) {
+	as := func(expr SqlNode, alias string) SqlNode {
+		return NewSqlCall(&SqlOperator{Name: "AS", Kind: SqlKindAs, Syntax: SyntaxSpecial},
+			[]SqlNode{expr, NewSqlIdentifier([]string{alias}, nil)}, nil)
+	}
+	pos := &SqlParserPos{LineNumber: 1, ColumnNumber: 8, EndLine: 1, EndColumn: 9}
+
+	tests := []struct {
+		name string
+		a, b SqlNode
+		opts EqualOptions
+	}{
+		{
+			name: "位置",
+			a:    NewSqlIdentifier([]string{"t", "id"}, pos),
+			b:    NewSqlIdentifier([]string{"t", "id"}, nil),
+			opts: EqualOptions{IgnorePositions: true},
+		},
+		{
+			name: "AS 别名",
+			a:    as(NewSqlIdentifier([]string{"id"}, nil), "x"),
+			b:    as(NewSqlIdentifier([]string{"id"}, nil), "y"),
+			opts: EqualOptions{IgnoreAliases: true},
+		},
+		{
+			name: "SqlBasicCall 别名",
+			a:    NewSqlBasicCall(NewSqlIdentifier([]string{"users"}, nil), "u", nil),
+			b:    NewSqlBasicCall(NewSqlIdentifier([]string{"users"}, nil), "usr", nil),
+			opts: EqualOptions{IgnoreAliases: true},
+		},
+		{
+			name: "标识符大小写",
+			a:    NewSqlIdentifier([]string{"Db", "Users"}, nil),
+			b:    NewSqlIdentifier([]string{"DB", "users"}, nil),
+			opts: EqualOptions{IgnoreIdentifierCase: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if Equal(tt.a, tt.b, EqualOptions{}) {
+				t.Errorf("严格比较时不应相等")
+			}
+			if !Equal(tt.a, tt.b, tt.opts) {
+				t.Fatalf("%+v 下应相等", tt.opts)
+			}
+			if Hash(tt.a, tt.opts) != Hash(tt.b, tt.opts) {
+				t.Errorf("%+v 下哈希应相同", tt.opts)
+			}
+		})
+	}
+
+	// 反引号括起的标识符仍区分大小写
+	quoted := NewSqlIdentifier([]string{"`Users`"}, nil)
+	if Equal(quoted, NewSqlIdentifier([]string{"`users`"}, nil), EqualOptions{IgnoreIdentifierCase: true}) {
+		t.Errorf("反引号标识符不应忽略大小写")
+	}
+}
+
+// TestSubtreeFingerprints 测试公共子表达式得到相同指纹，且与位置无关
+func TestSubtreeFingerprints(t *testing.T) {
+	tree := buildCloneTestTree()
+	fingerprints := SubtreeFingerprints(tree)
+
+	if fingerprints[tree] != NodeFingerprint(tree) {
+		t.Errorf("根节点指纹应与 NodeFingerprint 相同")
+	}
+
+	// GROUP BY 与 ORDER BY 中的 a.x 位置不同，结构相同
+	groupBy, orderBy := tree.GroupBy[0], tree.OrderBy[0]
+	if fingerprints[groupBy] == "" || fingerprints[groupBy] != fingerprints[orderBy] {
+		t.Errorf("相同结构的子树指纹应相同: %q vs %q", fingerprints[groupBy], fingerprints[orderBy])
+	}
+	if fingerprints[tree.Where] == fingerprints[tree.Having] {
+		t.Errorf("不同结构的子树指纹不应相同")
+	}
+
+	if got := NodeFingerprint(nil); got != "" {
+		t.Errorf("nil 的指纹应为空, 实际为 %q", got)
+	}
+}
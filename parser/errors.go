@@ -0,0 +1,261 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+)
+
+// =============================================================================
+// ParseError - 结构化解析错误
+// =============================================================================
+
+// 诊断错误码
+const (
+	ErrCodeSyntax              = "SYNTAX_ERROR"            // 其他语法错误
+	ErrCodeMismatchedInput     = "MISMATCHED_INPUT"        // 输入与期望的 token 不匹配
+	ErrCodeNoViableAlternative = "NO_VIABLE_ALTERNATIVE"   // 没有可用的语法分支
+	ErrCodeFailedPredicate     = "FAILED_PREDICATE"        // 语义谓词校验失败
+	ErrCodeMissingToken        = "MISSING_TOKEN"           // 缺少 token
+	ErrCodeExtraneousInput     = "EXTRANEOUS_INPUT"        // 多余的输入
+	ErrCodeTokenRecognition    = "TOKEN_RECOGNITION_ERROR" // 词法错误，无法识别的字符
+)
+
+// ParseError SQL 解析错误，可通过 errors.As 获取
+//
+//	var parseErr *parser.ParseError
+//	if errors.As(err, &parseErr) {
+//		for _, d := range parseErr.Diagnostics { ... }
+//	}
+type ParseError struct {
+	SQL         string        // 被解析的 SQL（已去除首尾空白），偏移量相对于它计算
+	Diagnostics []*Diagnostic // 所有诊断信息，按出现顺序排列
+}
+
+func (e *ParseError) Error() string {
+	messages := make([]string, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		messages[i] = d.String()
+	}
+	return fmt.Sprintf("解析失败: %s", strings.Join(messages, "; "))
+}
+
+// Diagnostic 单条诊断信息
+type Diagnostic struct {
+	Code           string   // 错误码，见 ErrCode* 常量
+	Message        string   // ANTLR 原始错误信息
+	Line           int      // 行号，从 1 开始
+	Column         int      // 列号（字符），从 0 开始
+	StartOffset    int      // 出错 token 起始字节偏移
+	EndOffset      int      // 出错 token 结束字节偏移（不含）
+	OffendingToken string   // 出错 token 文本，EOF 时为 "<EOF>"
+	ExpectedTokens []string // 此处期望的 token
+}
+
+// String 返回与旧版 Errors 相同格式的错误信息
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("line %d:%d %s", d.Line, d.Column, d.Message)
+}
+
+// newDiagnostic 根据 ANTLR 错误回调构建诊断信息
+func newDiagnostic(sql string, recognizer antlr4.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr4.RecognitionException) *Diagnostic {
+	d := &Diagnostic{
+		Code:    diagnosticCode(msg, e),
+		Message: msg,
+		Line:    line,
+		Column:  column,
+	}
+
+	if token, ok := offendingSymbol.(antlr4.Token); ok && token != nil {
+		if token.GetTokenType() == antlr4.TokenEOF {
+			d.OffendingToken = "<EOF>"
+			d.StartOffset = len(sql)
+			d.EndOffset = len(sql)
+		} else {
+			d.OffendingToken = token.GetText()
+			d.StartOffset = runeToByteOffset(sql, token.GetStart())
+			d.EndOffset = runeToByteOffset(sql, token.GetStop()+1)
+		}
+	} else {
+		// 词法错误没有 token，按行列定位到单个字符
+		d.StartOffset = lineColumnToByteOffset(sql, line, column)
+		d.EndOffset = d.StartOffset
+		if d.EndOffset < len(sql) {
+			d.EndOffset += len(string([]rune(sql[d.StartOffset:])[0]))
+			d.OffendingToken = sql[d.StartOffset:d.EndOffset]
+		}
+	}
+
+	if parser, ok := recognizer.(antlr4.Parser); ok {
+		d.ExpectedTokens = expectedTokenNames(parser)
+	}
+
+	return d
+}
+
+// diagnosticCode 根据异常类型和信息确定错误码
+func diagnosticCode(msg string, e antlr4.RecognitionException) string {
+	switch e.(type) {
+	case *antlr4.InputMisMatchException:
+		return ErrCodeMismatchedInput
+	case *antlr4.NoViableAltException:
+		return ErrCodeNoViableAlternative
+	case *antlr4.FailedPredicateException:
+		return ErrCodeFailedPredicate
+	case *antlr4.LexerNoViableAltException:
+		return ErrCodeTokenRecognition
+	}
+
+	// 单 token 补全/删除时没有异常对象，只能根据信息判断
+	switch {
+	case strings.HasPrefix(msg, "missing "):
+		return ErrCodeMissingToken
+	case strings.HasPrefix(msg, "extraneous input"):
+		return ErrCodeExtraneousInput
+	case strings.HasPrefix(msg, "token recognition error"):
+		return ErrCodeTokenRecognition
+	}
+	return ErrCodeSyntax
+}
+
+// expectedTokenNames 返回解析器当前状态下期望的 token 名称
+func expectedTokenNames(parser antlr4.Parser) (names []string) {
+	defer func() {
+		// 部分状态下 ATN 无法计算期望集合，忽略即可
+		if r := recover(); r != nil {
+			names = nil
+		}
+	}()
+
+	expected := parser.GetExpectedTokens()
+	if expected == nil {
+		return nil
+	}
+
+	literalNames := parser.GetLiteralNames()
+	symbolicNames := parser.GetSymbolicNames()
+	for _, interval := range expected.GetIntervals() {
+		for tokenType := interval.Start; tokenType < interval.Stop; tokenType++ {
+			switch {
+			case tokenType == antlr4.TokenEOF:
+				names = append(names, "<EOF>")
+			case tokenType < len(literalNames) && literalNames[tokenType] != "":
+				names = append(names, literalNames[tokenType])
+			case tokenType < len(symbolicNames) && symbolicNames[tokenType] != "":
+				names = append(names, symbolicNames[tokenType])
+			}
+		}
+	}
+	return names
+}
+
+// runeToByteOffset 将字符下标转换为字节偏移
+func runeToByteOffset(s string, runeIndex int) int {
+	if runeIndex <= 0 {
+		return 0
+	}
+	count := 0
+	for offset := range s {
+		if count == runeIndex {
+			return offset
+		}
+		count++
+	}
+	return len(s)
+}
+
+// lineColumnToByteOffset 将行号（从 1 开始）和列号（字符，从 0 开始）转换为字节偏移
+func lineColumnToByteOffset(s string, line, column int) int {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := strings.IndexByte(s[offset:], '\n')
+		if next < 0 {
+			return len(s)
+		}
+		offset += next + 1
+	}
+	return offset + runeToByteOffset(s[offset:], column)
+}
+
+// =============================================================================
+// UnsupportedFeatureError - 不支持的语法结构
+// =============================================================================
+
+// UnsupportedFeatureError 表示语法合法但解析器尚不支持的结构
+// 出现该错误时，构建出的 SqlNode 树是不完整的
+type UnsupportedFeatureError struct {
+	Rule    string        // 语法规则（或带标签的分支）名称，如 setOperation
+	Message string        // 错误描述
+	Text    string        // 对应的原始 SQL 片段
+	Pos     *SqlParserPos // 源码位置范围
+}
+
+func (e *UnsupportedFeatureError) Error() string {
+	if e.Pos == nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Rule)
+	}
+	return fmt.Sprintf("line %d:%d %s: %s (%s)", e.Pos.LineNumber, e.Pos.ColumnNumber, e.Message, e.Rule, e.Text)
+}
+
+// ruleNameOf 根据解析树节点类型获取语法规则名称
+// 如 *antlr.SetOperationContext -> setOperation
+func ruleNameOf(ctx interface{}) string {
+	name := fmt.Sprintf("%T", ctx)
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "Context")
+	if name == "" {
+		return ""
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// =============================================================================
+// LimitExceededError - 超过资源限制
+// =============================================================================
+
+// LimitKind 资源限制类型
+type LimitKind string
+
+const (
+	LimitBytes     LimitKind = "bytes"     // 输入字节数，见 ParseOptions.MaxInputLength
+	LimitTokens    LimitKind = "tokens"    // token 数，见 ParseOptions.MaxTokens
+	LimitDepth     LimitKind = "depth"     // 括号嵌套深度，见 ParseOptions.MaxNestingDepth
+	LimitRecursion LimitKind = "recursion" // 构建 SqlNode 时的递归深度，见 ParseOptions.MaxRecursionDepth
+	LimitNodes     LimitKind = "nodes"     // SqlNode 节点数，见 ParseOptions.MaxNodes
+)
+
+// LimitExceededError 输入超过 ParseOptions 中配置的资源限制
+// 超过限制后立即停止计数，Actual 可能小于真实值
+type LimitExceededError struct {
+	Limit  LimitKind // 超过的限制类型
+	Max    int       // 配置的上限
+	Actual int       // 停止时的计数
+	Line   int       // 超限位置的行号，从 1 开始，未知时为 0
+	Column int       // 超限位置的列号（字符），从 0 开始
+}
+
+func (e *LimitExceededError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("超过解析限制 %s: %d > %d", e.Limit, e.Actual, e.Max)
+	}
+	return fmt.Sprintf("line %d:%d 超过解析限制 %s: %d > %d", e.Line, e.Column, e.Limit, e.Actual, e.Max)
+}
+
+// =============================================================================
+// MalformedHintWarning - 疑似写错的 hint
+// =============================================================================
+
+// MalformedHintWarning 普通注释看起来像写错的 hint，如 /* + JOIN(TEE) */
+// hint 必须以 /*+ 开头，这类注释按普通注释保留，不会生效，只记录在 SQLParserResult.Warnings 中
+type MalformedHintWarning struct {
+	Text string        // 注释原文
+	Pos  *SqlParserPos // 注释位置
+}
+
+func (e *MalformedHintWarning) Error() string {
+	return fmt.Sprintf("line %d:%d 注释 %s 看起来像 hint，但 hint 必须以 /*+ 开头，将作为普通注释处理",
+		e.Pos.LineNumber, e.Pos.ColumnNumber, e.Text)
+}
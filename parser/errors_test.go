@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParseErrorDiagnostics 测试解析错误返回结构化的诊断信息
+func TestParseErrorDiagnostics(t *testing.T) {
+	sql := "select '中文' as c from events where events.a = )"
+
+	result, err := ParseSQLWithAntlr(sql)
+	if err == nil {
+		t.Fatal("期望解析失败")
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("期望 *ParseError，实际得到: %T", err)
+	}
+	if len(parseErr.Diagnostics) == 0 {
+		t.Fatal("期望至少一条诊断信息")
+	}
+	if len(result.Diagnostics) != len(result.Errors) {
+		t.Errorf("Diagnostics 与 Errors 数量不一致: %d != %d", len(result.Diagnostics), len(result.Errors))
+	}
+
+	d := parseErr.Diagnostics[0]
+	if d.Line != 1 {
+		t.Errorf("期望行号 1，实际得到: %d", d.Line)
+	}
+	if d.OffendingToken != ")" {
+		t.Errorf("期望出错 token 为 ')'，实际得到: %q", d.OffendingToken)
+	}
+	wantOffset := strings.Index(sql, ")")
+	if d.StartOffset != wantOffset || d.EndOffset != wantOffset+1 {
+		t.Errorf("期望字节偏移 [%d, %d)，实际得到: [%d, %d)", wantOffset, wantOffset+1, d.StartOffset, d.EndOffset)
+	}
+	if d.Column != len([]rune(sql[:wantOffset])) {
+		t.Errorf("期望列号 %d，实际得到: %d", len([]rune(sql[:wantOffset])), d.Column)
+	}
+	if d.Code == "" {
+		t.Error("期望错误码不为空")
+	}
+	if len(d.ExpectedTokens) == 0 {
+		t.Error("期望包含 expected tokens")
+	}
+	if !strings.HasPrefix(err.Error(), "解析失败: line 1:") {
+		t.Errorf("错误信息格式不符合预期: %s", err.Error())
+	}
+}
+
+// TestParseErrorAtEOF 测试输入提前结束时的诊断信息
+func TestParseErrorAtEOF(t *testing.T) {
+	sql := "select events.a from events where"
+
+	_, err := ParseSQLWithAntlr(sql)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("期望 *ParseError，实际得到: %v", err)
+	}
+
+	d := parseErr.Diagnostics[0]
+	if d.OffendingToken != "<EOF>" {
+		t.Errorf("期望出错 token 为 <EOF>，实际得到: %q", d.OffendingToken)
+	}
+	if d.StartOffset != len(sql) {
+		t.Errorf("期望字节偏移 %d，实际得到: %d", len(sql), d.StartOffset)
+	}
+}
+
+// TestByteOffsetConversion 测试字符下标到字节偏移的转换
+func TestByteOffsetConversion(t *testing.T) {
+	s := "ab中c\n中d"
+
+	if got := runeToByteOffset(s, 3); got != 5 {
+		t.Errorf("runeToByteOffset(3) 期望 5，实际得到: %d", got)
+	}
+	if got := runeToByteOffset(s, 100); got != len(s) {
+		t.Errorf("runeToByteOffset 越界时期望 %d，实际得到: %d", len(s), got)
+	}
+	if got := lineColumnToByteOffset(s, 2, 1); got != 10 {
+		t.Errorf("lineColumnToByteOffset(2, 1) 期望 10，实际得到: %d", got)
+	}
+}
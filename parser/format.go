@@ -0,0 +1,243 @@
+package parser
+
+import (
+	"strings"
+)
+
+// =============================================================================
+// 格式化 - 基于 SqlWriter 的多行输出
+// =============================================================================
+
+// KeywordCase 关键字大小写
+type KeywordCase int
+
+const (
+	KeywordUpper KeywordCase = iota // SELECT、FROM
+	KeywordLower                    // select、from
+)
+
+// BreakRule 换行规则
+type BreakRule int
+
+const (
+	BreakIfLong BreakRule = iota // 一行放不下时换行
+	BreakAlways                  // 总是换行
+	BreakNever                   // 从不换行
+)
+
+// FormatOptions 格式化选项，零值即为默认格式
+//
+// 输出只由语法树和选项决定，且能重新解析为结构相同的树，
+// 因此格式化是幂等的：Format(parse(Format(x))) == Format(x)
+type FormatOptions struct {
+	Dialect       SqlDialect  // 目标方言，默认为 SparkDialect
+	KeywordCase   KeywordCase // 关键字大小写，函数名和标识符保持不变
+	IndentWidth   int         // 缩进空格数，默认 2
+	MaxLineWidth  int         // 最大行宽，BreakIfLong 规则据此判断，默认 80
+	LeadingCommas bool        // 换行的列表把逗号放在行首
+
+	SelectList BreakRule // SELECT 列表每项一行
+	Joins      BreakRule // JOIN 链中每个 JOIN 一行
+	Conjuncts  BreakRule // WHERE / HAVING 中每个 AND 条件一行
+	Subqueries BreakRule // 子查询展开为多行
+}
+
+// withDefaults 返回填充默认值后的选项
+func (o FormatOptions) withDefaults() FormatOptions {
+	if o.IndentWidth <= 0 {
+		o.IndentWidth = 2
+	}
+	if o.MaxLineWidth <= 0 {
+		o.MaxLineWidth = 80
+	}
+	return o
+}
+
+// Format 按格式选项把节点输出为多行 SQL
+func Format(node SqlNode, opts FormatOptions) string {
+	w := NewSqlWriter(SqlWriterConfig{Dialect: opts.Dialect, Format: &opts})
+	w.Write(node)
+	return w.String()
+}
+
+// FormatSQL 解析 SQL 并格式化，用于在比较前规范化 SQL 文本
+func FormatSQL(sql string, opts FormatOptions) (string, error) {
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		return "", err
+	}
+	return Format(result.SqlNode, opts), nil
+}
+
+// =============================================================================
+// SqlWriter 的格式化辅助方法，未设置格式化选项时都退化为单行输出
+// =============================================================================
+
+// newline 换行并按当前层级缩进
+func (w *SqlWriter) newline() {
+	w.write("\n")
+	w.write(strings.Repeat(" ", w.indent*w.format.IndentWidth))
+}
+
+// clause 开始一个子句，格式化时子句关键字另起一行
+func (w *SqlWriter) clause(keyword string) {
+	if w.format == nil {
+		w.keyword(" " + keyword + " ")
+		return
+	}
+	w.newline()
+	w.keyword(keyword + " ")
+}
+
+// shouldBreak 按规则判断是否换行，BreakIfLong 时先按单行输出 fn 的内容，看当前行是否放得下
+func (w *SqlWriter) shouldBreak(rule BreakRule, fn func(*SqlWriter)) bool {
+	if w.format == nil {
+		return false
+	}
+	switch rule {
+	case BreakAlways:
+		return true
+	case BreakNever:
+		return false
+	}
+	flat := &SqlWriter{config: w.config, dialect: w.dialect, lower: w.lower}
+	fn(flat)
+	return w.column+flat.column > w.format.MaxLineWidth
+}
+
+// writeBrokenList 每项一行输出列表，逗号按选项放在行首或行尾
+func (w *SqlWriter) writeBrokenList(nodes []SqlNode, write func(SqlNode)) {
+	for i, node := range nodes {
+		w.newline()
+		if i > 0 && w.format.LeadingCommas {
+			w.write(", ")
+		}
+		write(node)
+		if i < len(nodes)-1 && !w.format.LeadingCommas {
+			w.write(",")
+		}
+	}
+}
+
+// writeSelectList 输出 SELECT 列表，换行时每项缩进一层
+func (w *SqlWriter) writeSelectList(items []SqlNode) {
+	writeItem := func(item SqlNode) { w.writeNode(item, 0, 0) }
+	if w.format == nil || !w.shouldBreak(w.format.SelectList, func(flat *SqlWriter) {
+		flat.write(" ")
+		flat.writeList(items, ", ")
+	}) {
+		w.write(" ")
+		w.writeList(items, ", ")
+		return
+	}
+
+	w.indent++
+	w.writeBrokenList(items, writeItem)
+	w.indent--
+}
+
+// writeFrom 输出 FROM 子句的内容，并决定其中的 JOIN 是否各占一行
+func (w *SqlWriter) writeFrom(from SqlNode) {
+	saved := w.breakJoins
+	defer func() { w.breakJoins = saved }()
+
+	_, isJoin := from.(*SqlJoin)
+	w.breakJoins = isJoin && w.format != nil && w.shouldBreak(w.format.Joins, func(flat *SqlWriter) {
+		flat.writeNode(from, 0, 0)
+	})
+	w.writeNode(from, 0, 0)
+}
+
+// joinSeparator 输出 JOIN 前的分隔，JOIN 各占一行时换行，逗号连接的表多缩进 extraIndent 层
+func (w *SqlWriter) joinSeparator(extraIndent int) {
+	if !w.breakJoins {
+		w.write(" ")
+		return
+	}
+	w.indent += extraIndent
+	w.newline()
+	w.indent -= extraIndent
+}
+
+// writeCondition 输出 WHERE / HAVING 条件，换行时每个 AND 条件一行
+func (w *SqlWriter) writeCondition(condition SqlNode) {
+	conjuncts := flattenConjuncts(condition)
+	if len(conjuncts) < 2 || w.format == nil || !w.shouldBreak(w.format.Conjuncts, func(flat *SqlWriter) {
+		flat.writeNode(condition, 0, 0)
+	}) {
+		w.writeNode(condition, 0, 0)
+		return
+	}
+
+	// 拆开的条件都是 AND 的操作数，按 AND 的优先级加括号
+	and, _ := conjuncts[0].parent.Operator.Precedence()
+	w.writeNode(conjuncts[0].node, 0, and)
+	w.indent++
+	for _, conjunct := range conjuncts[1:] {
+		w.newline()
+		w.keyword("AND ")
+		_, right := conjunct.parent.Operator.Precedence()
+		w.writeNode(conjunct.node, right, and)
+	}
+	w.indent--
+}
+
+// conjunct AND 链中的一个条件及其所在的 AND 调用
+type conjunct struct {
+	node   SqlNode
+	parent *SqlCall
+}
+
+// flattenConjuncts 沿左侧展开 AND 链：((a AND b) AND c) 展开为 a、b、c
+// 右侧的 AND 保持嵌套，以免改变重新解析后的树结构；带注释的 AND 不展开，以便输出其注释
+func flattenConjuncts(node SqlNode) []conjunct {
+	call, ok := node.(*SqlCall)
+	if !ok || call.Comments != nil || call.Kind != SqlKindAnd || call.Operator == nil || call.Operator.Syntax != SyntaxBinary || len(call.Operands) != 2 {
+		return []conjunct{{node: node}}
+	}
+	left := flattenConjuncts(call.Operands[0])
+	if left[0].parent == nil {
+		left[0].parent = call
+	}
+	return append(left, conjunct{node: call.Operands[1], parent: call})
+}
+
+// writeSubquery 输出带括号的子查询，展开时括号内的查询缩进一层
+func (w *SqlWriter) writeSubquery(sel *SqlSelect) {
+	if !w.shouldBreak(w.subqueryRule(), func(flat *SqlWriter) {
+		flat.write("(")
+		flat.writeSelect(sel)
+		flat.write(")")
+	}) {
+		w.write("(")
+		w.flat(func() { w.writeSelect(sel) })
+		w.write(")")
+		return
+	}
+
+	saved := w.breakJoins
+	w.write("(")
+	w.indent++
+	w.newline()
+	w.writeSelect(sel)
+	w.indent--
+	w.newline()
+	w.write(")")
+	w.breakJoins = saved
+}
+
+// flat 在一行内输出 fn 的内容，子句和列表都不换行，关键字大小写不变
+func (w *SqlWriter) flat(fn func()) {
+	saved := w.format
+	w.format = nil
+	fn()
+	w.format = saved
+}
+
+// subqueryRule 子查询的换行规则
+func (w *SqlWriter) subqueryRule() BreakRule {
+	if w.format == nil {
+		return BreakNever
+	}
+	return w.format.Subqueries
+}
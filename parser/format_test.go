@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// buildFormatTestTree 构造带子查询的查询：
+// SELECT x.a, COUNT(*) FROM (SELECT t.a FROM t) AS x, u
+// WHERE x.a = u.a AND x.a IN (SELECT v.a FROM v) AND (u.b = 1 OR u.c = 2)
+func buildFormatTestTree() *SqlSelect {
+	binary := func(name string, kind SqlKind, l, r SqlNode) SqlNode {
+		return writerCall(name, kind, SyntaxBinary, l, r)
+	}
+	subquery := func(table string) *SqlSelect {
+		sel := NewSqlSelect(nil)
+		sel.SelectList = []SqlNode{writerIdent(table, "a")}
+		sel.From = writerIdent(table)
+		return sel
+	}
+
+	sel := NewSqlSelect(nil)
+	sel.SelectList = []SqlNode{
+		writerIdent("x", "a"),
+		writerCall("COUNT", SqlKindCall, SyntaxFunction, writerIdent("*")),
+	}
+	sel.From = NewSqlJoin(writerAlias(subquery("t"), "x"), writerIdent("u"), JoinComma, nil, nil)
+	sel.Where = binary("AND", SqlKindAnd,
+		binary("AND", SqlKindAnd,
+			binary("=", SqlKindEquals, writerIdent("x", "a"), writerIdent("u", "a")),
+			writerCall("IN", SqlKindIn, SyntaxSpecial, writerIdent("x", "a"), subquery("v"))),
+		binary("OR", SqlKindOr,
+			binary("=", SqlKindEquals, writerIdent("u", "b"), NewSqlLiteral(int64(1), LiteralInteger, nil)),
+			binary("=", SqlKindEquals, writerIdent("u", "c"), NewSqlLiteral(int64(2), LiteralInteger, nil))))
+	return sel
+}
+
+// TestFormatOptions 测试关键字大小写、缩进、逗号位置和各换行规则
+func TestFormatOptions(t *testing.T) {
+	tree := buildFormatTestTree()
+
+	tests := []struct {
+		name     string
+		opts     FormatOptions
+		expected string
+	}{
+		{
+			name: "默认选项",
+			opts: FormatOptions{},
+			expected: "SELECT x.a, COUNT(*)\n" +
+				"FROM (SELECT t.a FROM t) AS x, u\n" +
+				"WHERE x.a = u.a AND x.a IN (SELECT v.a FROM v) AND (u.b = 1 OR u.c = 2)",
+		},
+		{
+			name: "全部换行",
+			opts: FormatOptions{SelectList: BreakAlways, Joins: BreakAlways, Conjuncts: BreakAlways, Subqueries: BreakAlways},
+			expected: "SELECT\n" +
+				"  x.a,\n" +
+				"  COUNT(*)\n" +
+				"FROM (\n" +
+				"  SELECT\n" +
+				"    t.a\n" +
+				"  FROM t\n" +
+				") AS x,\n" +
+				"  u\n" +
+				"WHERE x.a = u.a\n" +
+				"  AND x.a IN (\n" +
+				"    SELECT\n" +
+				"      v.a\n" +
+				"    FROM v\n" +
+				"  )\n" +
+				"  AND (u.b = 1 OR u.c = 2)",
+		},
+		{
+			name: "小写关键字和行首逗号",
+			opts: FormatOptions{KeywordCase: KeywordLower, IndentWidth: 4, LeadingCommas: true, SelectList: BreakAlways, Conjuncts: BreakAlways},
+			expected: "select\n" +
+				"    x.a\n" +
+				"    , COUNT(*)\n" +
+				"from (select t.a from t) as x, u\n" +
+				"where x.a = u.a\n" +
+				"    and x.a in (select v.a from v)\n" +
+				"    and (u.b = 1 or u.c = 2)",
+		},
+		{
+			name: "超出行宽时换行",
+			opts: FormatOptions{MaxLineWidth: 30},
+			expected: "SELECT x.a, COUNT(*)\n" +
+				"FROM (SELECT t.a FROM t) AS x,\n" +
+				"  u\n" +
+				"WHERE x.a = u.a\n" +
+				"  AND x.a IN (\n" +
+				"    SELECT v.a\n" +
+				"    FROM v\n" +
+				"  )\n" +
+				"  AND (u.b = 1 OR u.c = 2)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(tree, tt.opts); got != tt.expected {
+				t.Errorf("Format =\n%s\n期望\n%s", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestFormatMatchesUnparse 测试不换行时格式化结果只在子句之间与 Unparse 不同
+func TestFormatMatchesUnparse(t *testing.T) {
+	never := FormatOptions{SelectList: BreakNever, Joins: BreakNever, Conjuncts: BreakNever, Subqueries: BreakNever}
+	for _, tree := range []*SqlSelect{buildFormatTestTree(), buildCloneTestTree(), buildDialectTestTree()} {
+		got := strings.ReplaceAll(Format(tree, never), "\n", " ")
+		if expected := Unparse(tree); got != expected {
+			t.Errorf("Format =\n%s\n期望\n%s", got, expected)
+		}
+	}
+
+	// 方言选项与 UnparseWithDialect 一致
+	tree := buildDialectTestTree()
+	never.Dialect = TrinoDialect
+	if got := strings.ReplaceAll(Format(tree, never), "\n", " "); got != UnparseWithDialect(tree, TrinoDialect) {
+		t.Errorf("Format = %q", got)
+	}
+}
+
+// TestFormatIdempotent 测试格式化结果重新解析后再次格式化保持不变
+func TestFormatIdempotent(t *testing.T) {
+	sqls := []string{
+		"select t.a, t.b + 1 from t where t.a = 1 and t.b = 2 and (t.c = 3 or t.d = 4)",
+		"select /*+ JOIN(TEE) */ a.id, b.name from plat1.a join plat2.b on a.id = b.id left join c on b.id = c.id",
+		"select x.a from (select t.a from t where t.b in (select u.b from u)) as x order by x.a limit 10",
+	}
+	options := []FormatOptions{
+		{},
+		{KeywordCase: KeywordLower, LeadingCommas: true, IndentWidth: 4},
+		{SelectList: BreakAlways, Joins: BreakAlways, Conjuncts: BreakAlways, Subqueries: BreakAlways},
+		{MaxLineWidth: 20},
+	}
+
+	for _, sql := range sqls {
+		for _, opts := range options {
+			first, err := FormatSQL(sql, opts)
+			if err != nil {
+				t.Fatalf("格式化 %q 失败: %v", sql, err)
+			}
+			second, err := FormatSQL(first, opts)
+			if err != nil {
+				t.Fatalf("重新解析 %q 失败: %v", first, err)
+			}
+			if first != second {
+				t.Errorf("格式化不是幂等的:\n%s\n再次格式化为\n%s", first, second)
+			}
+		}
+	}
+}
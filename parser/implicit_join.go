@@ -0,0 +1,242 @@
+package parser
+
+import (
+	"strings"
+)
+
+// =============================================================================
+// 隐式 JOIN 改写
+// =============================================================================
+
+// implicitJoinCondition 可以改写为 JOIN 条件的等值条件
+type implicitJoinCondition struct {
+	condition SqlNode
+	left      int // 左侧引用的 FROM 项下标
+	right     int // 右侧引用的 FROM 项下标
+}
+
+// RewriteImplicitJoins 将 FROM a, b WHERE a.id = b.id 形式的隐式连接改写为 INNER JOIN
+// 参考 Java 版本 SqlNodeBuilderV2 的 dealJoinList2Node
+//
+// 只有 WHERE 顶层 AND 连接的、两侧分别引用 FROM 中两个不同表的列等值条件会被移到 JOIN 上，
+// OR / NOT 内部的条件以及其他条件原样保留在 WHERE 中，因此改写前后语义一致。
+// 会递归处理 FROM 中的子查询以及 EXPLAIN / DESCRIBE 包装的查询。节点会被原地修改并返回。
+func RewriteImplicitJoins(node SqlNode) SqlNode {
+	switch n := node.(type) {
+	case *SqlSelect:
+		rewriteSelectImplicitJoins(n)
+	case *SqlExplain:
+		RewriteImplicitJoins(n.Statement)
+	case *SqlDescribe:
+		RewriteImplicitJoins(n.Query)
+	}
+	return node
+}
+
+// rewriteSelectImplicitJoins 改写单个 SELECT 的隐式连接
+func rewriteSelectImplicitJoins(sel *SqlSelect) {
+	fromItems := flattenCommaJoin(sel.From)
+	for _, item := range fromItems {
+		rewriteFromItem(item)
+	}
+	if len(fromItems) < 2 {
+		return
+	}
+
+	// 表名/别名 -> FROM 项下标
+	tableIndex := make(map[string]int)
+	for i, item := range fromItems {
+		for _, key := range fromItemKeys(item) {
+			if _, exists := tableIndex[key]; !exists {
+				tableIndex[key] = i
+			}
+		}
+	}
+
+	// 区分 join 条件和保留在 WHERE 中的条件
+	var joinConditions []*implicitJoinCondition
+	var remaining []SqlNode
+	for _, conjunct := range splitAndConjuncts(sel.Where) {
+		if condition := matchJoinCondition(conjunct, tableIndex); condition != nil {
+			joinConditions = append(joinConditions, condition)
+		} else {
+			remaining = append(remaining, conjunct)
+		}
+	}
+	if len(joinConditions) == 0 {
+		return
+	}
+
+	sel.From = buildJoinTree(fromItems, joinConditions)
+	sel.Where = andNodes(remaining...)
+}
+
+// buildJoinTree 根据 join 条件构建左深的 JOIN 树
+// 没有出现在任何条件中的表以笛卡尔积（ON 1 = 1）连接在最后
+func buildJoinTree(fromItems []SqlNode, joinConditions []*implicitJoinCondition) SqlNode {
+	joined := make([]bool, len(fromItems))
+	var tree SqlNode
+	var lastJoin *SqlJoin
+
+	pending := joinConditions
+	for len(pending) > 0 {
+		var deferred []*implicitJoinCondition
+		for _, c := range pending {
+			if tree == nil {
+				tree = fromItems[c.left]
+				joined[c.left] = true
+			}
+
+			switch {
+			case joined[c.left] && joined[c.right]:
+				// 两侧都已连接，条件合并到最近的 JOIN 上
+				lastJoin.Condition = andNodes(lastJoin.Condition, c.condition)
+			case joined[c.left] || joined[c.right]:
+				other := c.right
+				if joined[c.right] {
+					other = c.left
+				}
+				lastJoin = NewSqlJoin(tree, fromItems[other], JoinInner, c.condition, syntheticPos(tree, fromItems[other]))
+				tree = lastJoin
+				joined[other] = true
+			default:
+				// 与已连接的表暂不相连，等待下一轮
+				deferred = append(deferred, c)
+			}
+		}
+
+		if len(deferred) > 0 && len(deferred) == len(pending) {
+			// 剩余条件都与已连接的表不相连，先以笛卡尔积连接其中一张表
+			first := deferred[0]
+			lastJoin = NewSqlJoin(tree, fromItems[first.left], JoinInner, newTrueCondition(), syntheticPos(tree, fromItems[first.left]))
+			tree = lastJoin
+			joined[first.left] = true
+		}
+		pending = deferred
+	}
+
+	// 连接额外的表（笛卡尔积）
+	for i, item := range fromItems {
+		if !joined[i] {
+			tree = NewSqlJoin(tree, item, JoinInner, newTrueCondition(), syntheticPos(tree, item))
+		}
+	}
+
+	return tree
+}
+
+// rewriteFromItem 递归改写 FROM 项中的子查询
+func rewriteFromItem(item SqlNode) {
+	switch n := item.(type) {
+	case *SqlSelect:
+		rewriteSelectImplicitJoins(n)
+	case *SqlJoin:
+		rewriteFromItem(n.Left)
+		rewriteFromItem(n.Right)
+	case *SqlCall:
+		if n.Operator != nil && n.Operator.Kind == SqlKindAs && len(n.Operands) > 0 {
+			rewriteFromItem(n.Operands[0])
+		}
+	}
+}
+
+// matchJoinCondition 判断条件是否为 a.x = b.y 形式、两侧引用不同 FROM 项的等值条件
+func matchJoinCondition(node SqlNode, tableIndex map[string]int) *implicitJoinCondition {
+	call, ok := node.(*SqlCall)
+	if !ok || call.Operator == nil || call.Operator.Kind != SqlKindEquals || len(call.Operands) != 2 {
+		return nil
+	}
+
+	left, ok := columnTableIndex(call.Operands[0], tableIndex)
+	if !ok {
+		return nil
+	}
+	right, ok := columnTableIndex(call.Operands[1], tableIndex)
+	if !ok || left == right {
+		return nil
+	}
+
+	return &implicitJoinCondition{condition: call, left: left, right: right}
+}
+
+// columnTableIndex 返回列引用 table.column 所属的 FROM 项下标
+func columnTableIndex(node SqlNode, tableIndex map[string]int) (int, bool) {
+	identifier, ok := node.(*SqlIdentifier)
+	if !ok || len(identifier.Names) < 2 {
+		return 0, false
+	}
+	qualifier := strings.Join(identifier.Names[:len(identifier.Names)-1], ".")
+	index, ok := tableIndex[qualifier]
+	return index, ok
+}
+
+// fromItemKeys 返回可以用来引用 FROM 项的名字（别名、完整表名、不带库名的表名）
+func fromItemKeys(item SqlNode) []string {
+	switch n := item.(type) {
+	case *SqlIdentifier:
+		if len(n.Names) == 0 {
+			return nil
+		}
+		return []string{n.ToString(), n.Names[len(n.Names)-1]}
+	case *SqlTableRef:
+		if n.Name != nil {
+			return fromItemKeys(n.Name)
+		}
+	case *SqlCall:
+		if n.Operator != nil && n.Operator.Kind == SqlKindAs && len(n.Operands) >= 2 {
+			if alias, ok := n.Operands[1].(*SqlIdentifier); ok {
+				return []string{alias.GetSimple()}
+			}
+		}
+	}
+	return nil
+}
+
+// flattenCommaJoin 将 COMMA JOIN 树展开为 FROM 项列表
+func flattenCommaJoin(node SqlNode) []SqlNode {
+	if node == nil {
+		return nil
+	}
+	if join, ok := node.(*SqlJoin); ok && join.JoinType == JoinComma {
+		return append(flattenCommaJoin(join.Left), join.Right)
+	}
+	return []SqlNode{node}
+}
+
+// splitAndConjuncts 将条件按顶层 AND 拆分
+func splitAndConjuncts(node SqlNode) []SqlNode {
+	if node == nil {
+		return nil
+	}
+	if call, ok := node.(*SqlCall); ok && call.Operator != nil && call.Operator.Kind == SqlKindAnd && len(call.Operands) == 2 {
+		return append(splitAndConjuncts(call.Operands[0]), splitAndConjuncts(call.Operands[1])...)
+	}
+	return []SqlNode{node}
+}
+
+// andNodes 用 AND 连接所有非空条件，没有条件时返回 nil
+func andNodes(nodes ...SqlNode) SqlNode {
+	var result SqlNode
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if result == nil {
+			result = node
+			continue
+		}
+		op := NewSqlOperator("AND", SqlKindAnd, SyntaxBinary)
+		result = NewSqlCall(op, []SqlNode{result, node}, syntheticPos(result, node))
+	}
+	return result
+}
+
+// newTrueCondition 构建 1 = 1 条件（笛卡尔积）
+// 两侧使用不同的字面量节点，改写其中一侧不会影响另一侧
+func newTrueCondition() SqlNode {
+	return NewSqlCall(
+		NewSqlOperator("=", SqlKindEquals, SyntaxBinary),
+		[]SqlNode{NewSqlLiteral(int64(1), LiteralInteger, syntheticPos()), NewSqlLiteral(int64(1), LiteralInteger, syntheticPos())},
+		syntheticPos(),
+	)
+}
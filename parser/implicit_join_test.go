@@ -0,0 +1,145 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestWherePreservedWithoutRewrite 测试默认情况下 WHERE 条件按原样保留
+func TestWherePreservedWithoutRewrite(t *testing.T) {
+	testCases := []struct {
+		name  string
+		sql   string
+		where string
+	}{
+		{
+			name:  "单表 OR 条件",
+			sql:   "select t.a from t where t.a = 1 or t.b = 2",
+			where: "t.a = 1 OR t.b = 2",
+		},
+		{
+			name:  "多表条件不被提取",
+			sql:   "select a.x from a, b where a.id = b.id and a.x = 1",
+			where: "a.id = b.id AND a.x = 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseSQLWithAntlr(tc.sql)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			sel, ok := result.SqlNode.(*SqlSelect)
+			if !ok {
+				t.Fatalf("期望 *SqlSelect，实际为 %T", result.SqlNode)
+			}
+			if sel.Where == nil {
+				t.Fatalf("WHERE 条件丢失")
+			}
+			if got := sel.Where.ToString(); got != tc.where {
+				t.Errorf("WHERE = %q，期望 %q", got, tc.where)
+			}
+		})
+	}
+}
+
+// TestCommaJoinKeptByDefault 测试默认情况下 FROM a, b 保留为逗号连接
+func TestCommaJoinKeptByDefault(t *testing.T) {
+	result, err := ParseSQLWithAntlr("select a.x from a, b where a.id = b.id")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	sel := result.SqlNode.(*SqlSelect)
+	join, ok := sel.From.(*SqlJoin)
+	if !ok {
+		t.Fatalf("期望 *SqlJoin，实际为 %T", sel.From)
+	}
+	if join.JoinType != JoinComma || join.Condition != nil {
+		t.Errorf("期望无条件的逗号连接，实际为 %s", join.ToString())
+	}
+}
+
+// TestRewriteImplicitJoins 测试隐式连接改写
+func TestRewriteImplicitJoins(t *testing.T) {
+	testCases := []struct {
+		name  string
+		sql   string
+		from  string
+		where string
+	}{
+		{
+			name:  "两表等值连接",
+			sql:   "select a.x from a, b where a.id = b.id and a.x = 1",
+			from:  "a INNER JOIN b ON a.id = b.id",
+			where: "a.x = 1",
+		},
+		{
+			name: "三表链式连接",
+			sql:  "select a.x from a, b, c where a.id = b.id and b.id = c.id",
+			from: "a INNER JOIN b ON a.id = b.id INNER JOIN c ON b.id = c.id",
+		},
+		{
+			name:  "OR 内部的等值条件不提取",
+			sql:   "select a.x from a, b where a.id = b.id or a.x = 1",
+			from:  "a, b",
+			where: "a.id = b.id OR a.x = 1",
+		},
+		{
+			name:  "没有连接条件的表以笛卡尔积连接",
+			sql:   "select a.x from a, b, c where a.id = b.id and c.y = 2",
+			from:  "a INNER JOIN b ON a.id = b.id INNER JOIN c ON 1 = 1",
+			where: "c.y = 2",
+		},
+		{
+			name: "带库名的表",
+			sql:  "select db1.a.x from db1.a, db2.b where db1.a.id = db2.b.id",
+			from: "db1.a INNER JOIN db2.b ON db1.a.id = db2.b.id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseSQLWithAntlr(tc.sql)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			sel := RewriteImplicitJoins(result.SqlNode).(*SqlSelect)
+			if got := sel.From.ToString(); got != tc.from {
+				t.Errorf("FROM = %q，期望 %q", got, tc.from)
+			}
+			where := ""
+			if sel.Where != nil {
+				where = sel.Where.ToString()
+			}
+			if where != tc.where {
+				t.Errorf("WHERE = %q，期望 %q", where, tc.where)
+			}
+		})
+	}
+}
+
+// TestTrueConditionIsTree 测试笛卡尔积的 1 = 1 条件不共享节点
+func TestTrueConditionIsTree(t *testing.T) {
+	sel := NewSqlSelect(nil)
+	sel.SelectList = []SqlNode{NewSqlIdentifier([]string{"*"}, nil)}
+	sel.From = NewSqlJoin(NewSqlIdentifier([]string{"a"}, nil), NewSqlIdentifier([]string{"b"}, nil), JoinComma, nil, nil)
+	sel.From = NewSqlJoin(sel.From, NewSqlIdentifier([]string{"c"}, nil), JoinComma, nil, nil)
+	sel.Where = NewSqlCall(NewSqlOperator("=", SqlKindEquals, SyntaxBinary),
+		[]SqlNode{NewSqlIdentifier([]string{"a", "id"}, nil), NewSqlIdentifier([]string{"b", "id"}, nil)}, nil)
+	RewriteImplicitJoins(sel)
+
+	if got := Unparse(sel.From); got != "a INNER JOIN b ON a.id = b.id INNER JOIN c ON 1 = 1" {
+		t.Fatalf("改写结果 = %q", got)
+	}
+	seen := make(map[SqlNode]bool)
+	Inspect(sel, func(node SqlNode) bool {
+		if node == nil {
+			return false
+		}
+		if seen[node] {
+			t.Errorf("节点 %s 被多处引用", Unparse(node))
+		}
+		seen[node] = true
+		return true
+	})
+}
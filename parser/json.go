@@ -0,0 +1,653 @@
+package parser
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// =============================================================================
+// JSON 编码 - 在服务之间传递语法树
+// =============================================================================
+
+// JSONVersion 当前的 JSON 编码版本
+// 编码不兼容地改变时加 1，UnmarshalNode 拒绝不认识的版本
+const JSONVersion = 1
+
+// NodeJSONSchema JSON 编码的 JSON Schema（draft 2020-12），描述 MarshalNode 的输出
+//
+//go:embed sqlnode.schema.json
+var NodeJSONSchema []byte
+
+// MarshalNode 把语法树编码为带版本号的 JSON：{"version": 1, "node": {...}}
+// 节点本身的 MarshalJSON 只输出 node 部分
+func MarshalNode(node SqlNode) ([]byte, error) {
+	return json.Marshal(jsonEnvelope{Version: JSONVersion, Node: child(node)})
+}
+
+// UnmarshalNode 从 MarshalNode 的输出重建语法树
+// 也接受节点 MarshalJSON 输出的不带版本号的节点对象，按当前版本解码
+func UnmarshalNode(data []byte) (SqlNode, error) {
+	var envelope struct {
+		Version *int            `json:"version"`
+		Node    json.RawMessage `json:"node"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("解码 JSON 失败: %w", err)
+	}
+	if envelope.Version == nil {
+		return decodeNode(data)
+	}
+	if *envelope.Version != JSONVersion {
+		return nil, fmt.Errorf("不支持的 JSON 编码版本: %d，当前版本为 %d", *envelope.Version, JSONVersion)
+	}
+	return decodeNode(envelope.Node)
+}
+
+// jsonEnvelope 带版本号的顶层对象
+type jsonEnvelope struct {
+	Version int        `json:"version"`
+	Node    *jsonChild `json:"node"`
+}
+
+// jsonNode 节点的 JSON 形式，Type 决定其余字段中哪些有效，见 sqlnode.schema.json
+type jsonNode struct {
+	Type     string        `json:"type"`
+	Kind     SqlKind       `json:"kind"`
+	Pos      *jsonPos      `json:"pos,omitempty"`
+	Comments *jsonComments `json:"comments,omitempty"`
+
+	// identifier
+	Names []string `json:"names,omitempty"`
+
+	// literal
+	Value     json.RawMessage `json:"value,omitempty"`
+	ValueType string          `json:"valueType,omitempty"`
+	TypeName  string          `json:"typeName,omitempty"`
+
+	// call
+	Operator *jsonOperator `json:"operator,omitempty"`
+	Operands jsonChildren  `json:"operands,omitempty"`
+
+	// hint、lambda
+	Name       string       `json:"name,omitempty"`
+	Parameters jsonChildren `json:"parameters,omitempty"`
+	Body       *jsonChild   `json:"body,omitempty"`
+
+	// select
+	Hints       jsonChildren `json:"hints,omitempty"`
+	Keywords    []string     `json:"keywords,omitempty"`
+	SelectList  jsonChildren `json:"selectList,omitempty"`
+	From        *jsonChild   `json:"from,omitempty"`
+	Where       *jsonChild   `json:"where,omitempty"`
+	GroupBy     jsonChildren `json:"groupBy,omitempty"`
+	Having      *jsonChild   `json:"having,omitempty"`
+	WindowDecls jsonChildren `json:"windowDecls,omitempty"`
+	OrderBy     jsonChildren `json:"orderBy,omitempty"`
+	Offset      *jsonChild   `json:"offset,omitempty"`
+	Fetch       *jsonChild   `json:"fetch,omitempty"`
+
+	// join
+	Left      *jsonChild   `json:"left,omitempty"`
+	Right     *jsonChild   `json:"right,omitempty"`
+	JoinType  JoinType     `json:"joinType,omitempty"`
+	Condition *jsonChild   `json:"condition,omitempty"`
+	Using     jsonChildren `json:"using,omitempty"`
+
+	// basicCall、nodeList
+	Operand *jsonChild   `json:"operand,omitempty"`
+	Alias   string       `json:"alias,omitempty"`
+	List    jsonChildren `json:"list,omitempty"`
+
+	// tableRef
+	Table    *jsonChild    `json:"table,omitempty"`
+	Temporal *jsonTemporal `json:"temporal,omitempty"`
+	Sample   *jsonSample   `json:"sample,omitempty"`
+
+	// explain、describe、show、use
+	Mode          string     `json:"mode,omitempty"`
+	Statement     *jsonChild `json:"statement,omitempty"`
+	Column        *jsonChild `json:"column,omitempty"`
+	Option        string     `json:"option,omitempty"`
+	Query         *jsonChild `json:"query,omitempty"`
+	Target        string     `json:"target,omitempty"`
+	Object        *jsonChild `json:"object,omitempty"`
+	Namespace     *jsonChild `json:"namespace,omitempty"`
+	Pattern       string     `json:"pattern,omitempty"`
+	NamespaceType string     `json:"namespaceType,omitempty"`
+
+	// error
+	Text string `json:"text,omitempty"`
+}
+
+// jsonPos 位置信息，nil 与全零的位置分别编码为缺省和全零对象
+type jsonPos struct {
+	Line        int  `json:"line"`
+	Column      int  `json:"column"`
+	EndLine     int  `json:"endLine"`
+	EndColumn   int  `json:"endColumn"`
+	StartOffset int  `json:"startOffset"`
+	EndOffset   int  `json:"endOffset"`
+	Synthetic   bool `json:"synthetic,omitempty"`
+}
+
+type jsonComment struct {
+	Text string   `json:"text"`
+	Pos  *jsonPos `json:"pos,omitempty"`
+}
+
+type jsonComments struct {
+	Leading  []jsonComment `json:"leading,omitempty"`
+	Trailing []jsonComment `json:"trailing,omitempty"`
+}
+
+type jsonOperator struct {
+	Name      string  `json:"name"`
+	Kind      SqlKind `json:"kind"`
+	Syntax    string  `json:"syntax"`
+	LeftPrec  int     `json:"leftPrec,omitempty"`
+	RightPrec int     `json:"rightPrec,omitempty"`
+}
+
+type jsonTemporal struct {
+	Type  SqlTemporalType `json:"type"`
+	Value *jsonChild      `json:"value,omitempty"`
+}
+
+type jsonSample struct {
+	Method      SqlSampleMethod `json:"method"`
+	Value       *jsonChild      `json:"value,omitempty"`
+	Numerator   int64           `json:"numerator,omitempty"`
+	Denominator int64           `json:"denominator,omitempty"`
+	BucketOn    *jsonChild      `json:"bucketOn,omitempty"`
+	Seed        *int64          `json:"seed,omitempty"`
+}
+
+// jsonChild 子节点，解码时按 type 重建具体的节点类型
+// nil 子节点用 nil 指针表示，借助 omitempty 不输出
+type jsonChild struct {
+	node SqlNode
+}
+
+// child 包装子节点，nil 节点返回 nil
+func child(node SqlNode) *jsonChild {
+	if node == nil {
+		return nil
+	}
+	return &jsonChild{node}
+}
+
+// get 取出子节点，nil 时返回 nil
+func (c *jsonChild) get() SqlNode {
+	if c == nil {
+		return nil
+	}
+	return c.node
+}
+
+func (c *jsonChild) MarshalJSON() ([]byte, error) {
+	return marshalNode(c.node)
+}
+
+func (c *jsonChild) UnmarshalJSON(data []byte) error {
+	node, err := decodeNode(data)
+	c.node = node
+	return err
+}
+
+type jsonChildren []*jsonChild
+
+// 节点类型名称，JSON 中 type 字段的取值
+const (
+	jsonTypeIdentifier = "identifier"
+	jsonTypeLiteral    = "literal"
+	jsonTypeCall       = "call"
+	jsonTypeHint       = "hint"
+	jsonTypeSelect     = "select"
+	jsonTypeJoin       = "join"
+	jsonTypeBasicCall  = "basicCall"
+	jsonTypeNodeList   = "nodeList"
+	jsonTypeLambda     = "lambda"
+	jsonTypeTableRef   = "tableRef"
+	jsonTypeExplain    = "explain"
+	jsonTypeDescribe   = "describe"
+	jsonTypeShow       = "show"
+	jsonTypeUse        = "use"
+	jsonTypeError      = "error"
+)
+
+var literalTypeNames = map[SqlLiteralType]string{
+	LiteralNull:      "NULL",
+	LiteralBoolean:   "BOOLEAN",
+	LiteralInteger:   "INTEGER",
+	LiteralDecimal:   "DECIMAL",
+	LiteralString:    "STRING",
+	LiteralDate:      "DATE",
+	LiteralTime:      "TIME",
+	LiteralTimestamp: "TIMESTAMP",
+	LiteralInterval:  "INTERVAL",
+	LiteralSymbol:    "SYMBOL",
+}
+
+var syntaxNames = map[SqlSyntax]string{
+	SyntaxFunction: "FUNCTION",
+	SyntaxPrefix:   "PREFIX",
+	SyntaxPostfix:  "POSTFIX",
+	SyntaxBinary:   "BINARY",
+	SyntaxSpecial:  "SPECIAL",
+}
+
+// lookupName 按名称反查枚举值
+func lookupName[T comparable](names map[T]string, name, what string) (T, error) {
+	for value, n := range names {
+		if n == name {
+			return value, nil
+		}
+	}
+	var zero T
+	return zero, fmt.Errorf("未知的%s: %q", what, name)
+}
+
+// =============================================================================
+// 编码
+// =============================================================================
+
+func (n *SqlIdentifier) MarshalJSON() ([]byte, error) { return marshalNode(n) }
+func (n *SqlLiteral) MarshalJSON() ([]byte, error)    { return marshalNode(n) }
+func (n *SqlCall) MarshalJSON() ([]byte, error)       { return marshalNode(n) }
+func (n *SqlHint) MarshalJSON() ([]byte, error)       { return marshalNode(n) }
+func (n *SqlSelect) MarshalJSON() ([]byte, error)     { return marshalNode(n) }
+func (n *SqlJoin) MarshalJSON() ([]byte, error)       { return marshalNode(n) }
+func (n *SqlBasicCall) MarshalJSON() ([]byte, error)  { return marshalNode(n) }
+func (n *SqlNodeList) MarshalJSON() ([]byte, error)   { return marshalNode(n) }
+func (n *SqlLambda) MarshalJSON() ([]byte, error)     { return marshalNode(n) }
+func (n *SqlTableRef) MarshalJSON() ([]byte, error)   { return marshalNode(n) }
+func (n *SqlExplain) MarshalJSON() ([]byte, error)    { return marshalNode(n) }
+func (n *SqlDescribe) MarshalJSON() ([]byte, error)   { return marshalNode(n) }
+func (n *SqlShow) MarshalJSON() ([]byte, error)       { return marshalNode(n) }
+func (n *SqlUse) MarshalJSON() ([]byte, error)        { return marshalNode(n) }
+func (n *SqlErrorNode) MarshalJSON() ([]byte, error)  { return marshalNode(n) }
+
+// marshalNode 把节点编码为 JSON 对象
+func marshalNode(node SqlNode) ([]byte, error) {
+	j, err := toJSONNode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// toJSONNode 把节点转换为 JSON 形式，子节点在编码时递归转换
+func toJSONNode(node SqlNode) (*jsonNode, error) {
+	j := &jsonNode{Kind: node.GetKind(), Pos: toJSONPos(node.GetPos()), Comments: toJSONComments(NodeComments(node))}
+
+	switch n := node.(type) {
+	case *SqlIdentifier:
+		j.Type = jsonTypeIdentifier
+		j.Names = n.Names
+	case *SqlLiteral:
+		j.Type = jsonTypeLiteral
+		j.ValueType = literalTypeNames[n.ValueType]
+		j.TypeName = n.TypeName
+		value, err := json.Marshal(n.Value)
+		if err != nil {
+			return nil, fmt.Errorf("无法编码字面量 %v: %w", n.Value, err)
+		}
+		j.Value = value
+	case *SqlCall:
+		j.Type = jsonTypeCall
+		if op := n.Operator; op != nil {
+			j.Operator = &jsonOperator{Name: op.Name, Kind: op.Kind, Syntax: syntaxNames[op.Syntax], LeftPrec: op.LeftPrec, RightPrec: op.RightPrec}
+		}
+		j.Operands = toJSONChildren(n.Operands)
+	case *SqlHint:
+		j.Type = jsonTypeHint
+		j.Name = n.Name
+		j.Parameters = toJSONChildren(n.Parameters)
+	case *SqlSelect:
+		j.Type = jsonTypeSelect
+		for _, hint := range n.Hints {
+			j.Hints = append(j.Hints, child(hint))
+		}
+		j.Keywords = n.KeywordList
+		j.SelectList = toJSONChildren(n.SelectList)
+		j.From = child(n.From)
+		j.Where = child(n.Where)
+		j.GroupBy = toJSONChildren(n.GroupBy)
+		j.Having = child(n.Having)
+		j.WindowDecls = toJSONChildren(n.WindowDecls)
+		j.OrderBy = toJSONChildren(n.OrderBy)
+		j.Offset = child(n.Offset)
+		j.Fetch = child(n.Fetch)
+	case *SqlJoin:
+		j.Type = jsonTypeJoin
+		j.Left = child(n.Left)
+		j.Right = child(n.Right)
+		j.JoinType = n.JoinType
+		j.Condition = child(n.Condition)
+		j.Using = toJSONChildren(n.Using)
+	case *SqlBasicCall:
+		j.Type = jsonTypeBasicCall
+		j.Operand = child(n.Operand)
+		j.Alias = n.Alias
+	case *SqlNodeList:
+		j.Type = jsonTypeNodeList
+		j.List = toJSONChildren(n.List)
+	case *SqlLambda:
+		j.Type = jsonTypeLambda
+		for _, param := range n.Parameters {
+			j.Parameters = append(j.Parameters, identifierChild(param))
+		}
+		j.Body = child(n.Body)
+	case *SqlTableRef:
+		j.Type = jsonTypeTableRef
+		j.Table = identifierChild(n.Name)
+		if n.Temporal != nil {
+			j.Temporal = &jsonTemporal{Type: n.Temporal.Type, Value: child(n.Temporal.Value)}
+		}
+		if s := n.Sample; s != nil {
+			j.Sample = &jsonSample{Method: s.Method, Value: child(s.Value), Numerator: s.Numerator,
+				Denominator: s.Denominator, BucketOn: child(s.BucketOn), Seed: s.Seed}
+		}
+	case *SqlExplain:
+		j.Type = jsonTypeExplain
+		j.Mode = n.Mode
+		j.Statement = child(n.Statement)
+	case *SqlDescribe:
+		j.Type = jsonTypeDescribe
+		j.Table = identifierChild(n.Table)
+		j.Column = identifierChild(n.Column)
+		j.Option = n.Option
+		j.Query = child(n.Query)
+	case *SqlShow:
+		j.Type = jsonTypeShow
+		j.Target = n.Target
+		j.Object = identifierChild(n.Object)
+		j.Namespace = identifierChild(n.Namespace)
+		j.Pattern = n.Pattern
+	case *SqlUse:
+		j.Type = jsonTypeUse
+		j.NamespaceType = n.NamespaceType
+		j.Namespace = identifierChild(n.Namespace)
+	case *SqlErrorNode:
+		j.Type = jsonTypeError
+		j.Text = n.Text
+	default:
+		return nil, fmt.Errorf("不支持编码的节点类型: %T", node)
+	}
+	return j, nil
+}
+
+// identifierChild 把可能为 nil 的标识符包装为子节点，避免 nil 指针变为非 nil 接口
+func identifierChild(identifier *SqlIdentifier) *jsonChild {
+	if identifier == nil {
+		return nil
+	}
+	return child(identifier)
+}
+
+func toJSONChildren(nodes []SqlNode) jsonChildren {
+	if nodes == nil {
+		return nil
+	}
+	children := make(jsonChildren, len(nodes))
+	for i, node := range nodes {
+		children[i] = child(node)
+	}
+	return children
+}
+
+func toJSONPos(pos *SqlParserPos) *jsonPos {
+	if pos == nil {
+		return nil
+	}
+	return &jsonPos{
+		Line:        pos.LineNumber,
+		Column:      pos.ColumnNumber,
+		EndLine:     pos.EndLine,
+		EndColumn:   pos.EndColumn,
+		StartOffset: pos.StartOffset,
+		EndOffset:   pos.EndOffset,
+		Synthetic:   pos.Synthetic,
+	}
+}
+
+func toJSONComments(comments *SqlComments) *jsonComments {
+	if comments == nil {
+		return nil
+	}
+	convert := func(list []*SqlComment) []jsonComment {
+		var converted []jsonComment
+		for _, comment := range list {
+			converted = append(converted, jsonComment{Text: comment.Text, Pos: toJSONPos(comment.Pos)})
+		}
+		return converted
+	}
+	return &jsonComments{Leading: convert(comments.Leading), Trailing: convert(comments.Trailing)}
+}
+
+// =============================================================================
+// 解码
+// =============================================================================
+
+// decodeNode 解码单个节点，null 解码为 nil
+func decodeNode(data []byte) (SqlNode, error) {
+	if trimmed := strings.TrimSpace(string(data)); trimmed == "" || trimmed == "null" {
+		return nil, nil
+	}
+	var j jsonNode
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return j.toNode()
+}
+
+// toNode 按 type 重建具体的节点
+func (j *jsonNode) toNode() (SqlNode, error) {
+	pos := j.Pos.toPos()
+	var node SqlNode
+
+	switch j.Type {
+	case jsonTypeIdentifier:
+		node = NewSqlIdentifier(j.Names, pos)
+	case jsonTypeLiteral:
+		literal, err := j.toLiteral(pos)
+		if err != nil {
+			return nil, err
+		}
+		node = literal
+	case jsonTypeCall:
+		var op *SqlOperator
+		if j.Operator != nil {
+			syntax, err := lookupName(syntaxNames, j.Operator.Syntax, "操作符语法")
+			if err != nil {
+				return nil, err
+			}
+			op = &SqlOperator{Name: j.Operator.Name, Kind: j.Operator.Kind, Syntax: syntax,
+				LeftPrec: j.Operator.LeftPrec, RightPrec: j.Operator.RightPrec}
+		}
+		node = NewSqlCall(op, j.Operands.nodes(), pos)
+	case jsonTypeHint:
+		node = NewSqlHint(j.Name, j.Parameters.nodes(), pos)
+	case jsonTypeSelect:
+		sel := NewSqlSelect(pos)
+		for _, c := range j.Hints {
+			hint, ok := c.get().(*SqlHint)
+			if !ok {
+				return nil, fmt.Errorf("hints 中的节点类型应为 hint，实际为 %T", c.get())
+			}
+			sel.Hints = append(sel.Hints, hint)
+		}
+		sel.KeywordList = j.Keywords
+		sel.SelectList = j.SelectList.nodes()
+		sel.From = j.From.get()
+		sel.Where = j.Where.get()
+		sel.GroupBy = j.GroupBy.nodes()
+		sel.Having = j.Having.get()
+		sel.WindowDecls = j.WindowDecls.nodes()
+		sel.OrderBy = j.OrderBy.nodes()
+		sel.Offset = j.Offset.get()
+		sel.Fetch = j.Fetch.get()
+		node = sel
+	case jsonTypeJoin:
+		join := NewSqlJoin(j.Left.get(), j.Right.get(), j.JoinType, j.Condition.get(), pos)
+		join.Using = j.Using.nodes()
+		node = join
+	case jsonTypeBasicCall:
+		node = NewSqlBasicCall(j.Operand.get(), j.Alias, pos)
+	case jsonTypeNodeList:
+		node = NewSqlNodeList(j.List.nodes(), pos)
+	case jsonTypeLambda:
+		params := make([]*SqlIdentifier, len(j.Parameters))
+		for i, c := range j.Parameters {
+			param, err := c.identifier("parameters")
+			if err != nil {
+				return nil, err
+			}
+			params[i] = param
+		}
+		node = NewSqlLambda(params, j.Body.get(), pos)
+	case jsonTypeTableRef:
+		name, err := j.Table.identifier("table")
+		if err != nil {
+			return nil, err
+		}
+		var temporal *SqlTemporalSpec
+		if j.Temporal != nil {
+			temporal = &SqlTemporalSpec{Type: j.Temporal.Type, Value: j.Temporal.Value.get()}
+		}
+		var sample *SqlSampleSpec
+		if s := j.Sample; s != nil {
+			sample = &SqlSampleSpec{Method: s.Method, Value: s.Value.get(), Numerator: s.Numerator,
+				Denominator: s.Denominator, BucketOn: s.BucketOn.get(), Seed: s.Seed}
+		}
+		node = NewSqlTableRef(name, temporal, sample, pos)
+	case jsonTypeExplain:
+		node = NewSqlExplain(j.Mode, j.Statement.get(), pos)
+	case jsonTypeDescribe:
+		table, err := j.Table.identifier("table")
+		if err != nil {
+			return nil, err
+		}
+		describe := NewSqlDescribe(table, j.Query.get(), pos)
+		describe.Option = j.Option
+		if describe.Column, err = j.Column.identifier("column"); err != nil {
+			return nil, err
+		}
+		node = describe
+	case jsonTypeShow:
+		show := NewSqlShow(j.Target, pos)
+		show.Pattern = j.Pattern
+		var err error
+		if show.Object, err = j.Object.identifier("object"); err != nil {
+			return nil, err
+		}
+		if show.Namespace, err = j.Namespace.identifier("namespace"); err != nil {
+			return nil, err
+		}
+		node = show
+	case jsonTypeUse:
+		namespace, err := j.Namespace.identifier("namespace")
+		if err != nil {
+			return nil, err
+		}
+		use := NewSqlUse(j.Kind, namespace, pos)
+		use.NamespaceType = j.NamespaceType
+		node = use
+	case jsonTypeError:
+		node = NewSqlErrorNode(j.Text, pos)
+	default:
+		return nil, fmt.Errorf("未知的节点类型: %q", j.Type)
+	}
+
+	// 构造函数按节点类型设置 Kind，以 JSON 中记录的为准
+	base := node.(interface{ base() *BaseSqlNode }).base()
+	if j.Kind != "" {
+		base.Kind = j.Kind
+	}
+	base.Comments = j.Comments.toComments()
+	return node, nil
+}
+
+// toLiteral 重建字面量，数字按字面量类型解码为 int64 或 float64
+func (j *jsonNode) toLiteral(pos *SqlParserPos) (*SqlLiteral, error) {
+	valueType, err := lookupName(literalTypeNames, j.ValueType, "字面量类型")
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if len(j.Value) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(j.Value))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return nil, fmt.Errorf("无法解码字面量 %s: %w", j.Value, err)
+		}
+	}
+	if number, ok := value.(json.Number); ok {
+		if i, err := number.Int64(); err == nil && valueType != LiteralDecimal {
+			value = i
+		} else if value, err = number.Float64(); err != nil {
+			return nil, fmt.Errorf("无法解码字面量 %s: %w", j.Value, err)
+		}
+	}
+
+	literal := NewSqlLiteral(value, valueType, pos)
+	literal.TypeName = j.TypeName
+	return literal, nil
+}
+
+func (c jsonChildren) nodes() []SqlNode {
+	if c == nil {
+		return nil
+	}
+	nodes := make([]SqlNode, len(c))
+	for i, child := range c {
+		nodes[i] = child.get()
+	}
+	return nodes
+}
+
+// identifier 取出应为标识符的子节点，field 用于错误信息
+func (c *jsonChild) identifier(field string) (*SqlIdentifier, error) {
+	node := c.get()
+	if node == nil {
+		return nil, nil
+	}
+	identifier, ok := node.(*SqlIdentifier)
+	if !ok {
+		return nil, fmt.Errorf("%s 的节点类型应为 identifier，实际为 %T", field, node)
+	}
+	return identifier, nil
+}
+
+func (p *jsonPos) toPos() *SqlParserPos {
+	if p == nil {
+		return nil
+	}
+	return &SqlParserPos{
+		LineNumber:   p.Line,
+		ColumnNumber: p.Column,
+		EndLine:      p.EndLine,
+		EndColumn:    p.EndColumn,
+		StartOffset:  p.StartOffset,
+		EndOffset:    p.EndOffset,
+		Synthetic:    p.Synthetic,
+	}
+}
+
+func (c *jsonComments) toComments() *SqlComments {
+	if c == nil {
+		return nil
+	}
+	convert := func(list []jsonComment) []*SqlComment {
+		var converted []*SqlComment
+		for _, comment := range list {
+			converted = append(converted, &SqlComment{Text: comment.Text, Pos: comment.Pos.toPos()})
+		}
+		return converted
+	}
+	return &SqlComments{Leading: convert(c.Leading), Trailing: convert(c.Trailing)}
+}
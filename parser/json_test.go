@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// buildJSONTestNodes 构造覆盖所有节点类型的语法树
+func buildJSONTestNodes() map[string]SqlNode {
+	pos := &SqlParserPos{LineNumber: 1, ColumnNumber: 2, EndLine: 1, EndColumn: 9, StartOffset: 2, EndOffset: 9}
+	ident := func(names ...string) *SqlIdentifier {
+		return NewSqlIdentifier(names, pos.Clone())
+	}
+
+	describe := NewSqlDescribe(ident("db", "t"), nil, pos.Clone())
+	describe.Column = ident("c")
+	describe.Option = "EXTENDED"
+	show := NewSqlShow("TABLES", pos.Clone())
+	show.Namespace = ident("db")
+	show.Pattern = "a*"
+	use := NewSqlUse(SqlKindUse, ident("db"), pos.Clone())
+	use.NamespaceType = "DATABASE"
+
+	bucket := NewSqlTableRef(ident("t"), &SqlTemporalSpec{Type: TemporalTimestamp, Value: NewSqlLiteral("2024-01-01", LiteralString, nil)},
+		&SqlSampleSpec{Method: SampleBucket, Numerator: 1, Denominator: 4, BucketOn: ident("id")}, nil)
+
+	commented := buildCloneTestTree()
+	commented.Comments = &SqlComments{Leading: []*SqlComment{commentAt("-- 说明", 1, 0)}}
+	commented.Where.(*SqlCall).Operands[0].(*SqlIdentifier).Comments = &SqlComments{Trailing: []*SqlComment{{Text: "/* x */"}}}
+
+	synthetic := buildDialectTestTree()
+	synthetic.From = NewSqlJoin(ident("a"), ident("b"), JoinComma, nil, syntheticPos(ident("a"), ident("b")))
+
+	return map[string]SqlNode{
+		"克隆测试树":          buildCloneTestTree(),
+		"方言测试树":          buildDialectTestTree(),
+		"格式化测试树":         buildFormatTestTree(),
+		"注释":             commented,
+		"合成位置":           synthetic,
+		"小数":             NewSqlLiteral(2.0, LiteralDecimal, pos),
+		"NULL":           NewSqlLiteral(nil, LiteralNull, nil),
+		"大整数":            NewSqlLiteral(int64(1)<<62, LiteralInteger, nil),
+		"零位置":            NewSqlIdentifier([]string{"a"}, &SqlParserPos{}),
+		"EXPLAIN":        NewSqlExplain("FORMATTED", buildCloneTestTree(), pos),
+		"DESCRIBE":       describe,
+		"DESCRIBE QUERY": NewSqlDescribe(nil, buildDialectTestTree(), nil),
+		"SHOW":           show,
+		"USE":            use,
+		"SET CATALOG":    NewSqlUse(SqlKindSetCatalog, ident("hive"), nil),
+		"抽样":             bucket,
+		"错误节点":           NewSqlErrorNode("selec 1", pos),
+	}
+}
+
+// TestJSONRoundTrip 测试编码后解码得到相同的语法树
+func TestJSONRoundTrip(t *testing.T) {
+	for name, node := range buildJSONTestNodes() {
+		data, err := MarshalNode(node)
+		if err != nil {
+			t.Fatalf("%s: 编码失败: %v", name, err)
+		}
+		decoded, err := UnmarshalNode(data)
+		if err != nil {
+			t.Fatalf("%s: 解码失败: %v\n%s", name, err, data)
+		}
+		if !Equal(node, decoded, EqualOptions{}) {
+			t.Errorf("%s: 解码结果不同:\n%s\n%s", name, Unparse(node), Unparse(decoded))
+		}
+		if Unparse(node) != Unparse(decoded) {
+			t.Errorf("%s: 注释丢失: %q", name, Unparse(decoded))
+		}
+
+		// 节点的 MarshalJSON 输出不带版本号的节点对象
+		bare, err := json.Marshal(node)
+		if err != nil {
+			t.Fatalf("%s: json.Marshal 失败: %v", name, err)
+		}
+		decoded, err = UnmarshalNode(bare)
+		if err != nil || !Equal(node, decoded, EqualOptions{}) {
+			t.Errorf("%s: 不带版本号的节点对象解码失败: %v", name, err)
+		}
+	}
+}
+
+// TestJSONEncoding 测试编码格式
+func TestJSONEncoding(t *testing.T) {
+	call := NewSqlCall(NewSqlOperator("=", SqlKindEquals, SyntaxBinary),
+		[]SqlNode{writerIdent("a"), NewSqlLiteral(int64(1), LiteralInteger, nil)}, nil)
+	data, err := MarshalNode(call)
+	if err != nil {
+		t.Fatalf("编码失败: %v", err)
+	}
+	expected := `{"version":1,"node":{"type":"call","kind":"EQUALS",` +
+		`"operator":{"name":"=","kind":"EQUALS","syntax":"BINARY","leftPrec":30,"rightPrec":31},` +
+		`"operands":[{"type":"identifier","kind":"IDENTIFIER","names":["a"]},` +
+		`{"type":"literal","kind":"LITERAL","value":1,"valueType":"INTEGER"}]}}`
+	if string(data) != expected {
+		t.Errorf("编码结果 =\n%s\n期望\n%s", data, expected)
+	}
+
+	// 节点作为其他结构的字段时同样可以编码
+	wrapped, err := json.Marshal(struct {
+		Query SqlNode `json:"query"`
+	}{call})
+	if err != nil || !strings.Contains(string(wrapped), `"type":"call"`) {
+		t.Errorf("嵌套编码结果 = %s, %v", wrapped, err)
+	}
+}
+
+// TestUnmarshalNodeErrors 测试无法解码的输入
+func TestUnmarshalNodeErrors(t *testing.T) {
+	tests := map[string]string{
+		"版本":      `{"version":2,"node":{"type":"identifier","kind":"IDENTIFIER"}}`,
+		"节点类型":    `{"version":1,"node":{"type":"unknown","kind":"IDENTIFIER"}}`,
+		"字面量类型":   `{"type":"literal","kind":"LITERAL","valueType":"BLOB"}`,
+		"操作符语法":   `{"type":"call","kind":"CALL","operator":{"name":"f","kind":"CALL","syntax":"INFIX"}}`,
+		"表名不是标识符": `{"type":"tableRef","kind":"TABLE_REF","table":{"type":"literal","kind":"LITERAL","valueType":"NULL"}}`,
+		"格式错误":    `{"type":`,
+	}
+	for name, input := range tests {
+		if node, err := UnmarshalNode([]byte(input)); err == nil {
+			t.Errorf("%s: 应返回错误, 实际解码为 %#v", name, node)
+		}
+	}
+
+	node, err := UnmarshalNode([]byte(`{"version":1,"node":null}`))
+	if err != nil || node != nil {
+		t.Errorf("空节点应解码为 nil, 实际为 %#v, %v", node, err)
+	}
+}
+
+// TestNodeJSONSchema 测试 JSON Schema 覆盖所有节点类型
+func TestNodeJSONSchema(t *testing.T) {
+	var schema struct {
+		Defs map[string]struct {
+			Properties struct {
+				Type struct {
+					Enum []string `json:"enum"`
+				} `json:"type"`
+			} `json:"properties"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(NodeJSONSchema, &schema); err != nil {
+		t.Fatalf("JSON Schema 格式错误: %v", err)
+	}
+	types := map[string]bool{}
+	for _, name := range schema.Defs["node"].Properties.Type.Enum {
+		types[name] = true
+	}
+	for name, node := range buildJSONTestNodes() {
+		Inspect(node, func(n SqlNode) bool {
+			if n == nil {
+				return false
+			}
+			j, err := toJSONNode(n)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			if !types[j.Type] {
+				t.Errorf("JSON Schema 缺少节点类型 %s", j.Type)
+			}
+			return true
+		})
+	}
+}
+
+// TestParsedJSONRoundTrip 测试解析结果的编码往返
+func TestParsedJSONRoundTrip(t *testing.T) {
+	sqls := []string{
+		"select /*+ JOIN(TEE) */ t.a, -- 第一列\n  sum(t.b) as s from db.t t, u where t.id = u.id and t.s = '中文' group by t.a",
+		"select transform(arr, x -> x + 1) from t tablesample (10 percent) order by 1 desc limit 5",
+		"describe extended db.t c",
+		"explain formatted select 1.5",
+	}
+	for _, sql := range sqls {
+		result, err := ParseSQLWithAntlr(sql)
+		if err != nil {
+			t.Fatalf("解析 %q 失败: %v", sql, err)
+		}
+		data, err := MarshalNode(result.SqlNode)
+		if err != nil {
+			t.Fatalf("编码 %q 失败: %v", sql, err)
+		}
+		decoded, err := UnmarshalNode(data)
+		if err != nil {
+			t.Fatalf("解码 %q 失败: %v", sql, err)
+		}
+		if !Equal(result.SqlNode, decoded, EqualOptions{}) || Unparse(result.SqlNode) != Unparse(decoded) {
+			t.Errorf("%q 的编码往返结果不同: %s", sql, Unparse(decoded))
+		}
+	}
+}
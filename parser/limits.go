@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"context"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+	"go-job-service/parser/antlr"
+)
+
+// =============================================================================
+// 资源限制与取消
+// =============================================================================
+
+// cancelCheckInterval 每产生多少个 token 检查一次 ctx 是否已取消
+const cancelCheckInterval = 128
+
+// parseAbort 超过资源限制或被取消时用于从深层调用中退出
+// 只在 runGuarded 内部使用，不会泄漏到包外
+type parseAbort struct {
+	err error
+}
+
+// abortParse 中止当前解析
+func abortParse(err error) {
+	panic(&parseAbort{err: err})
+}
+
+// runGuarded 执行 fn，将 abortParse 引起的中止转换为错误，其他 panic 原样抛出
+func runGuarded(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			abort, ok := r.(*parseAbort)
+			if !ok {
+				panic(r)
+			}
+			err = abort.err
+		}
+	}()
+	fn()
+	return nil
+}
+
+// checkInputLength 检查输入长度
+func checkInputLength(sql string, opts ParseOptions) error {
+	if opts.MaxInputLength > 0 && len(sql) > opts.MaxInputLength {
+		return &LimitExceededError{Limit: LimitBytes, Max: opts.MaxInputLength, Actual: len(sql)}
+	}
+	return nil
+}
+
+// checkNodeCount 构建完成后检查 SqlNode 节点数
+// 构建期间 leaveNesting 已按层统计，这里补上不经过表达式和子查询的节点，如 FROM 中的表名
+func checkNodeCount(node SqlNode, opts ParseOptions) error {
+	if opts.MaxNodes <= 0 {
+		return nil
+	}
+
+	count := 0
+	Inspect(node, func(child SqlNode) bool {
+		if child == nil {
+			return false
+		}
+		count++
+		return count <= opts.MaxNodes
+	})
+	if count > opts.MaxNodes {
+		return &LimitExceededError{Limit: LimitNodes, Max: opts.MaxNodes, Actual: count}
+	}
+	return nil
+}
+
+// guardedTokenSource 包装词法分析器，统计 token 数和括号嵌套深度并检查取消状态
+// 语法分析器按需拉取 token，左括号在进入对应的规则之前产生，
+// 因此括号深度超限时在 ANTLR 深层递归之前即可中止
+type guardedTokenSource struct {
+	antlr4.TokenSource
+
+	ctx       context.Context
+	maxTokens int
+	maxDepth  int // 最大括号嵌套深度，0 表示不限制
+	produced  int // 已产生的 token 数（含空白和注释），用于控制取消检查频率
+	tokens    int // 默认通道上的 token 数
+	depth     int // 当前括号嵌套深度
+}
+
+// newGuardedTokenSource 创建带限制的 token 源
+// 只统计括号，不带括号的长 AND / + 链由 ANTLR 循环处理，不受 maxDepth 限制
+func newGuardedTokenSource(ctx context.Context, source antlr4.TokenSource, maxTokens, maxDepth int) *guardedTokenSource {
+	return &guardedTokenSource{
+		TokenSource: source,
+		ctx:         ctx,
+		maxTokens:   maxTokens,
+		maxDepth:    maxDepth,
+	}
+}
+
+// NextToken 实现 TokenSource 接口
+func (s *guardedTokenSource) NextToken() antlr4.Token {
+	s.produced++
+	if s.produced%cancelCheckInterval == 0 {
+		if err := s.ctx.Err(); err != nil {
+			abortParse(err)
+		}
+	}
+
+	token := s.TokenSource.NextToken()
+	if token.GetChannel() == antlr4.TokenDefaultChannel && token.GetTokenType() != antlr4.TokenEOF {
+		s.tokens++
+		if s.maxTokens > 0 && s.tokens > s.maxTokens {
+			abortParse(&LimitExceededError{
+				Limit:  LimitTokens,
+				Max:    s.maxTokens,
+				Actual: s.tokens,
+				Line:   token.GetLine(),
+				Column: token.GetColumn(),
+			})
+		}
+	}
+
+	switch token.GetTokenType() {
+	case antlr.SqlBaseLexerLEFT_PAREN:
+		s.depth++
+		if s.maxDepth > 0 && s.depth > s.maxDepth {
+			abortParse(&LimitExceededError{
+				Limit:  LimitDepth,
+				Max:    s.maxDepth,
+				Actual: s.depth,
+				Line:   token.GetLine(),
+				Column: token.GetColumn(),
+			})
+		}
+	case antlr.SqlBaseLexerRIGHT_PAREN:
+		s.depth--
+	}
+	return token
+}
+
+// cancelCheckListener 语法分析期间检查取消状态
+// token 在预测时可能已全部读入，之后的解析不再经过 guardedTokenSource，
+// 因此在进入规则时每 cancelCheckInterval 次检查一次 ctx
+type cancelCheckListener struct {
+	antlr4.BaseParseTreeListener
+
+	ctx   context.Context
+	rules int // 已进入的规则数
+}
+
+// EnterEveryRule 实现 ParseTreeListener 接口
+func (l *cancelCheckListener) EnterEveryRule(_ antlr4.ParserRuleContext) {
+	l.rules++
+	if l.rules%cancelCheckInterval == 0 {
+		if err := l.ctx.Err(); err != nil {
+			abortParse(err)
+		}
+	}
+}
+
+// =============================================================================
+// Visitor 递归保护
+// =============================================================================
+
+// enterNesting 进入一层表达式或子查询，检查递归深度和取消状态
+// 左深的二元运算链每个运算符都会递归一层，因此与括号嵌套深度分开限制
+// 与 leaveNesting 成对使用，result 为调用方的命名返回值：
+//
+//	v.enterNesting(ctx)
+//	defer v.leaveNesting(ctx, &result)
+func (v *SqlNodeBuilderVisitor) enterNesting(ctx antlr4.ParserRuleContext) {
+	v.depth++
+	if v.maxRecursion > 0 && v.depth > v.maxRecursion {
+		err := &LimitExceededError{Limit: LimitRecursion, Max: v.maxRecursion, Actual: v.depth}
+		if start := ctx.GetStart(); start != nil {
+			err.Line = start.GetLine()
+			err.Column = start.GetColumn()
+		}
+		abortParse(err)
+	}
+	if v.ctx != nil {
+		if err := v.ctx.Err(); err != nil {
+			abortParse(err)
+		}
+	}
+}
+
+// leaveNesting 离开一层表达式或子查询，统计这一层新构建的节点数
+// 已统计过的子树直接跳过，整棵树的统计是线性的；超过 maxNodes 时立即中止，不必等到整棵树构建完成
+func (v *SqlNodeBuilderVisitor) leaveNesting(ctx antlr4.ParserRuleContext, result *interface{}) {
+	v.depth--
+	if v.maxNodes <= 0 {
+		return
+	}
+	node, ok := (*result).(SqlNode)
+	if !ok || node == nil {
+		return
+	}
+
+	if v.seenNodes == nil {
+		v.seenNodes = make(map[SqlNode]struct{})
+	}
+	Inspect(node, func(child SqlNode) bool {
+		if child == nil {
+			return false
+		}
+		if _, seen := v.seenNodes[child]; seen {
+			return false
+		}
+		v.seenNodes[child] = struct{}{}
+		return true
+	})
+	if len(v.seenNodes) > v.maxNodes {
+		err := &LimitExceededError{Limit: LimitNodes, Max: v.maxNodes, Actual: len(v.seenNodes)}
+		if start := ctx.GetStart(); start != nil {
+			err.Line = start.GetLine()
+			err.Column = start.GetColumn()
+		}
+		abortParse(err)
+	}
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParseLimits 测试各类资源限制
+func TestParseLimits(t *testing.T) {
+	inList := "select t.a from t where t.a in (" + strings.TrimSuffix(strings.Repeat("1, ", 1000), ", ") + ")"
+	deepExpr := "select " + strings.TrimSuffix(strings.Repeat("t.a + ", 200), " + ") + " from t"
+	nestedParens := "select " + strings.Repeat("(", 50) + "1" + strings.Repeat(")", 50) + " from t"
+
+	testCases := []struct {
+		name  string
+		sql   string
+		opts  ParseOptions
+		limit LimitKind
+	}{
+		{name: "字节数", sql: inList, opts: ParseOptions{MaxInputLength: 100}, limit: LimitBytes},
+		{name: "token 数", sql: inList, opts: ParseOptions{MaxTokens: 500}, limit: LimitTokens},
+		{name: "括号嵌套深度", sql: nestedParens, opts: ParseOptions{MaxNestingDepth: 20}, limit: LimitDepth},
+		{name: "递归深度", sql: deepExpr, opts: ParseOptions{MaxRecursionDepth: 50}, limit: LimitRecursion},
+		{name: "节点数", sql: inList, opts: ParseOptions{MaxNodes: 100}, limit: LimitNodes},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseWithOptions(tc.sql, tc.opts)
+			var limitErr *LimitExceededError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("期望 *LimitExceededError，实际为 %v", err)
+			}
+			if limitErr.Limit != tc.limit {
+				t.Errorf("Limit = %s，期望 %s", limitErr.Limit, tc.limit)
+			}
+			if limitErr.Actual <= limitErr.Max {
+				t.Errorf("Actual = %d 应大于 Max = %d", limitErr.Actual, limitErr.Max)
+			}
+			if result != nil {
+				t.Errorf("超过限制时不应返回结果")
+			}
+		})
+	}
+}
+
+// TestParseWithinLimits 测试未超过限制时正常解析
+func TestParseWithinLimits(t *testing.T) {
+	opts := ParseOptions{MaxInputLength: 1000, MaxTokens: 100, MaxNestingDepth: 100, MaxRecursionDepth: 100, MaxNodes: 100}
+	if _, err := ParseWithOptions("select t.a, t.b from t where t.a = 1", opts); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+}
+
+// TestParseFlatChainWithinNestingDepth 测试不带括号的长运算链不受括号嵌套深度限制
+func TestParseFlatChainWithinNestingDepth(t *testing.T) {
+	sums := "select " + strings.TrimSuffix(strings.Repeat("t.a + ", 200), " + ") + " from t"
+	ands := "select t.a from t where " + strings.TrimSuffix(strings.Repeat("t.a = 1 and ", 200), " and ")
+	for _, sql := range []string{sums, ands} {
+		if _, err := ParseWithOptions(sql, ParseOptions{MaxNestingDepth: 50}); err != nil {
+			t.Errorf("解析失败: %v", err)
+		}
+	}
+}
+
+// TestParseWithContextCancelled 测试取消后返回 ctx.Err()
+func TestParseWithContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := ParseWithContext(ctx, "select t.a from t", ParseOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望 context.Canceled，实际为 %v", err)
+	}
+}
+
+// TestParseCancelledAfterTokensRead 测试 token 已全部读入后，语法分析期间仍会检查取消状态
+func TestParseCancelledAfterTokensRead(t *testing.T) {
+	sql := "select " + strings.TrimSuffix(strings.Repeat("t.a + 1, ", 200), ", ") + " from t"
+	ctx, cancel := context.WithCancel(context.Background())
+	listener := NewAntlrErrorListener()
+	listener.sql = sql
+	inst := acquireParser(ctx, sql, listener, ParseOptions{})
+	defer releaseParser(inst, false)
+
+	inst.stream.Fill()
+	cancel()
+	err := runGuarded(func() { inst.parseSingleStatement(listener) })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("期望 context.Canceled，实际为 %v", err)
+	}
+}
+
+// TestParseNodeLimitDuringVisit 测试构建 SqlNode 期间超过节点数立即中止，并给出超限位置
+func TestParseNodeLimitDuringVisit(t *testing.T) {
+	sql := "select t.a from t where t.a in (" + strings.TrimSuffix(strings.Repeat("1, ", 1000), ", ") + ")"
+	_, err := ParseWithOptions(sql, ParseOptions{MaxNodes: 100})
+	var limitErr *LimitExceededError
+	if !errors.As(err, &limitErr) || limitErr.Limit != LimitNodes {
+		t.Fatalf("期望节点数超限，实际为 %v", err)
+	}
+	if limitErr.Actual >= 200 {
+		t.Errorf("Actual = %d，应在 IN 列表构建完成前中止", limitErr.Actual)
+	}
+	if limitErr.Line != 1 || limitErr.Column <= strings.Index(sql, "(") {
+		t.Errorf("超限位置 = %d:%d，期望在 IN 列表内", limitErr.Line, limitErr.Column)
+	}
+}
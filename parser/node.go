@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -52,31 +51,70 @@ const (
 	SqlKindCall        SqlKind = "CALL"
 	
 	// Operators
-	SqlKindPlus        SqlKind = "PLUS"
-	SqlKindMinus       SqlKind = "MINUS"
-	SqlKindTimes       SqlKind = "TIMES"
-	SqlKindDivide      SqlKind = "DIVIDE"
-	SqlKindEquals      SqlKind = "EQUALS"
-	SqlKindNotEquals   SqlKind = "NOT_EQUALS"
-	SqlKindGreaterThan SqlKind = "GREATER_THAN"
-	SqlKindLessThan    SqlKind = "LESS_THAN"
-	SqlKindAnd         SqlKind = "AND"
-	SqlKindOr          SqlKind = "OR"
-	SqlKindNot         SqlKind = "NOT"
+	SqlKindPlus               SqlKind = "PLUS"
+	SqlKindMinus              SqlKind = "MINUS"
+	SqlKindTimes              SqlKind = "TIMES"
+	SqlKindDivide             SqlKind = "DIVIDE"
+	SqlKindEquals             SqlKind = "EQUALS"
+	SqlKindNotEquals          SqlKind = "NOT_EQUALS"
+	SqlKindGreaterThan        SqlKind = "GREATER_THAN"
+	SqlKindGreaterThanOrEqual SqlKind = "GREATER_THAN_OR_EQUAL"
+	SqlKindLessThan           SqlKind = "LESS_THAN"
+	SqlKindLessThanOrEqual    SqlKind = "LESS_THAN_OR_EQUAL"
+	SqlKindAnd                SqlKind = "AND"
+	SqlKindOr                 SqlKind = "OR"
+	SqlKindNot                SqlKind = "NOT"
+	SqlKindIn                 SqlKind = "IN"
+	SqlKindNotIn              SqlKind = "NOT_IN"
+	SqlKindCast               SqlKind = "CAST" // CAST(x AS type) / TRY_CAST(x AS type)
+	
+	// Complex types
+	SqlKindLambda      SqlKind = "LAMBDA"
+	SqlKindItem        SqlKind = "ITEM"   // 元素访问: arr[0], m['key']
+	SqlKindDot         SqlKind = "DOT"    // 字段访问: s.field
+	SqlKindRow         SqlKind = "ROW"    // 行构造: (a, b)
+	SqlKindStruct      SqlKind = "STRUCT" // STRUCT(a, b AS c)
+	
+	// Utility statements
+	SqlKindExplain     SqlKind = "EXPLAIN"
+	SqlKindDescribe    SqlKind = "DESCRIBE"
+	SqlKindShow        SqlKind = "SHOW"
+	SqlKindUse         SqlKind = "USE"
+	SqlKindSetCatalog  SqlKind = "SET_CATALOG"
+	
+	// Table references
+	SqlKindTableRef    SqlKind = "TABLE_REF" // 带时间旅行/采样的表引用
 	
 	// Other
 	SqlKindJoin        SqlKind = "JOIN"
 	SqlKindOrderBy     SqlKind = "ORDER_BY"
+	SqlKindDescending  SqlKind = "DESCENDING"  // 排序项: x DESC
+	SqlKindNullsFirst  SqlKind = "NULLS_FIRST" // 排序项: x NULLS FIRST
+	SqlKindNullsLast   SqlKind = "NULLS_LAST"  // 排序项: x NULLS LAST
 	SqlKindAs          SqlKind = "AS"
+	SqlKindError       SqlKind = "ERROR" // 恢复模式下的语法错误区域
 	SqlKindOther       SqlKind = "OTHER"
 )
 
 // SqlParserPos 解析位置信息（类似 Calcite 的 SqlParserPos）
+// 行号从 1 开始，列号按字符从 0 开始，结束位置不含；LineNumber 为 0 表示没有位置信息
 type SqlParserPos struct {
 	LineNumber   int
 	ColumnNumber int
 	EndLine      int
 	EndColumn    int
+	StartOffset  int  // 起始字节偏移，相对于 SQLParserResult.SQL
+	EndOffset    int  // 结束字节偏移（不含）
+	Synthetic    bool // 节点由解析器或改写合成，源码中没有对应的文本，位置为其子节点覆盖的范围
+}
+
+// Clone 复制位置信息，nil 时返回 nil
+func (p *SqlParserPos) Clone() *SqlParserPos {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
 }
 
 // =============================================================================
@@ -85,8 +123,9 @@ type SqlParserPos struct {
 
 // BaseSqlNode 提供 SqlNode 的基础实现
 type BaseSqlNode struct {
-	Kind SqlKind
-	Pos  *SqlParserPos
+	Kind     SqlKind
+	Pos      *SqlParserPos
+	Comments *SqlComments // 附着在节点上的普通注释，没有注释时为 nil
 }
 
 func (n *BaseSqlNode) GetKind() SqlKind {
@@ -97,6 +136,56 @@ func (n *BaseSqlNode) GetPos() *SqlParserPos {
 	return n.Pos
 }
 
+// GetComments 获取附着在节点上的注释，没有注释时返回 nil
+func (n *BaseSqlNode) GetComments() *SqlComments {
+	return n.Comments
+}
+
+// cloneBase 复制节点类型、位置信息和注释
+func (n *BaseSqlNode) cloneBase() BaseSqlNode {
+	return BaseSqlNode{Kind: n.Kind, Pos: n.Pos.Clone(), Comments: n.Comments.Clone()}
+}
+
+// setPos 设置位置信息，供 CloneWithPos 使用
+func (n *BaseSqlNode) setPos(pos *SqlParserPos) {
+	n.Pos = pos
+}
+
+// base 返回嵌入的 BaseSqlNode，供附着注释使用
+func (n *BaseSqlNode) base() *BaseSqlNode {
+	return n
+}
+
+// CloneWithPos 深拷贝节点，并把根节点的位置设置为 pos（子节点保留各自的位置）
+// 类似 Calcite 的 SqlNode.clone(pos)
+func CloneWithPos(node SqlNode, pos *SqlParserPos) SqlNode {
+	clone := cloneNode(node)
+	if positioned, ok := clone.(interface{ setPos(*SqlParserPos) }); ok {
+		positioned.setPos(pos)
+	}
+	return clone
+}
+
+// cloneNode 克隆可能为 nil 的节点
+func cloneNode(node SqlNode) SqlNode {
+	if node == nil {
+		return nil
+	}
+	return node.Clone()
+}
+
+// cloneNodeList 克隆节点列表，nil 列表保持为 nil
+func cloneNodeList(nodes []SqlNode) []SqlNode {
+	if nodes == nil {
+		return nil
+	}
+	cloned := make([]SqlNode, len(nodes))
+	for i, node := range nodes {
+		cloned[i] = cloneNode(node)
+	}
+	return cloned
+}
+
 // =============================================================================
 // SqlIdentifier - 标识符节点
 // =============================================================================
@@ -126,7 +215,7 @@ func (n *SqlIdentifier) ToString() string {
 func (n *SqlIdentifier) Clone() SqlNode {
 	names := make([]string, len(n.Names))
 	copy(names, n.Names)
-	return NewSqlIdentifier(names, n.Pos)
+	return &SqlIdentifier{BaseSqlNode: n.cloneBase(), Names: names}
 }
 
 func (n *SqlIdentifier) GetSimple() string {
@@ -160,6 +249,8 @@ const (
 	LiteralDate
 	LiteralTime
 	LiteralTimestamp
+	LiteralInterval // Value 为数量的文本，TypeName 为单位，如 DAY
+	LiteralSymbol   // 原样输出的关键字，如 CAST 的目标类型，类似 Calcite 的 SqlLiteral.createSymbol
 )
 
 func NewSqlLiteral(value interface{}, valueType SqlLiteralType, pos *SqlParserPos) *SqlLiteral {
@@ -175,14 +266,17 @@ func (n *SqlLiteral) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlLiteral) ToString() string {
-	if n.Value == nil {
-		return "NULL"
-	}
-	return fmt.Sprintf("%v", n.Value)
+	return Unparse(n)
 }
 
 func (n *SqlLiteral) Clone() SqlNode {
-	return NewSqlLiteral(n.Value, n.ValueType, n.Pos)
+	// Value 只会是 nil、bool、int64、float64 或 string，直接复制即可
+	return &SqlLiteral{
+		BaseSqlNode: n.cloneBase(),
+		Value:       n.Value,
+		ValueType:   n.ValueType,
+		TypeName:    n.TypeName,
+	}
 }
 
 // =============================================================================
@@ -214,18 +308,15 @@ func (n *SqlCall) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlCall) ToString() string {
-	if n.Operator == nil {
-		return "UNKNOWN"
-	}
-	return n.Operator.Format(n.Operands)
+	return Unparse(n)
 }
 
 func (n *SqlCall) Clone() SqlNode {
-	operands := make([]SqlNode, len(n.Operands))
-	for i, op := range n.Operands {
-		operands[i] = op.Clone()
+	return &SqlCall{
+		BaseSqlNode: n.cloneBase(),
+		Operator:    n.Operator.Clone(),
+		Operands:    cloneNodeList(n.Operands),
 	}
-	return NewSqlCall(n.Operator, operands, n.Pos)
 }
 
 // SqlOperator 操作符信息
@@ -237,6 +328,29 @@ type SqlOperator struct {
 	RightPrec int // 右结合优先级
 }
 
+// NewSqlOperator 创建操作符，并按名字、类型和语法填充左右优先级
+func NewSqlOperator(name string, kind SqlKind, syntax SqlSyntax) *SqlOperator {
+	leftPrec, rightPrec := defaultPrecedence(name, kind, syntax)
+	return &SqlOperator{Name: name, Kind: kind, Syntax: syntax, LeftPrec: leftPrec, RightPrec: rightPrec}
+}
+
+// Precedence 返回左右优先级，未设置时（如直接构造的操作符）按名字、类型和语法推断
+func (op *SqlOperator) Precedence() (int, int) {
+	if op.LeftPrec == 0 && op.RightPrec == 0 {
+		return defaultPrecedence(op.Name, op.Kind, op.Syntax)
+	}
+	return op.LeftPrec, op.RightPrec
+}
+
+// Clone 复制操作符，nil 时返回 nil
+func (op *SqlOperator) Clone() *SqlOperator {
+	if op == nil {
+		return nil
+	}
+	c := *op
+	return &c
+}
+
 type SqlSyntax int
 
 const (
@@ -247,24 +361,9 @@ const (
 	SyntaxSpecial                        // 特殊语法
 )
 
+// Format 输出以 operands 为操作数的调用，操作数按优先级加括号
 func (op *SqlOperator) Format(operands []SqlNode) string {
-	switch op.Syntax {
-	case SyntaxFunction:
-		args := make([]string, len(operands))
-		for i, arg := range operands {
-			args[i] = arg.ToString()
-		}
-		return fmt.Sprintf("%s(%s)", op.Name, strings.Join(args, ", "))
-	case SyntaxBinary:
-		if len(operands) == 2 {
-			return fmt.Sprintf("%s %s %s", operands[0].ToString(), op.Name, operands[1].ToString())
-		}
-	case SyntaxPrefix:
-		if len(operands) == 1 {
-			return fmt.Sprintf("%s %s", op.Name, operands[0].ToString())
-		}
-	}
-	return op.Name
+	return Unparse(NewSqlCall(op, operands, nil))
 }
 
 // =============================================================================
@@ -292,31 +391,15 @@ func (n *SqlHint) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlHint) ToString() string {
-	var sb strings.Builder
-	sb.WriteString("/*+ ")
-	sb.WriteString(n.Name)
-	
-	if len(n.Parameters) > 0 {
-		sb.WriteString("(")
-		for i, param := range n.Parameters {
-			if i > 0 {
-				sb.WriteString(", ")
-			}
-			sb.WriteString(param.ToString())
-		}
-		sb.WriteString(")")
-	}
-	
-	sb.WriteString(" */")
-	return sb.String()
+	return Unparse(n)
 }
 
 func (n *SqlHint) Clone() SqlNode {
-	params := make([]SqlNode, len(n.Parameters))
-	for i, p := range n.Parameters {
-		params[i] = p.Clone()
+	return &SqlHint{
+		BaseSqlNode: n.cloneBase(),
+		Name:        n.Name,
+		Parameters:  cloneNodeList(n.Parameters),
 	}
-	return NewSqlHint(n.Name, params, n.Pos)
 }
 
 // =============================================================================
@@ -357,75 +440,29 @@ func (n *SqlSelect) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlSelect) ToString() string {
-	var sb strings.Builder
-	sb.WriteString("SELECT ")
-	
-	// 添加 HINT
-	if len(n.Hints) > 0 {
-		for _, hint := range n.Hints {
-			sb.WriteString(hint.ToString())
-			sb.WriteString(" ")
-		}
-	}
-	
-	if len(n.KeywordList) > 0 {
-		sb.WriteString(strings.Join(n.KeywordList, " "))
-		sb.WriteString(" ")
-	}
-	
-	for i, item := range n.SelectList {
-		if i > 0 {
-			sb.WriteString(", ")
-		}
-		sb.WriteString(item.ToString())
-	}
-	
-	if n.From != nil {
-		sb.WriteString(" FROM ")
-		sb.WriteString(n.From.ToString())
-	}
-	
-	if n.Where != nil {
-		sb.WriteString(" WHERE ")
-		sb.WriteString(n.Where.ToString())
-	}
-	
-	if len(n.GroupBy) > 0 {
-		sb.WriteString(" GROUP BY ")
-		for i, g := range n.GroupBy {
-			if i > 0 {
-				sb.WriteString(", ")
-			}
-			sb.WriteString(g.ToString())
-		}
-	}
-	
-	if n.Having != nil {
-		sb.WriteString(" HAVING ")
-		sb.WriteString(n.Having.ToString())
-	}
-	
-	if len(n.OrderBy) > 0 {
-		sb.WriteString(" ORDER BY ")
-		for i, o := range n.OrderBy {
-			if i > 0 {
-				sb.WriteString(", ")
-			}
-			sb.WriteString(o.ToString())
-		}
-	}
-	
-	if n.Fetch != nil {
-		sb.WriteString(" LIMIT ")
-		sb.WriteString(n.Fetch.ToString())
-	}
-	
-	return sb.String()
+	return Unparse(n)
 }
 
 func (n *SqlSelect) Clone() SqlNode {
-	// 实现克隆逻辑
-	return n
+	clone := NewSqlSelect(nil)
+	clone.BaseSqlNode = n.cloneBase()
+	clone.Hints = make([]*SqlHint, len(n.Hints))
+	for i, hint := range n.Hints {
+		if hint != nil {
+			clone.Hints[i] = hint.Clone().(*SqlHint)
+		}
+	}
+	clone.KeywordList = append([]string{}, n.KeywordList...)
+	clone.SelectList = cloneNodeList(n.SelectList)
+	clone.From = cloneNode(n.From)
+	clone.Where = cloneNode(n.Where)
+	clone.GroupBy = cloneNodeList(n.GroupBy)
+	clone.Having = cloneNode(n.Having)
+	clone.WindowDecls = cloneNodeList(n.WindowDecls)
+	clone.OrderBy = cloneNodeList(n.OrderBy)
+	clone.Offset = cloneNode(n.Offset)
+	clone.Fetch = cloneNode(n.Fetch)
+	return clone
 }
 
 // =============================================================================
@@ -450,6 +487,7 @@ const (
 	JoinRight JoinType = "RIGHT"
 	JoinFull  JoinType = "FULL"
 	JoinCross JoinType = "CROSS"
+	JoinComma JoinType = "COMMA" // FROM a, b 形式的隐式连接，没有条件
 )
 
 func NewSqlJoin(left, right SqlNode, joinType JoinType, condition SqlNode, pos *SqlParserPos) *SqlJoin {
@@ -467,21 +505,18 @@ func (n *SqlJoin) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlJoin) ToString() string {
-	var sb strings.Builder
-	sb.WriteString(n.Left.ToString())
-	sb.WriteString(" ")
-	sb.WriteString(string(n.JoinType))
-	sb.WriteString(" JOIN ")
-	sb.WriteString(n.Right.ToString())
-	if n.Condition != nil {
-		sb.WriteString(" ON ")
-		sb.WriteString(n.Condition.ToString())
-	}
-	return sb.String()
+	return Unparse(n)
 }
 
 func (n *SqlJoin) Clone() SqlNode {
-	return NewSqlJoin(n.Left.Clone(), n.Right.Clone(), n.JoinType, n.Condition.Clone(), n.Pos)
+	return &SqlJoin{
+		BaseSqlNode: n.cloneBase(),
+		Left:        cloneNode(n.Left),
+		Right:       cloneNode(n.Right),
+		JoinType:    n.JoinType,
+		Condition:   cloneNode(n.Condition),
+		Using:       cloneNodeList(n.Using),
+	}
 }
 
 // =============================================================================
@@ -508,14 +543,11 @@ func (n *SqlBasicCall) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlBasicCall) ToString() string {
-	if n.Alias != "" {
-		return fmt.Sprintf("%s AS %s", n.Operand.ToString(), n.Alias)
-	}
-	return n.Operand.ToString()
+	return Unparse(n)
 }
 
 func (n *SqlBasicCall) Clone() SqlNode {
-	return NewSqlBasicCall(n.Operand.Clone(), n.Alias, n.Pos)
+	return &SqlBasicCall{BaseSqlNode: n.cloneBase(), Operand: cloneNode(n.Operand), Alias: n.Alias}
 }
 
 // =============================================================================
@@ -551,19 +583,356 @@ func (n *SqlNodeList) Accept(visitor SqlNodeVisitor) (interface{}, error) {
 }
 
 func (n *SqlNodeList) ToString() string {
-	items := make([]string, len(n.List))
-	for i, item := range n.List {
-		items[i] = item.ToString()
-	}
-	return strings.Join(items, ", ")
+	return Unparse(n)
 }
 
 func (n *SqlNodeList) Clone() SqlNode {
-	list := make([]SqlNode, len(n.List))
-	for i, item := range n.List {
-		list[i] = item.Clone()
+	return &SqlNodeList{BaseSqlNode: n.cloneBase(), List: cloneNodeList(n.List)}
+}
+
+// =============================================================================
+// SqlLambda - Lambda 表达式
+// =============================================================================
+
+// SqlLambda 表示 lambda 表达式，如 x -> x + 1 或 (k, v) -> k + v
+// 类似 Calcite 的 SqlLambda
+// 参数只在 Body 内可见，不是表中的列
+type SqlLambda struct {
+	BaseSqlNode
+	Parameters []*SqlIdentifier // 参数列表
+	Body       SqlNode          // 函数体
+}
+
+func NewSqlLambda(parameters []*SqlIdentifier, body SqlNode, pos *SqlParserPos) *SqlLambda {
+	return &SqlLambda{
+		BaseSqlNode: BaseSqlNode{Kind: SqlKindLambda, Pos: pos},
+		Parameters:  parameters,
+		Body:        body,
+	}
+}
+
+func (n *SqlLambda) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitLambda(n)
+}
+
+func (n *SqlLambda) ToString() string {
+	return Unparse(n)
+}
+
+func (n *SqlLambda) Clone() SqlNode {
+	params := make([]*SqlIdentifier, len(n.Parameters))
+	for i, p := range n.Parameters {
+		if p != nil {
+			params[i] = p.Clone().(*SqlIdentifier)
+		}
 	}
-	return NewSqlNodeList(list, n.Pos)
+	return &SqlLambda{BaseSqlNode: n.cloneBase(), Parameters: params, Body: cloneNode(n.Body)}
+}
+
+// ParameterNames 返回参数名集合
+func (n *SqlLambda) ParameterNames() map[string]bool {
+	names := make(map[string]bool, len(n.Parameters))
+	for _, p := range n.Parameters {
+		if p != nil {
+			names[p.GetSimple()] = true
+		}
+	}
+	return names
+}
+
+// =============================================================================
+// SqlTableRef - 表引用（时间旅行 / 采样）
+// =============================================================================
+
+// SqlTableRef 表示带有时间旅行或 TABLESAMPLE 子句的表引用
+// 如 t VERSION AS OF 3 TABLESAMPLE (10 PERCENT) REPEATABLE (42)
+// 普通表引用仍使用 SqlIdentifier 表示
+type SqlTableRef struct {
+	BaseSqlNode
+	Name     *SqlIdentifier   // 表名
+	Temporal *SqlTemporalSpec // 时间旅行子句，可为 nil
+	Sample   *SqlSampleSpec   // 采样子句，可为 nil
+}
+
+// SqlTemporalType 时间旅行类型
+type SqlTemporalType string
+
+const (
+	TemporalVersion   SqlTemporalType = "VERSION"   // VERSION AS OF / SYSTEM_VERSION AS OF
+	TemporalTimestamp SqlTemporalType = "TIMESTAMP" // TIMESTAMP AS OF / SYSTEM_TIME AS OF
+)
+
+// SqlTemporalSpec 时间旅行子句
+type SqlTemporalSpec struct {
+	Type  SqlTemporalType
+	Value SqlNode // 版本号或时间戳表达式
+}
+
+// SqlSampleMethod 采样方式
+type SqlSampleMethod string
+
+const (
+	SamplePercent SqlSampleMethod = "PERCENT" // TABLESAMPLE (10 PERCENT)
+	SampleRows    SqlSampleMethod = "ROWS"    // TABLESAMPLE (100 ROWS)
+	SampleBucket  SqlSampleMethod = "BUCKET"  // TABLESAMPLE (BUCKET 1 OUT OF 4 ON id)
+	SampleBytes   SqlSampleMethod = "BYTES"   // TABLESAMPLE (100M)
+)
+
+// SqlSampleSpec TABLESAMPLE 子句
+type SqlSampleSpec struct {
+	Method      SqlSampleMethod
+	Value       SqlNode // 百分比、行数或字节数，BUCKET 时为 nil
+	Numerator   int64   // BUCKET x OUT OF y 中的 x
+	Denominator int64   // BUCKET x OUT OF y 中的 y
+	BucketOn    SqlNode // BUCKET ... ON 后的列或函数，可为 nil
+	Seed        *int64  // REPEATABLE (seed)，可为 nil
+}
+
+func NewSqlTableRef(name *SqlIdentifier, temporal *SqlTemporalSpec, sample *SqlSampleSpec, pos *SqlParserPos) *SqlTableRef {
+	return &SqlTableRef{
+		BaseSqlNode: BaseSqlNode{Kind: SqlKindTableRef, Pos: pos},
+		Name:        name,
+		Temporal:    temporal,
+		Sample:      sample,
+	}
+}
+
+func (n *SqlTableRef) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitTableRef(n)
+}
+
+func (n *SqlTableRef) ToString() string {
+	return Unparse(n)
+}
+
+func (n *SqlTableRef) Clone() SqlNode {
+	var name *SqlIdentifier
+	if n.Name != nil {
+		name = n.Name.Clone().(*SqlIdentifier)
+	}
+	return &SqlTableRef{
+		BaseSqlNode: n.cloneBase(),
+		Name:        name,
+		Temporal:    n.Temporal.Clone(),
+		Sample:      n.Sample.Clone(),
+	}
+}
+
+// IsTimeTravel 是否读取历史快照
+func (n *SqlTableRef) IsTimeTravel() bool {
+	return n.Temporal != nil
+}
+
+// IsSampled 是否只读取采样数据
+func (n *SqlTableRef) IsSampled() bool {
+	return n.Sample != nil
+}
+
+func (s *SqlTemporalSpec) ToString() string {
+	w := NewSqlWriter(SqlWriterConfig{})
+	w.writeTemporal(s)
+	return w.String()
+}
+
+func (s *SqlTemporalSpec) Clone() *SqlTemporalSpec {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Value != nil {
+		c.Value = s.Value.Clone()
+	}
+	return &c
+}
+
+func (s *SqlSampleSpec) ToString() string {
+	w := NewSqlWriter(SqlWriterConfig{})
+	w.writeSample(s)
+	return w.String()
+}
+
+func (s *SqlSampleSpec) Clone() *SqlSampleSpec {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	if s.Value != nil {
+		c.Value = s.Value.Clone()
+	}
+	if s.BucketOn != nil {
+		c.BucketOn = s.BucketOn.Clone()
+	}
+	if s.Seed != nil {
+		seed := *s.Seed
+		c.Seed = &seed
+	}
+	return &c
+}
+
+// =============================================================================
+// 工具语句 - EXPLAIN / DESCRIBE / SHOW / USE
+// =============================================================================
+
+// SqlExplain 表示 EXPLAIN [mode] statement
+type SqlExplain struct {
+	BaseSqlNode
+	Mode      string  // LOGICAL / FORMATTED / EXTENDED / CODEGEN / COST，默认为空
+	Statement SqlNode // 被解释的语句
+}
+
+func NewSqlExplain(mode string, statement SqlNode, pos *SqlParserPos) *SqlExplain {
+	return &SqlExplain{
+		BaseSqlNode: BaseSqlNode{Kind: SqlKindExplain, Pos: pos},
+		Mode:        mode,
+		Statement:   statement,
+	}
+}
+
+func (n *SqlExplain) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitExplain(n)
+}
+
+func (n *SqlExplain) ToString() string {
+	return Unparse(n)
+}
+
+func (n *SqlExplain) Clone() SqlNode {
+	return &SqlExplain{BaseSqlNode: n.cloneBase(), Mode: n.Mode, Statement: cloneNode(n.Statement)}
+}
+
+// SqlDescribe 表示 DESCRIBE [TABLE] [EXTENDED | FORMATTED] table [column]
+// 或 DESCRIBE QUERY query，两者只会设置其一
+type SqlDescribe struct {
+	BaseSqlNode
+	Table  *SqlIdentifier // 被描述的表
+	Column *SqlIdentifier // 被描述的列，可为 nil
+	Option string         // EXTENDED / FORMATTED，默认为空
+	Query  SqlNode        // DESCRIBE QUERY 的查询
+}
+
+func NewSqlDescribe(table *SqlIdentifier, query SqlNode, pos *SqlParserPos) *SqlDescribe {
+	return &SqlDescribe{
+		BaseSqlNode: BaseSqlNode{Kind: SqlKindDescribe, Pos: pos},
+		Table:       table,
+		Query:       query,
+	}
+}
+
+func (n *SqlDescribe) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitDescribe(n)
+}
+
+func (n *SqlDescribe) ToString() string {
+	return Unparse(n)
+}
+
+func (n *SqlDescribe) Clone() SqlNode {
+	clone := &SqlDescribe{BaseSqlNode: n.cloneBase(), Option: n.Option}
+	if n.Table != nil {
+		clone.Table = n.Table.Clone().(*SqlIdentifier)
+	}
+	if n.Column != nil {
+		clone.Column = n.Column.Clone().(*SqlIdentifier)
+	}
+	clone.Query = cloneNode(n.Query)
+	return clone
+}
+
+// SqlShow 表示 SHOW 系列语句
+// 如 SHOW TABLES IN db LIKE 'a*'、SHOW COLUMNS IN t、SHOW CREATE TABLE t
+type SqlShow struct {
+	BaseSqlNode
+	Target    string         // 展示目标，如 TABLES、DATABASES、COLUMNS、CREATE TABLE
+	Object    *SqlIdentifier // 目标对象（表），可为 nil
+	Namespace *SqlIdentifier // FROM/IN 后的命名空间，可为 nil
+	Pattern   string         // LIKE 模式，默认为空
+}
+
+func NewSqlShow(target string, pos *SqlParserPos) *SqlShow {
+	return &SqlShow{
+		BaseSqlNode: BaseSqlNode{Kind: SqlKindShow, Pos: pos},
+		Target:      target,
+	}
+}
+
+func (n *SqlShow) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitShow(n)
+}
+
+func (n *SqlShow) ToString() string {
+	return Unparse(n)
+}
+
+func (n *SqlShow) Clone() SqlNode {
+	clone := &SqlShow{BaseSqlNode: n.cloneBase(), Target: n.Target, Pattern: n.Pattern}
+	if n.Object != nil {
+		clone.Object = n.Object.Clone().(*SqlIdentifier)
+	}
+	if n.Namespace != nil {
+		clone.Namespace = n.Namespace.Clone().(*SqlIdentifier)
+	}
+	return clone
+}
+
+// SqlUse 表示 USE [NAMESPACE | DATABASE | SCHEMA] ns 或 SET CATALOG catalog
+// 两者的 Kind 分别为 SqlKindUse 和 SqlKindSetCatalog
+type SqlUse struct {
+	BaseSqlNode
+	NamespaceType string         // NAMESPACE / DATABASE / SCHEMA，默认为空
+	Namespace     *SqlIdentifier // 切换到的命名空间或 catalog
+}
+
+func NewSqlUse(kind SqlKind, namespace *SqlIdentifier, pos *SqlParserPos) *SqlUse {
+	return &SqlUse{
+		BaseSqlNode: BaseSqlNode{Kind: kind, Pos: pos},
+		Namespace:   namespace,
+	}
+}
+
+func (n *SqlUse) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitUse(n)
+}
+
+func (n *SqlUse) ToString() string {
+	return Unparse(n)
+}
+
+func (n *SqlUse) Clone() SqlNode {
+	clone := &SqlUse{BaseSqlNode: n.cloneBase(), NamespaceType: n.NamespaceType}
+	if n.Namespace != nil {
+		clone.Namespace = n.Namespace.Clone().(*SqlIdentifier)
+	}
+	return clone
+}
+
+// =============================================================================
+// SqlErrorNode - 语法错误节点
+// =============================================================================
+
+// SqlErrorNode 标记恢复模式下无法解析的区域，以及宽松模式下含有不支持语法、无法完整构建的区域
+// 后者同时记录在 SQLParserResult.Warnings 中，严格模式下整条语句返回 *UnsupportedFeatureError
+type SqlErrorNode struct {
+	BaseSqlNode
+	Text string // 出错区域的原始 SQL 文本
+}
+
+func NewSqlErrorNode(text string, pos *SqlParserPos) *SqlErrorNode {
+	return &SqlErrorNode{
+		BaseSqlNode: BaseSqlNode{Kind: SqlKindError, Pos: pos},
+		Text:        text,
+	}
+}
+
+func (n *SqlErrorNode) Accept(visitor SqlNodeVisitor) (interface{}, error) {
+	return visitor.VisitError(n)
+}
+
+func (n *SqlErrorNode) ToString() string {
+	return n.Text
+}
+
+func (n *SqlErrorNode) Clone() SqlNode {
+	return &SqlErrorNode{BaseSqlNode: n.cloneBase(), Text: n.Text}
 }
 
 // =============================================================================
@@ -580,6 +949,13 @@ type SqlNodeVisitor interface {
 	VisitBasicCall(node *SqlBasicCall) (interface{}, error)
 	VisitNodeList(node *SqlNodeList) (interface{}, error)
 	VisitHint(node *SqlHint) (interface{}, error)
+	VisitLambda(node *SqlLambda) (interface{}, error)
+	VisitTableRef(node *SqlTableRef) (interface{}, error)
+	VisitExplain(node *SqlExplain) (interface{}, error)
+	VisitDescribe(node *SqlDescribe) (interface{}, error)
+	VisitShow(node *SqlShow) (interface{}, error)
+	VisitUse(node *SqlUse) (interface{}, error)
+	VisitError(node *SqlErrorNode) (interface{}, error)
 }
 
 // =============================================================================
@@ -0,0 +1,183 @@
+package parser
+
+import (
+	"context"
+	"strings"
+)
+
+// =============================================================================
+// ParseOptions - 解析选项
+// =============================================================================
+
+// IdentifierCase 标识符大小写规范化方式
+type IdentifierCase int
+
+const (
+	IdentifierCasePreserve IdentifierCase = iota // 保持原样
+	IdentifierCaseUpper                          // 转为大写
+	IdentifierCaseLower                          // 转为小写
+)
+
+// ParseOptions 解析选项，零值表示：不改写隐式连接、宽松模式、保持标识符大小写、不保留 ANTLR 树、不限制输入
+type ParseOptions struct {
+	RewriteImplicitJoins bool           // 将 FROM a, b WHERE a.id = b.id 改写为 INNER JOIN，见 RewriteImplicitJoins
+	Strict               bool           // 严格模式：遇到不支持的语法时返回 *UnsupportedFeatureError
+	RecoverErrors        bool           // 恢复模式：收集所有错误并返回部分 SqlNode 树
+	IdentifierCase       IdentifierCase // 标识符大小写规范化，反引号括起的标识符保持原样
+	KeepAntlrTree        bool           // 在 SQLParserResult.AntlrTree 中保留原始 ANTLR 解析树
+	MaxInputLength       int            // 最大输入长度（字节），0 表示不限制
+	MaxTokens            int            // 最大 token 数（不含空白和注释），0 表示不限制
+	MaxNestingDepth      int            // 最大括号嵌套深度，0 表示不限制
+	MaxRecursionDepth    int            // 构建 SqlNode 时的最大递归深度（表达式、子查询），0 表示不限制
+	MaxNodes             int            // 最大 SqlNode 节点数，0 表示不限制
+	DefaultCatalog       string         // 用于补全表名的默认 catalog
+	DefaultSchema        string         // 用于补全表名的默认 schema
+}
+
+// DefaultParseOptions 返回 ParseSQLWithAntlr 使用的默认选项
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{KeepAntlrTree: true}
+}
+
+// ParseWithOptions 按指定选项解析SQL语句
+func ParseWithOptions(sql string, opts ParseOptions) (*SQLParserResult, error) {
+	return parseSQL(context.Background(), sql, opts)
+}
+
+// ParseWithContext 按指定选项解析SQL语句，ctx 取消时尽快中止并返回 ctx.Err()
+// 词法分析和 SqlNode 构建过程中都会检查取消状态
+func ParseWithContext(ctx context.Context, sql string, opts ParseOptions) (*SQLParserResult, error) {
+	return parseSQL(ctx, sql, opts)
+}
+
+// applyParseOptions 对构建好的 SqlNode 树应用后处理选项
+func applyParseOptions(node SqlNode, opts ParseOptions) SqlNode {
+	if opts.RewriteImplicitJoins {
+		node = RewriteImplicitJoins(node)
+	}
+	if opts.DefaultCatalog != "" || opts.DefaultSchema != "" {
+		qualifyTableNames(node, opts.DefaultCatalog, opts.DefaultSchema)
+	}
+	switch opts.IdentifierCase {
+	case IdentifierCaseUpper:
+		forEachIdentifier(node, func(identifier *SqlIdentifier) {
+			normalizeIdentifierCase(identifier, strings.ToUpper)
+		})
+	case IdentifierCaseLower:
+		forEachIdentifier(node, func(identifier *SqlIdentifier) {
+			normalizeIdentifierCase(identifier, strings.ToLower)
+		})
+	}
+	return node
+}
+
+// normalizeIdentifierCase 转换标识符各部分的大小写，反引号括起的部分保持不变
+func normalizeIdentifierCase(identifier *SqlIdentifier, convert func(string) string) {
+	for i, name := range identifier.Names {
+		if strings.HasPrefix(name, "`") {
+			continue
+		}
+		identifier.Names[i] = convert(name)
+	}
+}
+
+// =============================================================================
+// 默认 catalog/schema 补全
+// =============================================================================
+
+// qualifyTableNames 用默认 catalog/schema 补全 FROM 和 DESCRIBE 中的表名
+// 只补全缺失的前缀：t -> schema.t -> catalog.schema.t，schema.t -> catalog.schema.t
+func qualifyTableNames(node SqlNode, catalog, schema string) {
+	switch n := node.(type) {
+	case *SqlSelect:
+		n.From = qualifyFromItem(n.From, catalog, schema)
+		forEachSubquery(n, func(query SqlNode) {
+			qualifyTableNames(query, catalog, schema)
+		})
+	case *SqlExplain:
+		qualifyTableNames(n.Statement, catalog, schema)
+	case *SqlDescribe:
+		qualifyTableName(n.Table, catalog, schema)
+		qualifyTableNames(n.Query, catalog, schema)
+	}
+}
+
+// qualifyFromItem 补全 FROM 项中的表名
+func qualifyFromItem(item SqlNode, catalog, schema string) SqlNode {
+	switch n := item.(type) {
+	case *SqlIdentifier:
+		qualifyTableName(n, catalog, schema)
+	case *SqlTableRef:
+		qualifyTableName(n.Name, catalog, schema)
+	case *SqlJoin:
+		n.Left = qualifyFromItem(n.Left, catalog, schema)
+		n.Right = qualifyFromItem(n.Right, catalog, schema)
+	case *SqlCall:
+		if n.Operator != nil && n.Operator.Kind == SqlKindAs && len(n.Operands) > 0 {
+			n.Operands[0] = qualifyFromItem(n.Operands[0], catalog, schema)
+		}
+	case *SqlSelect:
+		qualifyTableNames(n, catalog, schema)
+	}
+	return item
+}
+
+// qualifyTableName 为表名补全缺失的 catalog/schema 前缀
+func qualifyTableName(identifier *SqlIdentifier, catalog, schema string) {
+	if identifier == nil || len(identifier.Names) == 0 || strings.EqualFold(identifier.Names[0], "DUAL") {
+		return
+	}
+
+	var prefix []string
+	switch len(identifier.Names) {
+	case 1:
+		if schema == "" {
+			return
+		}
+		if catalog != "" {
+			prefix = append(prefix, catalog)
+		}
+		prefix = append(prefix, schema)
+	case 2:
+		if catalog == "" {
+			return
+		}
+		prefix = append(prefix, catalog)
+	default:
+		return
+	}
+	identifier.Names = append(prefix, identifier.Names...)
+}
+
+// =============================================================================
+// SqlNode 树遍历辅助
+// =============================================================================
+
+// forEachSubquery 对 SELECT 中 FROM 以外位置出现的子查询调用 fn
+// FROM 中的子查询由调用方自行处理
+func forEachSubquery(sel *SqlSelect, fn func(SqlNode)) {
+	var expressions []SqlNode
+	expressions = append(expressions, sel.SelectList...)
+	expressions = append(expressions, sel.Where, sel.Having)
+	expressions = append(expressions, sel.GroupBy...)
+	expressions = append(expressions, sel.OrderBy...)
+	for _, expr := range expressions {
+		Inspect(expr, func(child SqlNode) bool {
+			if query, ok := child.(*SqlSelect); ok {
+				fn(query)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// forEachIdentifier 对树中所有标识符调用 fn
+func forEachIdentifier(node SqlNode, fn func(*SqlIdentifier)) {
+	Inspect(node, func(child SqlNode) bool {
+		if identifier, ok := child.(*SqlIdentifier); ok {
+			fn(identifier)
+		}
+		return true
+	})
+}
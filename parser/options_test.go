@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseWithOptionsIdentifierCase 测试标识符大小写规范化
+func TestParseWithOptionsIdentifierCase(t *testing.T) {
+	sql := "select Orders.Amount, `MixedCase`.Id from Orders, `MixedCase`"
+
+	testCases := []struct {
+		name       string
+		identCase  IdentifierCase
+		selectItem string
+		from       string
+	}{
+		{name: "保持原样", identCase: IdentifierCasePreserve, selectItem: "Orders.Amount", from: "Orders, `MixedCase`"},
+		{name: "转为大写", identCase: IdentifierCaseUpper, selectItem: "ORDERS.AMOUNT", from: "ORDERS, `MixedCase`"},
+		{name: "转为小写", identCase: IdentifierCaseLower, selectItem: "orders.amount", from: "orders, `MixedCase`"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseWithOptions(sql, ParseOptions{IdentifierCase: tc.identCase})
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			sel := result.SqlNode.(*SqlSelect)
+			if got := sel.SelectList[0].ToString(); got != tc.selectItem {
+				t.Errorf("SELECT 项 = %q，期望 %q", got, tc.selectItem)
+			}
+			if got := sel.From.ToString(); got != tc.from {
+				t.Errorf("FROM = %q，期望 %q", got, tc.from)
+			}
+		})
+	}
+}
+
+// TestParseWithOptionsDefaultNamespace 测试默认 catalog/schema 补全表名
+func TestParseWithOptionsDefaultNamespace(t *testing.T) {
+	testCases := []struct {
+		name    string
+		sql     string
+		catalog string
+		schema  string
+		from    string
+	}{
+		{name: "补全 schema", sql: "select t.a from t", schema: "db", from: "db.t"},
+		{name: "补全 catalog 和 schema", sql: "select t.a from t", catalog: "hive", schema: "db", from: "hive.db.t"},
+		{name: "已有 schema 只补全 catalog", sql: "select t.a from other.t", catalog: "hive", schema: "db", from: "hive.other.t"},
+		{name: "完整表名不变", sql: "select t.a from c.s.t", catalog: "hive", schema: "db", from: "c.s.t"},
+		{name: "子查询中的表", sql: "select x.a from (select t.a from t) x", schema: "db", from: "(SELECT t.a FROM db.t) AS x"},
+		{name: "DUAL 不区分大小写", sql: "select 1 from dual", schema: "db", from: "dual"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseWithOptions(tc.sql, ParseOptions{DefaultCatalog: tc.catalog, DefaultSchema: tc.schema})
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			sel := result.SqlNode.(*SqlSelect)
+			if got := sel.From.ToString(); got != tc.from {
+				t.Errorf("FROM = %q，期望 %q", got, tc.from)
+			}
+		})
+	}
+}
+
+// TestParseWithOptionsImplicitJoin 测试隐式连接改写开关
+func TestParseWithOptionsImplicitJoin(t *testing.T) {
+	sql := "select a.x from a, b where a.id = b.id"
+
+	result, err := ParseWithOptions(sql, ParseOptions{})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if join := result.SqlNode.(*SqlSelect).From.(*SqlJoin); join.JoinType != JoinComma {
+		t.Errorf("关闭改写时期望逗号连接，实际为 %s", join.JoinType)
+	}
+
+	result, err = ParseWithOptions(sql, ParseOptions{RewriteImplicitJoins: true})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if join := result.SqlNode.(*SqlSelect).From.(*SqlJoin); join.JoinType != JoinInner {
+		t.Errorf("开启改写时期望 INNER JOIN，实际为 %s", join.JoinType)
+	}
+}
+
+// TestParseWithOptionsAntlrTree 测试是否保留 ANTLR 解析树
+func TestParseWithOptionsAntlrTree(t *testing.T) {
+	sql := "select t.a from t"
+
+	result, err := ParseWithOptions(sql, ParseOptions{})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if result.AntlrTree != nil {
+		t.Errorf("未开启 KeepAntlrTree 时不应保留解析树")
+	}
+
+	result, err = ParseWithOptions(sql, ParseOptions{KeepAntlrTree: true})
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if result.AntlrTree == nil {
+		t.Errorf("开启 KeepAntlrTree 时应保留解析树")
+	}
+}
+
+// TestParseWithOptionsLimits 测试输入长度和嵌套深度限制
+func TestParseWithOptionsLimits(t *testing.T) {
+	if _, err := ParseWithOptions("select t.a from t", ParseOptions{MaxInputLength: 5}); err == nil {
+		t.Errorf("超过最大长度时应返回错误")
+	}
+
+	nested := "select " + strings.Repeat("(", 10) + "1" + strings.Repeat(")", 10) + " from t"
+	if _, err := ParseWithOptions(nested, ParseOptions{MaxNestingDepth: 5}); err == nil {
+		t.Errorf("超过最大嵌套深度时应返回错误")
+	}
+	if _, err := ParseWithOptions(nested, ParseOptions{MaxNestingDepth: 10}); err != nil {
+		t.Errorf("未超过最大嵌套深度时不应返回错误: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -12,15 +13,21 @@ import (
 type SQLParserResult struct {
 	Success      bool
 	ErrorMessage string
+	SQL          string      // 被解析的 SQL（已去除首尾空白），位置信息中的字节偏移相对于它计算
 	SqlNode      SqlNode     // SqlNode AST（类似 Calcite）
 	AntlrTree    interface{} // 原始 ANTLR 解析树
 	Errors       []string
+	Diagnostics  []*Diagnostic // 结构化诊断信息，与 Errors 一一对应
+	Warnings     []error       // 宽松模式下收集的警告，如 *UnsupportedFeatureError
 }
 
 // AntlrErrorListener 自定义错误监听器
 type AntlrErrorListener struct {
 	*antlr4.DefaultErrorListener
-	Errors []string
+	Errors      []string
+	Diagnostics []*Diagnostic
+	
+	sql string // 被解析的 SQL，用于计算字节偏移
 }
 
 // NewAntlrErrorListener 创建新的错误监听器
@@ -32,57 +39,101 @@ func NewAntlrErrorListener() *AntlrErrorListener {
 
 // SyntaxError 实现 ErrorListener 接口
 func (l *AntlrErrorListener) SyntaxError(recognizer antlr4.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr4.RecognitionException) {
-	errorMsg := fmt.Sprintf("line %d:%d %s", line, column, msg)
-	l.Errors = append(l.Errors, errorMsg)
+	diagnostic := newDiagnostic(l.sql, recognizer, offendingSymbol, line, column, msg, e)
+	l.Diagnostics = append(l.Diagnostics, diagnostic)
+	l.Errors = append(l.Errors, diagnostic.String())
 }
 
 // ParseSQLWithAntlr 使用ANTLR4解析SQL语句，返回 SqlNode 结构
 // 使用 Visitor 模式直接在访问 AST 时构建 SqlNode
 func ParseSQLWithAntlr(sql string) (*SQLParserResult, error) {
+	return parseSQL(context.Background(), sql, DefaultParseOptions())
+}
+
+// ParseSQLStrict 以严格模式解析SQL语句
+// 遇到语法合法但尚不支持的结构时返回 *UnsupportedFeatureError，而不是返回缺失部分节点的树
+// ParseSQLWithAntlr 为宽松模式，同样的问题记录在 SQLParserResult.Warnings 中
+func ParseSQLStrict(sql string) (*SQLParserResult, error) {
+	opts := DefaultParseOptions()
+	opts.Strict = true
+	return parseSQL(context.Background(), sql, opts)
+}
+
+// ParseSQLWithRecovery 以恢复模式解析SQL语句
+// 一次性收集所有词法和语法错误，并尽可能构建部分 SqlNode 树，出错的区域用 SqlErrorNode 标记
+// 存在错误时同时返回 result（包含部分树）和 *ParseError
+func ParseSQLWithRecovery(sql string) (*SQLParserResult, error) {
+	opts := DefaultParseOptions()
+	opts.RecoverErrors = true
+	return parseSQL(context.Background(), sql, opts)
+}
+
+// parseSQL 按选项解析SQL语句
+func parseSQL(ctx context.Context, sql string, opts ParseOptions) (*SQLParserResult, error) {
 	// 清理SQL语句
 	sql = strings.TrimSpace(sql)
 	if sql == "" {
 		return nil, fmt.Errorf("SQL语句不能为空")
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := checkInputLength(sql, opts); err != nil {
+		return nil, err
+	}
 
 	result := &SQLParserResult{
 		Success: true,
+		SQL:     sql,
 		Errors:  make([]string, 0),
 	}
 	
-	// 1. 创建输入流
-	input := antlr4.NewInputStream(sql)
-	
-	// 2. 创建词法分析器
-	lexer := antlr.NewSqlBaseLexer(input)
-	
-	// 3. 创建token流
-	stream := antlr4.NewCommonTokenStream(lexer, antlr4.TokenDefaultChannel)
+	// 1. 创建错误监听器（词法和语法分析共用）
+	errorListener := NewAntlrErrorListener()
+	errorListener.sql = sql
 	
-	// 4. 创建语法分析器
-	parser := antlr.NewSqlBaseParser(stream)
+	// 2. 从池中取出词法/语法分析器并绑定输入
+	inst := acquireParser(ctx, sql, errorListener, opts)
 	
-	// 5. 添加自定义错误监听器
-	errorListener := NewAntlrErrorListener()
-	parser.RemoveErrorListeners()
-	parser.AddErrorListener(errorListener)
+	// 3. 解析SQL语句（从起始规则开始，先 SLL 后 LL），超过限制或取消时中止
+	var tree antlr.ISingleStatementContext
+	err := runGuarded(func() { tree = inst.parseSingleStatement(errorListener) })
 	
-	// 6. 解析SQL语句（从起始规则开始）
-	tree := parser.SingleStatement()
+	// 4. 收集注释后归还解析器，中止时解析器状态不完整，不再复用
+	var comments []pendingComment
+	var commentWarnings []error
+	if err == nil {
+		comments, commentWarnings = collectComments(inst.stream)
+	}
+	releaseParser(inst, err == nil)
+	if err != nil {
+		return nil, err
+	}
 	
-	// 7. 检查是否有语法错误
+	// 5. 检查是否有语法错误，恢复模式下继续构建部分语法树
+	var parseErr *ParseError
 	if len(errorListener.Errors) > 0 {
 		result.Success = false
 		result.Errors = errorListener.Errors
+		result.Diagnostics = errorListener.Diagnostics
 		result.ErrorMessage = strings.Join(errorListener.Errors, "; ")
-		return result, fmt.Errorf("解析失败: %s", result.ErrorMessage)
+		parseErr = &ParseError{SQL: sql, Diagnostics: errorListener.Diagnostics}
+		if !opts.RecoverErrors {
+			return result, parseErr
+		}
 	}
 	
-	// 8. 保存原始 ANTLR 解析树
-	result.AntlrTree = tree
+	// 6. 按需保存原始 ANTLR 解析树
+	if opts.KeepAntlrTree {
+		result.AntlrTree = tree
+	}
 	
-	// 9. 使用 Visitor 模式直接构建 SqlNode
+	// 7. 使用 Visitor 模式直接构建 SqlNode
 	visitor := NewSqlNodeBuilderVisitor()
+	visitor.recoverErrors = opts.RecoverErrors
+	visitor.ctx = ctx
+	visitor.maxRecursion = opts.MaxRecursionDepth
+	visitor.maxNodes = opts.MaxNodes
 	
 	// 类型断言并直接调用 VisitSingleStatement
 	singleStmtCtx, ok := tree.(*antlr.SingleStatementContext)
@@ -90,7 +141,33 @@ func ParseSQLWithAntlr(sql string) (*SQLParserResult, error) {
 		return nil, fmt.Errorf("tree 类型错误: %T", tree)
 	}
 	
-	sqlNodeResult := visitor.VisitSingleStatement(singleStmtCtx)
+	if parseErr != nil {
+		// 部分语法树可能缺少子节点，构建失败时整条语句标记为错误节点
+		var partial SqlNode
+		if err := runGuarded(func() { partial = visitor.buildPartialSqlNode(singleStmtCtx, sql) }); err != nil {
+			return nil, err
+		}
+		if err := checkNodeCount(partial, opts); err != nil {
+			return nil, err
+		}
+		attachComments(partial, comments)
+		result.SqlNode = partial
+		result.Warnings = append(visitor.getUnsupportedWarnings(), commentWarnings...)
+		return result, parseErr
+	}
+	
+	var sqlNodeResult interface{}
+	if err := runGuarded(func() { sqlNodeResult = visitor.VisitSingleStatement(singleStmtCtx) }); err != nil {
+		return nil, err
+	}
+	result.Warnings = append(visitor.getUnsupportedWarnings(), commentWarnings...)
+	
+	// 整条语句不支持时没有可返回的树，无论是否严格模式都返回错误
+	if unsupportedErr, ok := sqlNodeResult.(*UnsupportedFeatureError); ok {
+		result.Success = false
+		result.ErrorMessage = unsupportedErr.Error()
+		return result, unsupportedErr
+	}
 	
 	if sqlNodeResult == nil {
 		return nil, fmt.Errorf("无法构建 SqlNode")
@@ -101,7 +178,18 @@ func ParseSQLWithAntlr(sql string) (*SQLParserResult, error) {
 		return nil, fmt.Errorf("visitor 返回的不是 SqlNode 类型: %T", sqlNodeResult)
 	}
 	
-	result.SqlNode = sqlNode
+	if err := checkNodeCount(sqlNode, opts); err != nil {
+		return nil, err
+	}
+	result.SqlNode = applyParseOptions(sqlNode, opts)
+	attachComments(result.SqlNode, comments)
+	
+	// 严格模式下，树不完整即视为失败
+	if opts.Strict && len(visitor.unsupported) > 0 {
+		result.Success = false
+		result.ErrorMessage = visitor.unsupported[0].Error()
+		return result, visitor.unsupported[0]
+	}
 	
 	return result, nil
 }
@@ -115,6 +203,7 @@ func ExtractTableNames(sqlNode SqlNode) ([]string, error) {
 	extractor := &TableNameExtractor{
 		tables: make([]string, 0),
 	}
+	extractor.BaseSqlNodeVisitor = NewBaseSqlNodeVisitor(extractor)
 	
 	_, err := sqlNode.Accept(extractor)
 	return extractor.tables, err
@@ -129,6 +218,7 @@ func ExtractColumns(sqlNode SqlNode) ([]string, error) {
 	extractor := &ColumnNameExtractor{
 		columns: make([]string, 0),
 	}
+	extractor.BaseSqlNodeVisitor = NewBaseSqlNodeVisitor(extractor)
 	
 	_, err := sqlNode.Accept(extractor)
 	return extractor.columns, err
@@ -139,25 +229,12 @@ func ExtractColumns(sqlNode SqlNode) ([]string, error) {
 // =============================================================================
 
 // TableNameExtractor 提取表名的 Visitor
+// 只覆盖包含表名的节点，其余节点由 BaseSqlNodeVisitor 继续遍历子节点
 type TableNameExtractor struct {
+	*BaseSqlNodeVisitor
 	tables []string
 }
 
-func (v *TableNameExtractor) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
-	return nil, nil
-}
-
-func (v *TableNameExtractor) VisitLiteral(node *SqlLiteral) (interface{}, error) {
-	return nil, nil
-}
-
-func (v *TableNameExtractor) VisitCall(node *SqlCall) (interface{}, error) {
-	for _, operand := range node.Operands {
-		operand.Accept(v)
-	}
-	return nil, nil
-}
-
 func (v *TableNameExtractor) VisitSelect(node *SqlSelect) (interface{}, error) {
 	// 提取 FROM 子句中的表名
 	if node.From != nil {
@@ -191,36 +268,37 @@ func (v *TableNameExtractor) VisitBasicCall(node *SqlBasicCall) (interface{}, er
 	return nil, nil
 }
 
-func (v *TableNameExtractor) VisitNodeList(node *SqlNodeList) (interface{}, error) {
-	for _, item := range node.List {
-		item.Accept(v)
+func (v *TableNameExtractor) VisitTableRef(node *SqlTableRef) (interface{}, error) {
+	if node.Name != nil {
+		v.tables = append(v.tables, node.Name.ToString())
 	}
 	return nil, nil
 }
 
-func (v *TableNameExtractor) VisitHint(node *SqlHint) (interface{}, error) {
-	// Hint 不包含表名，直接返回
-	return nil, nil
-}
-
-// ColumnNameExtractor 提取列名的 Visitor
-type ColumnNameExtractor struct {
-	columns []string
-}
-
-func (v *ColumnNameExtractor) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
+func (v *TableNameExtractor) VisitDescribe(node *SqlDescribe) (interface{}, error) {
+	if node.Table != nil {
+		v.tables = append(v.tables, node.Table.ToString())
+	}
+	if node.Query != nil {
+		node.Query.Accept(v)
+	}
 	return nil, nil
 }
 
-func (v *ColumnNameExtractor) VisitLiteral(node *SqlLiteral) (interface{}, error) {
+func (v *TableNameExtractor) VisitShow(node *SqlShow) (interface{}, error) {
+	// SHOW COLUMNS / SHOW CREATE TABLE 等的目标对象是表
+	if node.Object != nil {
+		v.tables = append(v.tables, node.Object.ToString())
+	}
 	return nil, nil
 }
 
-func (v *ColumnNameExtractor) VisitCall(node *SqlCall) (interface{}, error) {
-	for _, operand := range node.Operands {
-		operand.Accept(v)
-	}
-	return nil, nil
+// ColumnNameExtractor 提取列名的 Visitor
+// 只提取 SELECT 列表中的列名，其余节点由 BaseSqlNodeVisitor 继续遍历子节点
+type ColumnNameExtractor struct {
+	*BaseSqlNodeVisitor
+	columns      []string
+	lambdaScopes []map[string]bool // lambda 参数作用域栈
 }
 
 func (v *ColumnNameExtractor) VisitSelect(node *SqlSelect) (interface{}, error) {
@@ -228,12 +306,12 @@ func (v *ColumnNameExtractor) VisitSelect(node *SqlSelect) (interface{}, error)
 	for _, selectItem := range node.SelectList {
 		if basicCall, ok := selectItem.(*SqlBasicCall); ok {
 			// 带别名的列
-			if identifier, ok := basicCall.Operand.(*SqlIdentifier); ok {
+			if identifier, ok := basicCall.Operand.(*SqlIdentifier); ok && !v.isLambdaParameter(identifier) {
 				v.columns = append(v.columns, identifier.ToString())
 			}
 		} else if identifier, ok := selectItem.(*SqlIdentifier); ok {
 			// 直接的标识符
-			if identifier.ToString() != "*" {
+			if identifier.ToString() != "*" && !v.isLambdaParameter(identifier) {
 				v.columns = append(v.columns, identifier.ToString())
 			}
 		}
@@ -241,24 +319,29 @@ func (v *ColumnNameExtractor) VisitSelect(node *SqlSelect) (interface{}, error)
 	return nil, nil
 }
 
-func (v *ColumnNameExtractor) VisitJoin(node *SqlJoin) (interface{}, error) {
-	return nil, nil
-}
-
-func (v *ColumnNameExtractor) VisitBasicCall(node *SqlBasicCall) (interface{}, error) {
-	return nil, nil
-}
-
-func (v *ColumnNameExtractor) VisitNodeList(node *SqlNodeList) (interface{}, error) {
-	for _, item := range node.List {
-		item.Accept(v)
+func (v *ColumnNameExtractor) VisitLambda(node *SqlLambda) (interface{}, error) {
+	if node.Body == nil {
+		return nil, nil
 	}
-	return nil, nil
+	
+	// 参数只在 lambda 体内可见，不是表中的列
+	v.lambdaScopes = append(v.lambdaScopes, node.ParameterNames())
+	defer func() {
+		v.lambdaScopes = v.lambdaScopes[:len(v.lambdaScopes)-1]
+	}()
+	
+	return node.Body.Accept(v)
 }
 
-func (v *ColumnNameExtractor) VisitHint(node *SqlHint) (interface{}, error) {
-	// Hint 不包含列名，直接返回
-	return nil, nil
+// isLambdaParameter 判断标识符是否引用当前作用域内的 lambda 参数
+func (v *ColumnNameExtractor) isLambdaParameter(identifier *SqlIdentifier) bool {
+	if len(identifier.Names) == 0 {
+		return false
+	}
+	for i := len(v.lambdaScopes) - 1; i >= 0; i-- {
+		if v.lambdaScopes[i][identifier.Names[0]] {
+			return true
+		}
+	}
+	return false
 }
-
-
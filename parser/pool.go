@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+	"go-job-service/parser/antlr"
+)
+
+// =============================================================================
+// 解析器复用
+// =============================================================================
+
+// parserInstance 可复用的词法分析器、token 流和语法分析器
+type parserInstance struct {
+	lexer  *antlr.SqlBaseLexer
+	stream *antlr4.CommonTokenStream
+	parser *antlr.SqlBaseParser
+	dfa    *dfaCache            // 当前语法分析器使用的 DFA 缓存
+	cancel *cancelCheckListener // 可取消的 ctx 使用的解析监听器
+}
+
+var parserPool = sync.Pool{
+	New: func() interface{} {
+		lexer := antlr.NewSqlBaseLexer(antlr4.NewInputStream(""))
+		stream := antlr4.NewCommonTokenStream(lexer, antlr4.TokenDefaultChannel)
+		return &parserInstance{
+			lexer:  lexer,
+			stream: stream,
+			parser: antlr.NewSqlBaseParser(stream),
+			cancel: &cancelCheckListener{},
+		}
+	},
+}
+
+// acquireParser 从池中取出解析器并绑定到新的输入
+// 词法错误直接交给 listener，语法错误在 parseSingleStatement 中按阶段处理
+// token 流经过 guardedTokenSource，超过 MaxTokens、MaxNestingDepth 或 ctx 取消时中止解析；
+// ctx 可取消时还会在语法分析期间通过 cancelCheckListener 检查取消状态
+func acquireParser(ctx context.Context, sql string, listener *AntlrErrorListener, opts ParseOptions) *parserInstance {
+	inst := parserPool.Get().(*parserInstance)
+
+	inst.lexer.SetInputStream(antlr4.NewInputStream(sql))
+	inst.lexer.RemoveErrorListeners()
+	inst.lexer.AddErrorListener(listener)
+	inst.stream.SetTokenSource(newGuardedTokenSource(ctx, inst.lexer, opts.MaxTokens, opts.MaxNestingDepth))
+
+	if ctx.Done() != nil {
+		inst.cancel.ctx = ctx
+		inst.cancel.rules = 0
+		inst.parser.AddParseListener(inst.cancel)
+	}
+
+	inst.useDFACache(currentDFACache(inst.parser))
+	inst.dfa.mu.RLock()
+	return inst
+}
+
+// releaseParser 解析结束后归还解析器
+// reuse 为 false 时（解析被中止，内部状态不完整）不放回池中
+// 已构建的解析树只引用 token 和规则名，解析器复用后仍然有效
+func releaseParser(inst *parserInstance, reuse bool) {
+	inst.dfa.mu.RUnlock()
+	inst.dfa.afterParse()
+
+	if reuse {
+		inst.parser.RemoveParseListener(inst.cancel)
+		inst.cancel.ctx = nil
+		parserPool.Put(inst)
+	}
+}
+
+// useDFACache 切换语法分析器使用的 DFA 缓存
+func (inst *parserInstance) useDFACache(cache *dfaCache) {
+	if inst.dfa == cache {
+		return
+	}
+	inst.parser.Interpreter = antlr4.NewParserATNSimulator(inst.parser, cache.atn, cache.decisionToDFA, cache.contextCache)
+	inst.dfa = cache
+}
+
+// parseSingleStatement 两阶段解析
+// 第一阶段使用 SLL 预测并在遇到错误时立即放弃，绝大多数语句在这一阶段即可完成；
+// 只有存在语法错误或需要完整上下文预测的语句才会用 LL 预测重新解析，并把错误交给 listener
+func (inst *parserInstance) parseSingleStatement(listener *AntlrErrorListener) antlr.ISingleStatementContext {
+	p := inst.parser
+
+	sllErrors := &sllErrorListener{DefaultErrorListener: antlr4.NewDefaultErrorListener()}
+	p.RemoveErrorListeners()
+	p.AddErrorListener(sllErrors)
+	p.SetErrorHandler(antlr4.NewBailErrorStrategy())
+	p.SetInputStream(inst.stream)
+	p.SetError(nil)
+	p.GetInterpreter().SetPredictionMode(antlr4.PredictionModeSLL)
+
+	tree := p.SingleStatement()
+	if !sllErrors.failed && !p.HasError() {
+		return tree
+	}
+	return inst.parseLL(listener)
+}
+
+// parseLL 第二阶段：从头使用 LL 预测重新解析，token 已缓存在流中，不会重复报告词法错误
+// SetInputStream 在 reset 之前就清空了输入，reset 不会回退 token 流，
+// 因此必须先把流退回开头，否则会从 SLL 放弃的位置继续解析
+func (inst *parserInstance) parseLL(listener *AntlrErrorListener) antlr.ISingleStatementContext {
+	p := inst.parser
+
+	p.RemoveErrorListeners()
+	p.AddErrorListener(listener)
+	p.SetErrorHandler(antlr4.NewDefaultErrorStrategy())
+	inst.stream.Seek(0)
+	p.SetInputStream(inst.stream)
+	p.SetError(nil)
+	p.GetInterpreter().SetPredictionMode(antlr4.PredictionModeLL)
+
+	return p.SingleStatement()
+}
+
+// sllErrorListener 第一阶段的错误监听器，只记录是否出错
+type sllErrorListener struct {
+	*antlr4.DefaultErrorListener
+	failed bool
+}
+
+// SyntaxError 实现 ErrorListener 接口
+func (l *sllErrorListener) SyntaxError(recognizer antlr4.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr4.RecognitionException) {
+	l.failed = true
+}
+
+// =============================================================================
+// DFA 缓存
+// =============================================================================
+
+const (
+	dfaCacheMaxStates     = 500000 // DFA 缓存允许的最大状态数，超过后整体丢弃重建
+	dfaCacheCheckInterval = 1000   // 每解析多少条语句检查一次 DFA 缓存大小
+)
+
+// dfaCache 语法分析器共享的 DFA 缓存
+// ANTLR 预测过程中会不断向 DFA 添加状态，长时间解析大量不同语句时缓存会无限增长，
+// 因此定期统计状态数，超过上限后换用新的缓存，旧缓存在使用它的解析结束后被回收
+type dfaCache struct {
+	mu            sync.RWMutex // 解析期间持有读锁，统计状态数时持有写锁
+	atn           *antlr4.ATN
+	decisionToDFA []*antlr4.DFA
+	contextCache  *antlr4.PredictionContextCache
+	parses        atomic.Int64 // 使用该缓存完成的解析次数
+	checkPending  atomic.Bool  // 是否有待执行的大小检查
+}
+
+var sharedDFACache atomic.Pointer[dfaCache]
+
+// currentDFACache 返回当前共享的 DFA 缓存，不存在时根据 p 的 ATN 创建
+func currentDFACache(p *antlr.SqlBaseParser) *dfaCache {
+	for {
+		if cache := sharedDFACache.Load(); cache != nil {
+			return cache
+		}
+		sharedDFACache.CompareAndSwap(nil, newDFACache(p.GetATN()))
+	}
+}
+
+// newDFACache 为 ATN 的每个决策点创建空的 DFA
+func newDFACache(atn *antlr4.ATN) *dfaCache {
+	decisionToDFA := make([]*antlr4.DFA, len(atn.DecisionToState))
+	for i, state := range atn.DecisionToState {
+		decisionToDFA[i] = antlr4.NewDFA(state, i)
+	}
+	return &dfaCache{
+		atn:           atn,
+		decisionToDFA: decisionToDFA,
+		contextCache:  antlr4.NewPredictionContextCache(),
+	}
+}
+
+// afterParse 每完成 dfaCacheCheckInterval 次解析检查一次缓存大小
+// 有其他解析正在使用该缓存时跳过，由之后完成的解析重试
+func (c *dfaCache) afterParse() {
+	if c.parses.Add(1)%dfaCacheCheckInterval == 0 {
+		c.checkPending.Store(true)
+	}
+	if !c.checkPending.Load() || !c.mu.TryLock() {
+		return
+	}
+	c.checkPending.Store(false)
+	states := 0
+	for _, dfa := range c.decisionToDFA {
+		states += dfa.Len()
+	}
+	c.mu.Unlock()
+
+	if states > dfaCacheMaxStates {
+		sharedDFACache.CompareAndSwap(c, nil)
+	}
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-job-service/parser/antlr"
+)
+
+// TestParseLLRestartsFromBeginning 测试 SLL 阶段中途放弃后，LL 阶段从第一个 token 重新解析
+func TestParseLLRestartsFromBeginning(t *testing.T) {
+	sql := "select t.a, t.b from db.t t where t.a > 1"
+	listener := NewAntlrErrorListener()
+	listener.sql = sql
+	inst := acquireParser(context.Background(), sql, listener, ParseOptions{})
+	defer releaseParser(inst, true)
+
+	// 模拟 SLL 阶段在语句中间出错放弃：token 流停在 from 之后
+	inst.stream.Fill()
+	inst.stream.Seek(7)
+
+	tree, ok := inst.parseLL(listener).(*antlr.SingleStatementContext)
+	if !ok || tree == nil {
+		t.Fatal("LL 阶段没有返回解析树")
+	}
+	if len(listener.Diagnostics) != 0 {
+		t.Fatalf("LL 阶段不应报告错误，实际得到: %v", listener.Errors)
+	}
+	if tree.GetStart().GetTokenIndex() != 0 {
+		t.Errorf("LL 阶段应从第一个 token 开始，实际从第 %d 个开始", tree.GetStart().GetTokenIndex())
+	}
+
+	node, ok := NewSqlNodeBuilderVisitor().VisitSingleStatement(tree).(SqlNode)
+	if !ok || Unparse(node) != "SELECT t.a, t.b FROM db.t AS t WHERE t.a > 1" {
+		t.Errorf("LL 阶段的解析结果 = %v", node)
+	}
+}
+
+// TestParseLLDiagnosticOffsets 测试经过 LL 阶段的语法错误指向正确的 token
+func TestParseLLDiagnosticOffsets(t *testing.T) {
+	sql := "select t.a from t where t.b = = 1"
+	_, err := ParseSQLWithAntlr(sql)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || len(parseErr.Diagnostics) == 0 {
+		t.Fatalf("期望 *ParseError，实际得到: %v", err)
+	}
+	d := parseErr.Diagnostics[0]
+	if want := strings.LastIndex(sql, "="); d.StartOffset != want || d.OffendingToken != "=" {
+		t.Errorf("错误位置 = %d (%q)，期望 %d (\"=\")", d.StartOffset, d.OffendingToken, want)
+	}
+}
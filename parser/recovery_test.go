@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestLexerErrorsAreReported 测试词法错误通过 ParseError 返回
+func TestLexerErrorsAreReported(t *testing.T) {
+	sql := "select events.a from events where events.b = 'abc"
+
+	_, err := ParseSQLWithAntlr(sql)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("期望 *ParseError，实际得到: %v", err)
+	}
+
+	found := false
+	for _, d := range parseErr.Diagnostics {
+		if d.Code == ErrCodeTokenRecognition {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("期望包含词法错误诊断，实际得到: %v", parseErr)
+	}
+}
+
+// TestRecoveryModeReturnsPartialTree 测试恢复模式收集所有错误并返回部分语法树
+func TestRecoveryModeReturnsPartialTree(t *testing.T) {
+	sql := "select events.a, from events where events.b = )"
+
+	result, err := ParseSQLWithRecovery(sql)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("期望 *ParseError，实际得到: %v", err)
+	}
+	if result == nil || result.SqlNode == nil {
+		t.Fatal("恢复模式应返回部分语法树")
+	}
+	if result.Success {
+		t.Error("存在错误时 Success 应为 false")
+	}
+
+	sqlSelect, ok := result.SqlNode.(*SqlSelect)
+	if !ok {
+		t.Fatalf("期望 SqlSelect，实际得到: %T", result.SqlNode)
+	}
+	if sqlSelect.Where == nil || sqlSelect.Where.GetKind() != SqlKindError {
+		t.Errorf("期望 WHERE 被标记为错误节点，实际得到: %v", sqlSelect.Where)
+	}
+	if sqlSelect.From == nil || sqlSelect.From.ToString() != "events" {
+		t.Errorf("期望保留 FROM 子句，实际得到: %v", sqlSelect.From)
+	}
+}
+
+// TestRecoveryModeWithoutErrors 测试恢复模式在没有错误时与普通模式一致
+func TestRecoveryModeWithoutErrors(t *testing.T) {
+	sql := "select events.a from events where events.b = 1"
+
+	result, err := ParseSQLWithRecovery(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	expected, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if result.SqlNode.ToString() != expected.SqlNode.ToString() {
+		t.Errorf("期望 %q，实际得到: %q", expected.SqlNode.ToString(), result.SqlNode.ToString())
+	}
+}
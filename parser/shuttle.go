@@ -0,0 +1,374 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// =============================================================================
+// SqlShuttle - 语法树改写
+// =============================================================================
+
+// SqlShuttle 自底向上改写语法树的访问者，参考 Calcite 的 SqlShuttle
+//
+// 每个 Visit 方法返回改写后的 SqlNode（作为 interface{}）。默认实现先改写所有子节点，
+// 子节点都没有变化时返回原节点，否则返回替换了子节点的浅拷贝，因此只有变化的路径会被复制，
+// 未变化的子树在新旧两棵树之间共享。
+//
+// 自定义改写时嵌入 *SqlShuttle 并只覆盖关心的方法，构造时把自身传给 NewSqlShuttle，
+// 这样默认实现递归时会回到覆盖后的方法：
+//
+//	type tableRenamer struct {
+//		*parser.SqlShuttle
+//	}
+//
+//	r := &tableRenamer{}
+//	r.SqlShuttle = parser.NewSqlShuttle(r)
+//	newNode, err := parser.Rewrite(node, r)
+type SqlShuttle struct {
+	self SqlNodeVisitor
+}
+
+// NewSqlShuttle 创建默认改写器，self 为递归访问子节点时使用的访问者（通常是嵌入它的外层结构）
+// self 为 nil 时使用默认实现本身，此时改写结果与原树相同
+func NewSqlShuttle(self SqlNodeVisitor) *SqlShuttle {
+	s := &SqlShuttle{self: self}
+	if s.self == nil {
+		s.self = s
+	}
+	return s
+}
+
+// Rewrite 用 shuttle 改写 node，返回改写后的树
+func Rewrite(node SqlNode, shuttle SqlNodeVisitor) (SqlNode, error) {
+	if node == nil {
+		return nil, nil
+	}
+	result, err := node.Accept(shuttle)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+	rewritten, ok := result.(SqlNode)
+	if !ok {
+		return nil, fmt.Errorf("改写结果不是 SqlNode 类型: %T", result)
+	}
+	return rewritten, nil
+}
+
+// visitChild 改写单个子节点
+func (s *SqlShuttle) visitChild(node SqlNode) (SqlNode, error) {
+	return Rewrite(node, s.self)
+}
+
+// visitList 改写子节点列表，没有变化时返回原列表
+func (s *SqlShuttle) visitList(nodes []SqlNode) ([]SqlNode, bool, error) {
+	var rewritten []SqlNode
+	for i, node := range nodes {
+		newNode, err := s.visitChild(node)
+		if err != nil {
+			return nil, false, err
+		}
+		if rewritten == nil && newNode != node {
+			rewritten = make([]SqlNode, len(nodes))
+			copy(rewritten, nodes[:i])
+		}
+		if rewritten != nil {
+			rewritten[i] = newNode
+		}
+	}
+	if rewritten == nil {
+		return nodes, false, nil
+	}
+	return rewritten, true, nil
+}
+
+// visitIdentifierChild 改写类型为 *SqlIdentifier 的子节点，改写结果必须仍是标识符
+func (s *SqlShuttle) visitIdentifierChild(identifier *SqlIdentifier) (*SqlIdentifier, error) {
+	if identifier == nil {
+		return nil, nil
+	}
+	newNode, err := s.visitChild(identifier)
+	if err != nil || newNode == nil {
+		return nil, err
+	}
+	newIdentifier, ok := newNode.(*SqlIdentifier)
+	if !ok {
+		return nil, fmt.Errorf("标识符只能改写为 *SqlIdentifier，实际为 %T", newNode)
+	}
+	return newIdentifier, nil
+}
+
+// VisitIdentifier 标识符没有子节点，返回原节点
+func (s *SqlShuttle) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
+	return node, nil
+}
+
+// VisitLiteral 字面量没有子节点，返回原节点
+func (s *SqlShuttle) VisitLiteral(node *SqlLiteral) (interface{}, error) {
+	return node, nil
+}
+
+// VisitCall 改写操作数
+func (s *SqlShuttle) VisitCall(node *SqlCall) (interface{}, error) {
+	operands, changed, err := s.visitList(node.Operands)
+	if err != nil || !changed {
+		return node, err
+	}
+	c := *node
+	c.Operands = operands
+	return &c, nil
+}
+
+// VisitSelect 改写 SELECT 的各个子句
+func (s *SqlShuttle) VisitSelect(node *SqlSelect) (interface{}, error) {
+	c := *node
+	changed := false
+
+	for i, hint := range node.Hints {
+		newNode, err := s.visitChild(hint)
+		if err != nil {
+			return nil, err
+		}
+		newHint, ok := newNode.(*SqlHint)
+		if !ok {
+			return nil, fmt.Errorf("HINT 只能改写为 *SqlHint，实际为 %T", newNode)
+		}
+		if newHint != hint {
+			if !changed {
+				c.Hints = append([]*SqlHint{}, node.Hints...)
+				changed = true
+			}
+			c.Hints[i] = newHint
+		}
+	}
+
+	lists := []*[]SqlNode{&c.SelectList, &c.GroupBy, &c.WindowDecls, &c.OrderBy}
+	for _, list := range lists {
+		rewritten, listChanged, err := s.visitList(*list)
+		if err != nil {
+			return nil, err
+		}
+		*list = rewritten
+		changed = changed || listChanged
+	}
+
+	fields := []*SqlNode{&c.From, &c.Where, &c.Having, &c.Offset, &c.Fetch}
+	for _, field := range fields {
+		rewritten, err := s.visitChild(*field)
+		if err != nil {
+			return nil, err
+		}
+		changed = changed || rewritten != *field
+		*field = rewritten
+	}
+
+	if !changed {
+		return node, nil
+	}
+	return &c, nil
+}
+
+// VisitJoin 改写左右两侧、连接条件和 USING 列表
+func (s *SqlShuttle) VisitJoin(node *SqlJoin) (interface{}, error) {
+	left, err := s.visitChild(node.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := s.visitChild(node.Right)
+	if err != nil {
+		return nil, err
+	}
+	condition, err := s.visitChild(node.Condition)
+	if err != nil {
+		return nil, err
+	}
+	using, usingChanged, err := s.visitList(node.Using)
+	if err != nil {
+		return nil, err
+	}
+
+	if left == node.Left && right == node.Right && condition == node.Condition && !usingChanged {
+		return node, nil
+	}
+	c := *node
+	c.Left, c.Right, c.Condition, c.Using = left, right, condition, using
+	return &c, nil
+}
+
+// VisitBasicCall 改写操作数
+func (s *SqlShuttle) VisitBasicCall(node *SqlBasicCall) (interface{}, error) {
+	operand, err := s.visitChild(node.Operand)
+	if err != nil || operand == node.Operand {
+		return node, err
+	}
+	c := *node
+	c.Operand = operand
+	return &c, nil
+}
+
+// VisitNodeList 改写列表元素
+func (s *SqlShuttle) VisitNodeList(node *SqlNodeList) (interface{}, error) {
+	list, changed, err := s.visitList(node.List)
+	if err != nil || !changed {
+		return node, err
+	}
+	c := *node
+	c.List = list
+	return &c, nil
+}
+
+// VisitHint 改写 HINT 参数
+func (s *SqlShuttle) VisitHint(node *SqlHint) (interface{}, error) {
+	params, changed, err := s.visitList(node.Parameters)
+	if err != nil || !changed {
+		return node, err
+	}
+	c := *node
+	c.Parameters = params
+	return &c, nil
+}
+
+// VisitLambda 改写参数和函数体
+func (s *SqlShuttle) VisitLambda(node *SqlLambda) (interface{}, error) {
+	changed := false
+	params := node.Parameters
+	for i, param := range node.Parameters {
+		newParam, err := s.visitIdentifierChild(param)
+		if err != nil {
+			return nil, err
+		}
+		if newParam != param {
+			if !changed {
+				params = append([]*SqlIdentifier{}, node.Parameters...)
+			}
+			params[i] = newParam
+			changed = true
+		}
+	}
+
+	body, err := s.visitChild(node.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !changed && body == node.Body {
+		return node, nil
+	}
+	c := *node
+	c.Parameters, c.Body = params, body
+	return &c, nil
+}
+
+// VisitTableRef 改写表名以及时间旅行、采样子句中的表达式
+func (s *SqlShuttle) VisitTableRef(node *SqlTableRef) (interface{}, error) {
+	name, err := s.visitIdentifierChild(node.Name)
+	if err != nil {
+		return nil, err
+	}
+	c := *node
+	c.Name = name
+	changed := name != node.Name
+
+	if node.Temporal != nil {
+		value, err := s.visitChild(node.Temporal.Value)
+		if err != nil {
+			return nil, err
+		}
+		if value != node.Temporal.Value {
+			temporal := *node.Temporal
+			temporal.Value = value
+			c.Temporal = &temporal
+			changed = true
+		}
+	}
+
+	if node.Sample != nil {
+		value, err := s.visitChild(node.Sample.Value)
+		if err != nil {
+			return nil, err
+		}
+		bucketOn, err := s.visitChild(node.Sample.BucketOn)
+		if err != nil {
+			return nil, err
+		}
+		if value != node.Sample.Value || bucketOn != node.Sample.BucketOn {
+			sample := *node.Sample
+			sample.Value, sample.BucketOn = value, bucketOn
+			c.Sample = &sample
+			changed = true
+		}
+	}
+
+	if !changed {
+		return node, nil
+	}
+	return &c, nil
+}
+
+// VisitExplain 改写被解释的语句
+func (s *SqlShuttle) VisitExplain(node *SqlExplain) (interface{}, error) {
+	statement, err := s.visitChild(node.Statement)
+	if err != nil || statement == node.Statement {
+		return node, err
+	}
+	c := *node
+	c.Statement = statement
+	return &c, nil
+}
+
+// VisitDescribe 改写表名、列名和查询
+func (s *SqlShuttle) VisitDescribe(node *SqlDescribe) (interface{}, error) {
+	table, err := s.visitIdentifierChild(node.Table)
+	if err != nil {
+		return nil, err
+	}
+	column, err := s.visitIdentifierChild(node.Column)
+	if err != nil {
+		return nil, err
+	}
+	query, err := s.visitChild(node.Query)
+	if err != nil {
+		return nil, err
+	}
+	if table == node.Table && column == node.Column && query == node.Query {
+		return node, nil
+	}
+	c := *node
+	c.Table, c.Column, c.Query = table, column, query
+	return &c, nil
+}
+
+// VisitShow 改写目标对象和命名空间
+func (s *SqlShuttle) VisitShow(node *SqlShow) (interface{}, error) {
+	object, err := s.visitIdentifierChild(node.Object)
+	if err != nil {
+		return nil, err
+	}
+	namespace, err := s.visitIdentifierChild(node.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if object == node.Object && namespace == node.Namespace {
+		return node, nil
+	}
+	c := *node
+	c.Object, c.Namespace = object, namespace
+	return &c, nil
+}
+
+// VisitUse 改写命名空间
+func (s *SqlShuttle) VisitUse(node *SqlUse) (interface{}, error) {
+	namespace, err := s.visitIdentifierChild(node.Namespace)
+	if err != nil || namespace == node.Namespace {
+		return node, err
+	}
+	c := *node
+	c.Namespace = namespace
+	return &c, nil
+}
+
+// VisitError 错误节点没有子节点，返回原节点
+func (s *SqlShuttle) VisitError(node *SqlErrorNode) (interface{}, error) {
+	return node, nil
+}
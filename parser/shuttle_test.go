@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"testing"
+)
+
+// columnMasker 把指定列替换为 MASK(列)
+type columnMasker struct {
+	*SqlShuttle
+	column string
+}
+
+func (m *columnMasker) VisitIdentifier(node *SqlIdentifier) (interface{}, error) {
+	if node.ToString() != m.column {
+		return node, nil
+	}
+	op := &SqlOperator{Name: "MASK", Kind: SqlKindCall, Syntax: SyntaxFunction}
+	return NewSqlCall(op, []SqlNode{node}, node.Pos), nil
+}
+
+// predicateInjector 为每个 SELECT 追加过滤条件
+type predicateInjector struct {
+	*SqlShuttle
+	predicate SqlNode
+}
+
+func (p *predicateInjector) VisitSelect(node *SqlSelect) (interface{}, error) {
+	result, err := p.SqlShuttle.VisitSelect(node)
+	if err != nil {
+		return nil, err
+	}
+	sel := *result.(*SqlSelect)
+	sel.Where = andNodes(sel.Where, p.predicate.Clone())
+	return &sel, nil
+}
+
+// buildShuttleTestTree 构建 SELECT t.name, t.ssn AS ssn FROM t WHERE t.age = 18
+func buildShuttleTestTree() *SqlSelect {
+	sel := NewSqlSelect(&SqlParserPos{})
+	sel.SelectList = []SqlNode{
+		NewSqlIdentifier([]string{"t", "name"}, nil),
+		writerAlias(NewSqlIdentifier([]string{"t", "ssn"}, nil), "ssn"),
+	}
+	sel.From = NewSqlIdentifier([]string{"t"}, nil)
+	sel.Where = NewSqlCall(&SqlOperator{Name: "=", Kind: SqlKindEquals, Syntax: SyntaxBinary},
+		[]SqlNode{NewSqlIdentifier([]string{"t", "age"}, nil), NewSqlLiteral(int64(18), LiteralInteger, nil)}, nil)
+	return sel
+}
+
+// TestSqlShuttleIdentity 测试默认实现不改变树且不复制节点
+func TestSqlShuttleIdentity(t *testing.T) {
+	original := buildShuttleTestTree()
+
+	rewritten, err := Rewrite(original, NewSqlShuttle(nil))
+	if err != nil {
+		t.Fatalf("改写失败: %v", err)
+	}
+	if rewritten != original {
+		t.Errorf("没有变化时应返回原节点")
+	}
+}
+
+// TestSqlShuttleColumnMasking 测试只复制变化的路径
+func TestSqlShuttleColumnMasking(t *testing.T) {
+	original := buildShuttleTestTree()
+	before := original.ToString()
+
+	masker := &columnMasker{column: "t.ssn"}
+	masker.SqlShuttle = NewSqlShuttle(masker)
+	rewritten, err := Rewrite(original, masker)
+	if err != nil {
+		t.Fatalf("改写失败: %v", err)
+	}
+
+	if got := rewritten.ToString(); got != "SELECT t.name, MASK(t.ssn) AS ssn FROM t WHERE t.age = 18" {
+		t.Errorf("改写结果 = %q", got)
+	}
+	if got := original.ToString(); got != before {
+		t.Errorf("原树被修改: %q", got)
+	}
+
+	sel := rewritten.(*SqlSelect)
+	if sel == original {
+		t.Fatalf("有变化时应返回新节点")
+	}
+	if sel.SelectList[0] != original.SelectList[0] || sel.Where != original.Where || sel.From != original.From {
+		t.Errorf("未变化的子树应与原树共享")
+	}
+}
+
+// TestSqlShuttlePredicateInjection 测试覆盖 VisitSelect 并调用默认实现
+func TestSqlShuttlePredicateInjection(t *testing.T) {
+	original := buildShuttleTestTree()
+	subquery := buildShuttleTestTree()
+	original.From = NewSqlCall(&SqlOperator{Name: "AS", Kind: SqlKindAs, Syntax: SyntaxSpecial},
+		[]SqlNode{subquery, NewSqlIdentifier([]string{"t"}, nil)}, nil)
+
+	injector := &predicateInjector{
+		predicate: NewSqlCall(&SqlOperator{Name: "=", Kind: SqlKindEquals, Syntax: SyntaxBinary},
+			[]SqlNode{NewSqlIdentifier([]string{"tenant_id"}, nil), NewSqlLiteral(int64(7), LiteralInteger, nil)}, nil),
+	}
+	injector.SqlShuttle = NewSqlShuttle(injector)
+	rewritten, err := Rewrite(original, injector)
+	if err != nil {
+		t.Fatalf("改写失败: %v", err)
+	}
+
+	sel := rewritten.(*SqlSelect)
+	if got := sel.Where.ToString(); got != "t.age = 18 AND tenant_id = 7" {
+		t.Errorf("外层 WHERE = %q", got)
+	}
+	inner := sel.From.(*SqlCall).Operands[0].(*SqlSelect)
+	if got := inner.Where.ToString(); got != "t.age = 18 AND tenant_id = 7" {
+		t.Errorf("子查询 WHERE = %q", got)
+	}
+	if got := subquery.Where.ToString(); got != "t.age = 18" {
+		t.Errorf("原子查询被修改: %q", got)
+	}
+}
+
+// TestSqlShuttleTypeCheck 测试类型受限的子节点改写为其他类型时报错
+func TestSqlShuttleTypeCheck(t *testing.T) {
+	tableRef := NewSqlTableRef(NewSqlIdentifier([]string{"t", "ssn"}, nil), nil, nil, nil)
+
+	masker := &columnMasker{column: "t.ssn"}
+	masker.SqlShuttle = NewSqlShuttle(masker)
+	if _, err := Rewrite(tableRef, masker); err == nil {
+		t.Errorf("表名改写为函数调用时应返回错误")
+	}
+}
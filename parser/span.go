@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+)
+
+// =============================================================================
+// 源码位置 - 字节偏移、合成节点和原文
+// =============================================================================
+
+// sourceIndex 把 ANTLR 的字符下标转换为字节偏移
+type sourceIndex struct {
+	offsets []int // 第 i 个字符的字节偏移，末尾多一项为总长度；纯 ASCII 时为 nil，下标即偏移
+	length  int   // 字节长度
+}
+
+// newSourceIndex 根据输入流创建转换表
+func newSourceIndex(input antlr4.CharStream) *sourceIndex {
+	if input == nil || input.Size() == 0 {
+		return &sourceIndex{}
+	}
+	text := input.GetText(0, input.Size()-1)
+	index := &sourceIndex{length: len(text)}
+	if utf8.RuneCountInString(text) == len(text) {
+		return index
+	}
+	index.offsets = make([]int, 0, input.Size()+1)
+	for offset := range text {
+		index.offsets = append(index.offsets, offset)
+	}
+	index.offsets = append(index.offsets, len(text))
+	return index
+}
+
+// byteOffset 返回第 runeIndex 个字符的字节偏移，超出范围时截断到输入的首尾
+func (s *sourceIndex) byteOffset(runeIndex int) int {
+	if runeIndex <= 0 {
+		return 0
+	}
+	if s.offsets == nil {
+		return min(runeIndex, s.length)
+	}
+	if runeIndex >= len(s.offsets) {
+		return s.length
+	}
+	return s.offsets[runeIndex]
+}
+
+// tokenEnd 计算 token 之后的位置
+func tokenEnd(token antlr4.Token) sourcePoint {
+	return textEnd(sourcePoint{token.GetLine(), token.GetColumn()}, token.GetText())
+}
+
+// textEnd 计算从 start 开始的 text 之后的位置，按文本中的换行和字符数计算，可跨行
+func textEnd(start sourcePoint, text string) sourcePoint {
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		return sourcePoint{start.line + strings.Count(text, "\n"), utf8.RuneCountInString(text[i+1:])}
+	}
+	return sourcePoint{start.line, start.column + utf8.RuneCountInString(text)}
+}
+
+// syntheticPos 为合成节点创建位置信息：覆盖所有带位置的子节点，并标记为合成
+// 子节点都没有位置时只有 Synthetic 标记
+func syntheticPos(children ...SqlNode) *SqlParserPos {
+	pos := &SqlParserPos{Synthetic: true}
+	for _, child := range children {
+		if child == nil {
+			continue
+		}
+		childPos := child.GetPos()
+		if childPos == nil || childPos.LineNumber == 0 {
+			continue
+		}
+		start := sourcePoint{childPos.LineNumber, childPos.ColumnNumber}
+		end := sourcePoint{childPos.EndLine, childPos.EndColumn}
+		if pos.LineNumber == 0 || start.before(sourcePoint{pos.LineNumber, pos.ColumnNumber}) {
+			pos.LineNumber, pos.ColumnNumber, pos.StartOffset = start.line, start.column, childPos.StartOffset
+		}
+		if pos.EndLine == 0 || (sourcePoint{pos.EndLine, pos.EndColumn}).before(end) {
+			pos.EndLine, pos.EndColumn, pos.EndOffset = end.line, end.column, childPos.EndOffset
+		}
+	}
+	return pos
+}
+
+// SourceText 返回节点在原始 SQL 中对应的文本
+// 合成节点返回其子节点覆盖的文本；没有位置信息或位置超出 SQL 范围时返回空字符串
+func (r *SQLParserResult) SourceText(node SqlNode) string {
+	if r == nil || node == nil {
+		return ""
+	}
+	pos := node.GetPos()
+	if pos == nil || pos.LineNumber == 0 || pos.StartOffset > pos.EndOffset || pos.EndOffset > len(r.SQL) {
+		return ""
+	}
+	return r.SQL[pos.StartOffset:pos.EndOffset]
+}
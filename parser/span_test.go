@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"testing"
+
+	antlr4 "github.com/antlr4-go/antlr/v4"
+)
+
+// TestSourceIndex 测试字符下标到字节偏移的转换
+func TestSourceIndex(t *testing.T) {
+	ascii := newSourceIndex(antlr4.NewInputStream("select 1"))
+	if ascii.offsets != nil {
+		t.Errorf("纯 ASCII 输入不需要转换表")
+	}
+	if got := ascii.byteOffset(7); got != 7 {
+		t.Errorf("byteOffset(7) = %d", got)
+	}
+	if got := ascii.byteOffset(100); got != 8 {
+		t.Errorf("超出范围应截断到末尾, 实际为 %d", got)
+	}
+
+	// '中文' 中每个汉字占 3 个字节
+	index := newSourceIndex(antlr4.NewInputStream("select '中文', a"))
+	tests := map[int]int{0: 0, 8: 8, 9: 11, 10: 14, 13: 17, 14: 18, 15: 18}
+	for runeIndex, expected := range tests {
+		if got := index.byteOffset(runeIndex); got != expected {
+			t.Errorf("byteOffset(%d) = %d, 期望 %d", runeIndex, got, expected)
+		}
+	}
+}
+
+// TestSyntheticPos 测试合成节点的位置覆盖所有子节点
+func TestSyntheticPos(t *testing.T) {
+	left := positioned(writerIdent("a"), 1, 14, 1, 15)
+	left.GetPos().StartOffset, left.GetPos().EndOffset = 14, 15
+	right := positioned(writerIdent("b"), 2, 0, 2, 1)
+	right.GetPos().StartOffset, right.GetPos().EndOffset = 17, 18
+
+	pos := syntheticPos(right, nil, left, writerIdent("c"))
+	expected := SqlParserPos{LineNumber: 1, ColumnNumber: 14, EndLine: 2, EndColumn: 1, StartOffset: 14, EndOffset: 18, Synthetic: true}
+	if *pos != expected {
+		t.Errorf("syntheticPos = %+v, 期望 %+v", *pos, expected)
+	}
+	if pos := syntheticPos(); *pos != (SqlParserPos{Synthetic: true}) {
+		t.Errorf("没有子节点时 = %+v", *pos)
+	}
+
+	result := &SQLParserResult{SQL: "select * from a,\nb"}
+	join := NewSqlJoin(left, right, JoinComma, nil, syntheticPos(left, right))
+	if got := result.SourceText(join); got != "a,\nb" {
+		t.Errorf("SourceText = %q", got)
+	}
+	if got := result.SourceText(writerIdent("x")); got != "" {
+		t.Errorf("没有位置信息时应返回空字符串, 实际为 %q", got)
+	}
+}
+
+// TestParsedSpans 测试解析得到的节点位置覆盖整个语法规则
+func TestParsedSpans(t *testing.T) {
+	sql := "select t.a + 1 as x,\n  t.b\nfrom t, u\nwhere t.s = '中文' and t.b > 0"
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	sel, ok := result.SqlNode.(*SqlSelect)
+	if !ok {
+		t.Fatalf("根节点类型为 %T", result.SqlNode)
+	}
+
+	if got := result.SourceText(sel); got != sql {
+		t.Errorf("查询的原文 = %q", got)
+	}
+	if got := result.SourceText(sel.SelectList[0]); got != "t.a + 1 as x" {
+		t.Errorf("第一列的原文 = %q", got)
+	}
+	if got := result.SourceText(sel.Where); got != "t.s = '中文' and t.b > 0" {
+		t.Errorf("WHERE 的原文 = %q", got)
+	}
+	pos := sel.Where.GetPos()
+	if pos.LineNumber != 4 || pos.ColumnNumber != 6 || pos.EndLine != 4 || pos.EndColumn != 28 {
+		t.Errorf("WHERE 的位置 = %+v", pos)
+	}
+
+	// 逗号连接由解析器合成
+	join, ok := sel.From.(*SqlJoin)
+	if !ok || !join.Pos.Synthetic {
+		t.Fatalf("FROM t, u 应为合成的 JOIN, 实际为 %#v", sel.From)
+	}
+	if got := result.SourceText(join); got != "t, u" {
+		t.Errorf("合成 JOIN 的原文 = %q", got)
+	}
+
+	Inspect(sel, func(node SqlNode) bool {
+		if node == nil {
+			return false
+		}
+		if pos := node.GetPos(); pos != nil && !pos.Synthetic && result.SourceText(node) == "" {
+			t.Errorf("%s 没有原文", Unparse(node))
+		}
+		return true
+	})
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestTableRefTimeTravelAndSample 测试表引用上的时间旅行和 TABLESAMPLE 子句
+func TestTableRefTimeTravelAndSample(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sql      string
+		temporal SqlTemporalType
+		sample   SqlSampleMethod
+		toString string
+	}{
+		{
+			name:     "VERSION AS OF",
+			sql:      "select events.a from events version as of 3",
+			temporal: TemporalVersion,
+			toString: "events VERSION AS OF 3",
+		},
+		{
+			name:     "TIMESTAMP AS OF",
+			sql:      "select events.a from events timestamp as of '2024-01-01'",
+			temporal: TemporalTimestamp,
+			toString: "events TIMESTAMP AS OF '2024-01-01'",
+		},
+		{
+			name:     "百分比采样",
+			sql:      "select events.a from events tablesample (10 percent) repeatable (42)",
+			sample:   SamplePercent,
+			toString: "events TABLESAMPLE (10 PERCENT) REPEATABLE (42)",
+		},
+		{
+			name:     "行数采样",
+			sql:      "select events.a from events tablesample (100 rows)",
+			sample:   SampleRows,
+			toString: "events TABLESAMPLE (100 ROWS)",
+		},
+		{
+			name:     "分桶采样",
+			sql:      "select events.a from events tablesample (bucket 1 out of 4 on id)",
+			sample:   SampleBucket,
+			toString: "events TABLESAMPLE (BUCKET 1 OUT OF 4 ON id)",
+		},
+		{
+			name:     "时间旅行加采样",
+			sql:      "select events.a from events version as of 3 tablesample (10 percent)",
+			temporal: TemporalVersion,
+			sample:   SamplePercent,
+			toString: "events VERSION AS OF 3 TABLESAMPLE (10 PERCENT)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseSQLWithAntlr(tc.sql)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+
+			sqlSelect := result.SqlNode.(*SqlSelect)
+			tableRef, ok := sqlSelect.From.(*SqlTableRef)
+			if !ok {
+				t.Fatalf("期望 SqlTableRef，实际得到: %T", sqlSelect.From)
+			}
+
+			if tc.temporal != "" {
+				if tableRef.Temporal == nil || tableRef.Temporal.Type != tc.temporal {
+					t.Errorf("期望时间旅行类型 %s，实际得到: %v", tc.temporal, tableRef.Temporal)
+				}
+			} else if tableRef.IsTimeTravel() {
+				t.Errorf("不应包含时间旅行子句: %v", tableRef.Temporal)
+			}
+
+			if tc.sample != "" {
+				if tableRef.Sample == nil || tableRef.Sample.Method != tc.sample {
+					t.Errorf("期望采样方式 %s，实际得到: %v", tc.sample, tableRef.Sample)
+				}
+			} else if tableRef.IsSampled() {
+				t.Errorf("不应包含采样子句: %v", tableRef.Sample)
+			}
+
+			if got := tableRef.ToString(); got != tc.toString {
+				t.Errorf("期望 %q，实际得到: %q", tc.toString, got)
+			}
+		})
+	}
+}
+
+// TestTableRefWithAlias 测试带别名的时间旅行表引用
+func TestTableRefWithAlias(t *testing.T) {
+	sql := "select e.a from events version as of 3 e"
+
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	sqlSelect := result.SqlNode.(*SqlSelect)
+	if got := sqlSelect.From.ToString(); got != "events VERSION AS OF 3 AS e" {
+		t.Errorf("FROM 子句 ToString 不符合预期: %s", got)
+	}
+
+	tables, err := ExtractTableNames(result.SqlNode)
+	if err != nil {
+		t.Fatalf("提取表名失败: %v", err)
+	}
+	if len(tables) != 1 || tables[0] != "events" {
+		t.Errorf("期望表名 [events]，实际得到: %v", tables)
+	}
+}
@@ -0,0 +1,6 @@
+select a, count(*) as cnt
+from db.t
+where a >= 1 and b is not null
+group by a
+order by cnt desc
+limit 10
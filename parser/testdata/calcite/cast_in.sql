@@ -0,0 +1,3 @@
+select distinct cast(x as DECIMAL(10,2))
+from t
+where y in (1, 'a') and -z < 0.5
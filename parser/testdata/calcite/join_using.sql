@@ -0,0 +1,3 @@
+select t.id, u.name
+from t left join u using (id)
+join v on t.id = v.id
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestUnsupportedFeatureStrict 测试严格模式下不支持的语法返回 UnsupportedFeatureError
+func TestUnsupportedFeatureStrict(t *testing.T) {
+	sql := "select events.a from events lateral view explode(events.arr) t as x"
+
+	result, err := ParseSQLStrict(sql)
+	var unsupportedErr *UnsupportedFeatureError
+	if !errors.As(err, &unsupportedErr) {
+		t.Fatalf("期望 *UnsupportedFeatureError，实际得到: %v", err)
+	}
+	if unsupportedErr.Rule != "lateralView" {
+		t.Errorf("期望规则名 lateralView，实际得到: %s", unsupportedErr.Rule)
+	}
+	if unsupportedErr.Pos == nil || unsupportedErr.Pos.LineNumber != 1 {
+		t.Errorf("期望包含位置信息，实际得到: %v", unsupportedErr.Pos)
+	}
+	if result == nil || result.Success {
+		t.Error("严格模式下存在不支持的语法时 Success 应为 false")
+	}
+}
+
+// TestUnsupportedFeatureLenient 测试宽松模式下不支持的语法记录为警告
+func TestUnsupportedFeatureLenient(t *testing.T) {
+	sql := "select events.a from events lateral view explode(events.arr) t as x"
+
+	result, err := ParseSQLWithAntlr(sql)
+	if err != nil {
+		t.Fatalf("宽松模式不应返回错误: %v", err)
+	}
+	if len(result.Warnings) == 0 {
+		t.Fatal("期望记录不支持语法的警告")
+	}
+
+	var unsupportedErr *UnsupportedFeatureError
+	if !errors.As(result.Warnings[0], &unsupportedErr) {
+		t.Errorf("期望警告为 *UnsupportedFeatureError，实际得到: %T", result.Warnings[0])
+	}
+}
+
+// TestUnsupportedPredicateLenient 测试宽松模式下不支持的谓词保留原文，不会只剩左侧的值
+func TestUnsupportedPredicateLenient(t *testing.T) {
+	result, err := ParseSQLWithAntlr("select t.a from t where t.b = 1 and t.a like 'x%'")
+	if err != nil {
+		t.Fatalf("宽松模式不应返回错误: %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("期望 1 个警告，实际得到: %v", result.Warnings)
+	}
+	where := result.SqlNode.(*SqlSelect).Where.(*SqlCall)
+	if _, ok := where.Operands[1].(*SqlErrorNode); !ok {
+		t.Errorf("不支持的谓词应为 SqlErrorNode，实际得到: %T", where.Operands[1])
+	}
+	if got := Unparse(result.SqlNode); got != "SELECT t.a FROM t WHERE t.b = 1 AND t.a like 'x%'" {
+		t.Errorf("Unparse = %q", got)
+	}
+}
+
+// TestSupportedSQLHasNoWarnings 测试完全支持的 SQL 不产生警告
+func TestSupportedSQLHasNoWarnings(t *testing.T) {
+	result, err := ParseSQLStrict("select events.a from events where events.b = 1")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("不应有警告，实际得到: %v", result.Warnings)
+	}
+}
+
+// TestRuleNameOf 测试规则名称的推导
+func TestRuleNameOf(t *testing.T) {
+	type SetOperationContext struct{}
+	if got := ruleNameOf(&SetOperationContext{}); got != "setOperation" {
+		t.Errorf("期望 setOperation，实际得到: %s", got)
+	}
+}
+
+// TestExplicitJoinStrict 测试显式 JOIN 在严格模式下解析为左深的 SqlJoin 树
+func TestExplicitJoinStrict(t *testing.T) {
+	sql := "select a.id from db.a a left outer join b on a.id = b.id cross join c full join d using (id, dt)"
+
+	result, err := ParseSQLStrict(sql)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("不应有警告，实际得到: %v", result.Warnings)
+	}
+
+	full, ok := result.SqlNode.(*SqlSelect).From.(*SqlJoin)
+	if !ok || full.JoinType != JoinFull || len(full.Using) != 2 || full.Condition != nil {
+		t.Fatalf("最外层应为 FULL JOIN ... USING (id, dt)，实际得到: %s", Unparse(result.SqlNode))
+	}
+	cross, ok := full.Left.(*SqlJoin)
+	if !ok || cross.JoinType != JoinCross || cross.Condition != nil {
+		t.Fatalf("第二层应为 CROSS JOIN，实际得到: %s", Unparse(full.Left))
+	}
+	left, ok := cross.Left.(*SqlJoin)
+	if !ok || left.JoinType != JoinLeft || left.Condition == nil {
+		t.Fatalf("最内层应为 LEFT JOIN ... ON，实际得到: %s", Unparse(cross.Left))
+	}
+
+	expected := "SELECT a.id FROM db.a AS a LEFT JOIN b ON a.id = b.id CROSS JOIN c FULL JOIN d USING (id, dt)"
+	if got := Unparse(result.SqlNode); got != expected {
+		t.Errorf("Unparse = %q, 期望 %q", got, expected)
+	}
+	if got := result.SourceText(left); got != "db.a a left outer join b on a.id = b.id" {
+		t.Errorf("LEFT JOIN 的原文 = %q", got)
+	}
+}
+
+// TestUnsupportedJoinStrict 测试尚不支持的 JOIN 形式在严格模式下返回 UnsupportedFeatureError
+func TestUnsupportedJoinStrict(t *testing.T) {
+	sqls := []string{
+		"select a.id from a left semi join b on a.id = b.id",
+		"select a.id from a anti join b on a.id = b.id",
+		"select a.id from a natural join b",
+	}
+	for _, sql := range sqls {
+		_, err := ParseSQLStrict(sql)
+		var unsupportedErr *UnsupportedFeatureError
+		if !errors.As(err, &unsupportedErr) {
+			t.Errorf("%q: 期望 *UnsupportedFeatureError，实际得到: %v", sql, err)
+		}
+	}
+}
+
+// TestUnsupportedJoinLenient 测试宽松模式下不支持的 JOIN 保留原文，不会只剩左侧的表
+func TestUnsupportedJoinLenient(t *testing.T) {
+	result, err := ParseSQLWithAntlr("select a.id from a left anti join b on a.id = b.id join c on a.id = c.id")
+	if err != nil {
+		t.Fatalf("宽松模式不应返回错误: %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("期望 1 个警告，实际得到: %v", result.Warnings)
+	}
+	join, ok := result.SqlNode.(*SqlSelect).From.(*SqlJoin)
+	if !ok {
+		t.Fatalf("FROM 应为 JOIN，实际得到: %s", Unparse(result.SqlNode))
+	}
+	if errNode, ok := join.Left.(*SqlErrorNode); !ok || errNode.Text != "a left anti join b on a.id = b.id" {
+		t.Errorf("不支持的 JOIN 应为覆盖原文的 SqlErrorNode，实际得到: %#v", join.Left)
+	}
+	if got := Unparse(result.SqlNode); got != "SELECT a.id FROM a left anti join b on a.id = b.id INNER JOIN c ON a.id = c.id" {
+		t.Errorf("Unparse = %q", got)
+	}
+}
+
+// TestSelectDistinct 测试 SELECT DISTINCT 保留在 KeywordList 中
+func TestSelectDistinct(t *testing.T) {
+	result, err := ParseSQLStrict("select distinct t.a from t")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	sel := result.SqlNode.(*SqlSelect)
+	if len(sel.KeywordList) != 1 || sel.KeywordList[0] != "DISTINCT" {
+		t.Errorf("KeywordList = %v, 期望 [DISTINCT]", sel.KeywordList)
+	}
+	if got := Unparse(sel); got != "SELECT DISTINCT t.a FROM t" {
+		t.Errorf("Unparse = %q", got)
+	}
+}
+
+// TestUnsupportedFunctionModifiers 测试函数调用的 DISTINCT、FILTER、OVER 不会被静默丢弃
+func TestUnsupportedFunctionModifiers(t *testing.T) {
+	cases := []struct {
+		sql  string
+		text string
+	}{
+		{"select count(distinct t.a) as c from t", "count(distinct t.a)"},
+		{"select sum(t.a) filter (where t.b > 0) as c from t", "sum(t.a) filter (where t.b > 0)"},
+		{"select row_number() over (partition by t.b order by t.a) as c from t", "row_number() over (partition by t.b order by t.a)"},
+	}
+	for _, c := range cases {
+		_, err := ParseSQLStrict(c.sql)
+		var unsupportedErr *UnsupportedFeatureError
+		if !errors.As(err, &unsupportedErr) {
+			t.Errorf("%q: 期望 *UnsupportedFeatureError，实际得到: %v", c.sql, err)
+		}
+
+		result, err := ParseSQLWithAntlr(c.sql)
+		if err != nil {
+			t.Fatalf("%q: 宽松模式不应返回错误: %v", c.sql, err)
+		}
+		alias := result.SqlNode.(*SqlSelect).SelectList[0].(*SqlCall)
+		if errNode, ok := alias.Operands[0].(*SqlErrorNode); !ok || errNode.Text != c.text {
+			t.Errorf("%q: 函数调用应为覆盖原文的 SqlErrorNode，实际得到: %#v", c.sql, alias.Operands[0])
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"testing"
+)
+
+// TestUtilityStatements 测试 EXPLAIN / DESCRIBE / SHOW / USE 等工具语句
+func TestUtilityStatements(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sql      string
+		kind     SqlKind
+		toString string
+	}{
+		{
+			name:     "EXPLAIN",
+			sql:      "explain select events.a from events",
+			kind:     SqlKindExplain,
+			toString: "EXPLAIN SELECT events.a FROM events",
+		},
+		{
+			name:     "EXPLAIN带模式",
+			sql:      "explain extended select events.a from events",
+			kind:     SqlKindExplain,
+			toString: "EXPLAIN EXTENDED SELECT events.a FROM events",
+		},
+		{
+			name:     "DESCRIBE表",
+			sql:      "describe table extended db.events",
+			kind:     SqlKindDescribe,
+			toString: "DESCRIBE EXTENDED db.events",
+		},
+		{
+			name:     "DESCRIBE QUERY",
+			sql:      "describe query select events.a from events",
+			kind:     SqlKindDescribe,
+			toString: "DESCRIBE QUERY SELECT events.a FROM events",
+		},
+		{
+			name:     "SHOW TABLES",
+			sql:      "show tables in db like 'ev*'",
+			kind:     SqlKindShow,
+			toString: "SHOW TABLES IN db LIKE 'ev*'",
+		},
+		{
+			name:     "SHOW COLUMNS",
+			sql:      "show columns in events",
+			kind:     SqlKindShow,
+			toString: "SHOW COLUMNS IN events",
+		},
+		{
+			name:     "SHOW DATABASES",
+			sql:      "show databases",
+			kind:     SqlKindShow,
+			toString: "SHOW DATABASES",
+		},
+		{
+			name:     "USE",
+			sql:      "use db",
+			kind:     SqlKindUse,
+			toString: "USE db",
+		},
+		{
+			name:     "USE DATABASE",
+			sql:      "use database db",
+			kind:     SqlKindUse,
+			toString: "USE DATABASE db",
+		},
+		{
+			name:     "SET CATALOG",
+			sql:      "set catalog hive",
+			kind:     SqlKindSetCatalog,
+			toString: "SET CATALOG hive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseSQLWithAntlr(tc.sql)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+
+			if result.SqlNode.GetKind() != tc.kind {
+				t.Errorf("期望类型 %s，实际得到: %s", tc.kind, result.SqlNode.GetKind())
+			}
+			if got := result.SqlNode.ToString(); got != tc.toString {
+				t.Errorf("期望 %q，实际得到: %q", tc.toString, got)
+			}
+		})
+	}
+}
+
+// TestExplainWrapsStatement 测试 EXPLAIN 包装内部语句
+func TestExplainWrapsStatement(t *testing.T) {
+	result, err := ParseSQLWithAntlr("explain cost select events.a from events")
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+
+	explain, ok := result.SqlNode.(*SqlExplain)
+	if !ok {
+		t.Fatalf("期望 SqlExplain，实际得到: %T", result.SqlNode)
+	}
+	if explain.Mode != "COST" {
+		t.Errorf("期望模式 COST，实际得到: %s", explain.Mode)
+	}
+	if _, ok := explain.Statement.(*SqlSelect); !ok {
+		t.Errorf("期望内部语句为 SqlSelect，实际得到: %T", explain.Statement)
+	}
+}
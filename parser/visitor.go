@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"fmt"
+	"context"
 	"strconv"
 	"strings"
 
@@ -21,9 +21,16 @@ type SqlNodeBuilderVisitor struct {
 	assetMap           map[string]string     // 资产映射（别名到表名）
 	currentAssetKey    string                // 当前资产键
 	currentIdentifiers []string              // 当前标识符列表
-	joinConditions     []*SqlCall            // JOIN 条件列表
-	filterConditions   []*SqlCall            // FILTER 条件列表
 	variableSet        map[string]bool       // 变量集合
+	lambdaScopes       []map[string]bool     // lambda 参数作用域栈
+	recoverErrors      bool                  // 恢复模式：为出错区域生成 SqlErrorNode
+	unsupported        []*UnsupportedFeatureError // 遇到的不支持语法
+	ctx                context.Context       // 取消信号，可为 nil
+	maxRecursion       int                   // 最大递归深度，0 表示不限制
+	depth              int                   // 当前递归深度
+	maxNodes           int                   // 最大节点数，0 表示不限制
+	seenNodes          map[SqlNode]struct{}  // 已统计的节点
+	source             *sourceIndex          // 字符下标到字节偏移的转换，首次计算位置时创建
 }
 
 // NewSqlNodeBuilderVisitor 创建新的 Visitor
@@ -37,8 +44,6 @@ func NewSqlNodeBuilderVisitor() *SqlNodeBuilderVisitor {
 		subQueryTables:     make(map[string]SqlNode),
 		assetMap:           make(map[string]string),
 		currentIdentifiers: []string{},
-		joinConditions:     []*SqlCall{},
-		filterConditions:   []*SqlCall{},
 		variableSet:        make(map[string]bool),
 	}
 	return v
@@ -59,9 +64,32 @@ func (v *SqlNodeBuilderVisitor) VisitSingleStatement(ctx *antlr.SingleStatementC
 		return nil
 	}
 	
-	// 检查是否为 StatementDefault (查询语句)
-	if defaultCtx, ok := stmtCtx.(*antlr.StatementDefaultContext); ok {
-		return v.VisitStatementDefault(defaultCtx)
+	return v.visitStatementInternal(stmtCtx)
+}
+
+// visitStatementInternal 内部辅助方法，按语句类型分派
+func (v *SqlNodeBuilderVisitor) visitStatementInternal(ctx antlr.IStatementContext) interface{} {
+	switch stmtCtx := ctx.(type) {
+	case *antlr.StatementDefaultContext:
+		// 查询语句
+		return v.VisitStatementDefault(stmtCtx)
+	case *antlr.ExplainContext:
+		return v.VisitExplain(stmtCtx)
+	case *antlr.DescribeRelationContext:
+		return v.VisitDescribeRelation(stmtCtx)
+	case *antlr.DescribeQueryContext:
+		return v.VisitDescribeQuery(stmtCtx)
+	case *antlr.UseContext:
+		return v.VisitUse(stmtCtx)
+	case *antlr.UseNamespaceContext:
+		return v.VisitUseNamespace(stmtCtx)
+	case *antlr.SetCatalogContext:
+		return v.VisitSetCatalog(stmtCtx)
+	case *antlr.ShowNamespacesContext, *antlr.ShowTablesContext, *antlr.ShowTableExtendedContext,
+		*antlr.ShowTblPropertiesContext, *antlr.ShowColumnsContext, *antlr.ShowViewsContext,
+		*antlr.ShowPartitionsContext, *antlr.ShowFunctionsContext, *antlr.ShowCreateTableContext,
+		*antlr.ShowCurrentNamespaceContext, *antlr.ShowCatalogsContext:
+		return v.visitShowInternal(stmtCtx)
 	}
 	
 	return v.newError("不支持的语句类型", ctx)
@@ -75,11 +103,292 @@ func (v *SqlNodeBuilderVisitor) VisitStatementDefault(ctx *antlr.StatementDefaul
 	return v.VisitQuery(ctx.Query())
 }
 
+// =============================================================================
+// 工具语句 (EXPLAIN / DESCRIBE / SHOW / USE)
+// =============================================================================
+
+// VisitExplain 访问 EXPLAIN 语句
+func (v *SqlNodeBuilderVisitor) VisitExplain(ctx *antlr.ExplainContext) interface{} {
+	if ctx == nil || ctx.Statement() == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	
+	mode := ""
+	switch {
+	case ctx.LOGICAL() != nil:
+		mode = "LOGICAL"
+	case ctx.FORMATTED() != nil:
+		mode = "FORMATTED"
+	case ctx.EXTENDED() != nil:
+		mode = "EXTENDED"
+	case ctx.CODEGEN() != nil:
+		mode = "CODEGEN"
+	case ctx.COST() != nil:
+		mode = "COST"
+	}
+	
+	// 内部语句不支持时直接返回错误
+	inner := v.visitStatementInternal(ctx.Statement())
+	statement, ok := inner.(SqlNode)
+	if !ok {
+		return inner
+	}
+	
+	return NewSqlExplain(mode, statement, pos)
+}
+
+// VisitDescribeRelation 访问 DESCRIBE TABLE 语句
+func (v *SqlNodeBuilderVisitor) VisitDescribeRelation(ctx *antlr.DescribeRelationContext) interface{} {
+	if ctx == nil || ctx.MultipartIdentifier() == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	describe := NewSqlDescribe(v.visitMultipartIdentifierInternal(ctx.MultipartIdentifier()), nil, pos)
+	
+	if ctx.GetOption() != nil {
+		describe.Option = strings.ToUpper(ctx.GetOption().GetText())
+	}
+	
+	if colCtx := ctx.DescribeColName(); colCtx != nil {
+		parts := strings.Split(colCtx.GetText(), ".")
+		describe.Column = NewSqlIdentifier(parts, v.getPositionFromContext(colCtx))
+	}
+	
+	// TODO: 处理 PARTITION 子句
+	
+	return describe
+}
+
+// VisitDescribeQuery 访问 DESCRIBE QUERY 语句
+func (v *SqlNodeBuilderVisitor) VisitDescribeQuery(ctx *antlr.DescribeQueryContext) interface{} {
+	if ctx == nil || ctx.Query() == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	
+	query, ok := v.VisitQuery(ctx.Query()).(SqlNode)
+	if !ok {
+		return nil
+	}
+	
+	return NewSqlDescribe(nil, query, pos)
+}
+
+// VisitUse 访问 USE 语句
+func (v *SqlNodeBuilderVisitor) VisitUse(ctx *antlr.UseContext) interface{} {
+	if ctx == nil || ctx.MultipartIdentifier() == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	return NewSqlUse(SqlKindUse, v.visitMultipartIdentifierInternal(ctx.MultipartIdentifier()), pos)
+}
+
+// VisitUseNamespace 访问 USE NAMESPACE/DATABASE/SCHEMA 语句
+func (v *SqlNodeBuilderVisitor) VisitUseNamespace(ctx *antlr.UseNamespaceContext) interface{} {
+	if ctx == nil || ctx.MultipartIdentifier() == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	use := NewSqlUse(SqlKindUse, v.visitMultipartIdentifierInternal(ctx.MultipartIdentifier()), pos)
+	if ctx.Namespace() != nil {
+		use.NamespaceType = strings.ToUpper(ctx.Namespace().GetText())
+	}
+	
+	return use
+}
+
+// VisitSetCatalog 访问 SET CATALOG 语句
+func (v *SqlNodeBuilderVisitor) VisitSetCatalog(ctx *antlr.SetCatalogContext) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	
+	catalog := ""
+	if ctx.Identifier() != nil {
+		catalog = ctx.Identifier().GetText()
+	} else if ctx.StringLit() != nil {
+		catalog = v.getStringLitText(ctx.StringLit())
+	}
+	if catalog == "" {
+		return nil
+	}
+	
+	return NewSqlUse(SqlKindSetCatalog, NewSqlIdentifier([]string{catalog}, pos), pos)
+}
+
+// visitShowInternal 访问 SHOW 系列语句
+func (v *SqlNodeBuilderVisitor) visitShowInternal(ctx antlr.IStatementContext) interface{} {
+	pos := v.getPositionFromContext(ctx)
+	
+	var show *SqlShow
+	switch c := ctx.(type) {
+	case *antlr.ShowNamespacesContext:
+		// SHOW DATABASES [IN ns] [LIKE pattern]
+		show = NewSqlShow(strings.ToUpper(c.Namespaces().GetText()), pos)
+		show.Namespace = v.visitMultipartIdentifierInternal(c.MultipartIdentifier())
+		show.Pattern = v.getStringLitText(c.GetPattern())
+	case *antlr.ShowTablesContext:
+		// SHOW TABLES [IN ns] [LIKE pattern]
+		show = NewSqlShow("TABLES", pos)
+		show.Namespace = v.visitMultipartIdentifierInternal(c.MultipartIdentifier())
+		show.Pattern = v.getStringLitText(c.GetPattern())
+	case *antlr.ShowTableExtendedContext:
+		// SHOW TABLE EXTENDED [IN ns] LIKE pattern
+		show = NewSqlShow("TABLE EXTENDED", pos)
+		show.Namespace = v.visitMultipartIdentifierInternal(c.GetNs())
+		show.Pattern = v.getStringLitText(c.GetPattern())
+	case *antlr.ShowTblPropertiesContext:
+		// SHOW TBLPROPERTIES table
+		show = NewSqlShow("TBLPROPERTIES", pos)
+		show.Object = v.visitMultipartIdentifierInternal(c.GetTable())
+	case *antlr.ShowColumnsContext:
+		// SHOW COLUMNS IN table [IN ns]
+		show = NewSqlShow("COLUMNS", pos)
+		show.Object = v.visitMultipartIdentifierInternal(c.GetTable())
+		show.Namespace = v.visitMultipartIdentifierInternal(c.GetNs())
+	case *antlr.ShowViewsContext:
+		// SHOW VIEWS [IN ns] [LIKE pattern]
+		show = NewSqlShow("VIEWS", pos)
+		show.Namespace = v.visitMultipartIdentifierInternal(c.MultipartIdentifier())
+		show.Pattern = v.getStringLitText(c.GetPattern())
+	case *antlr.ShowPartitionsContext:
+		// SHOW PARTITIONS table
+		show = NewSqlShow("PARTITIONS", pos)
+		show.Object = v.visitMultipartIdentifierInternal(c.MultipartIdentifier())
+	case *antlr.ShowFunctionsContext:
+		// SHOW [USER | SYSTEM | ALL] FUNCTIONS [IN ns] [LIKE pattern]
+		target := "FUNCTIONS"
+		if c.Identifier() != nil {
+			target = strings.ToUpper(c.Identifier().GetText()) + " " + target
+		}
+		show = NewSqlShow(target, pos)
+		show.Namespace = v.visitMultipartIdentifierInternal(c.GetNs())
+		if c.GetLegacy() != nil {
+			show.Pattern = c.GetLegacy().GetText()
+		} else {
+			show.Pattern = v.getStringLitText(c.GetPattern())
+		}
+	case *antlr.ShowCreateTableContext:
+		// SHOW CREATE TABLE table
+		show = NewSqlShow("CREATE TABLE", pos)
+		show.Object = v.visitMultipartIdentifierInternal(c.MultipartIdentifier())
+	case *antlr.ShowCurrentNamespaceContext:
+		// SHOW CURRENT NAMESPACE
+		show = NewSqlShow("CURRENT "+strings.ToUpper(c.Namespace().GetText()), pos)
+	case *antlr.ShowCatalogsContext:
+		// SHOW CATALOGS [LIKE pattern]
+		show = NewSqlShow("CATALOGS", pos)
+		show.Pattern = v.getStringLitText(c.GetPattern())
+	default:
+		return v.newError("不支持的 SHOW 语句", ctx)
+	}
+	
+	return show
+}
+
+// visitMultipartIdentifierInternal 将 a.b.c 形式的名字转换为 SqlIdentifier
+func (v *SqlNodeBuilderVisitor) visitMultipartIdentifierInternal(ctx antlr.IMultipartIdentifierContext) *SqlIdentifier {
+	if ctx == nil {
+		return nil
+	}
+	
+	parts := strings.Split(ctx.GetText(), ".")
+	return NewSqlIdentifier(parts, v.getPositionFromContext(ctx))
+}
+
+// getStringLitText 获取字符串字面量内容（去除引号）
+func (v *SqlNodeBuilderVisitor) getStringLitText(ctx antlr.IStringLitContext) string {
+	if ctx == nil {
+		return ""
+	}
+	
+	return unescapeStringLiteral(ctx.GetText())
+}
+
+// unescapeStringLiteral 去掉字符串字面量的引号并按 Spark 的规则处理转义
+// R'...' 形式的原始字符串不处理转义；\% 和 \_ 保留反斜杠，供 LIKE 使用
+func unescapeStringLiteral(text string) string {
+	if len(text) >= 3 && (text[0] == 'R' || text[0] == 'r') {
+		return text[2 : len(text)-1]
+	}
+	if len(text) < 2 {
+		return text
+	}
+	text = text[1 : len(text)-1]
+	if !strings.Contains(text, "\\") {
+		return text
+	}
+	
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\\' || i+1 >= len(text) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch next := text[i]; next {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'Z':
+			sb.WriteByte(0x1A)
+		case '%', '_':
+			sb.WriteByte('\\')
+			sb.WriteByte(next)
+		case 'u':
+			// \uXXXX
+			if i+4 < len(text) {
+				if code, err := strconv.ParseUint(text[i+1:i+5], 16, 32); err == nil {
+					sb.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteByte(next)
+		default:
+			// \ddd 八进制
+			if i+2 < len(text) && isOctalDigit(next) && isOctalDigit(text[i+1]) && isOctalDigit(text[i+2]) {
+				code, _ := strconv.ParseUint(text[i:i+3], 8, 32)
+				sb.WriteRune(rune(code))
+				i += 2
+				continue
+			}
+			if next == '0' {
+				sb.WriteByte(0)
+				continue
+			}
+			// \' \" \\ 及其他字符去掉反斜杠
+			sb.WriteByte(next)
+		}
+	}
+	return sb.String()
+}
+
+func isOctalDigit(c byte) bool {
+	return '0' <= c && c <= '7'
+}
+
 // VisitQuery 访问查询
-func (v *SqlNodeBuilderVisitor) VisitQuery(ctx antlr.IQueryContext) interface{} {
+func (v *SqlNodeBuilderVisitor) VisitQuery(ctx antlr.IQueryContext) (result interface{}) {
 	if ctx == nil {
 		return nil
 	}
+	v.enterNesting(ctx)
+	defer v.leaveNesting(ctx, &result)
 	
 	queryCtx, ok := ctx.(*antlr.QueryContext)
 	if !ok || queryCtx == nil {
@@ -91,12 +400,78 @@ func (v *SqlNodeBuilderVisitor) VisitQuery(ctx antlr.IQueryContext) interface{}
 		return nil
 	}
 	
+	// TODO: 处理 CTE
+	if ctes := queryCtx.Ctes(); ctes != nil {
+		v.newError("不支持的 CTE", ctes)
+	}
+	
 	// 检查是否为 QueryTermDefault
-	if termDefaultCtx, ok := queryTermCtx.(*antlr.QueryTermDefaultContext); ok {
-		return v.VisitQueryTermDefault(termDefaultCtx)
+	termDefaultCtx, ok := queryTermCtx.(*antlr.QueryTermDefaultContext)
+	if !ok {
+		return v.newError("不支持的查询项类型", queryCtx)
+	}
+	result = v.VisitQueryTermDefault(termDefaultCtx)
+	
+	if organization, ok := queryCtx.QueryOrganization().(*antlr.QueryOrganizationContext); ok && organization != nil && organization.GetChildCount() > 0 {
+		if sqlSelect, ok := result.(*SqlSelect); ok {
+			v.visitQueryOrganizationInternal(organization, sqlSelect)
+		} else {
+			v.newError("不支持的查询组织子句", organization)
+		}
+	}
+	return result
+}
+
+// visitQueryOrganizationInternal 处理 ORDER BY / LIMIT / OFFSET
+// CLUSTER BY、DISTRIBUTE BY、SORT BY 和 WINDOW 暂不支持
+func (v *SqlNodeBuilderVisitor) visitQueryOrganizationInternal(ctx *antlr.QueryOrganizationContext, sqlSelect *SqlSelect) {
+	if len(ctx.GetClusterBy()) > 0 || len(ctx.GetDistributeBy()) > 0 || len(ctx.GetSort()) > 0 || ctx.WindowClause() != nil {
+		v.newError("不支持的查询组织子句", ctx)
+		return
+	}
+	
+	for _, itemCtx := range ctx.GetOrder() {
+		if sortItem, ok := itemCtx.(*antlr.SortItemContext); ok {
+			if item := v.visitSortItemInternal(sortItem); item != nil {
+				sqlSelect.OrderBy = append(sqlSelect.OrderBy, item)
+			}
+		}
+	}
+	
+	// LIMIT ALL 不限制行数
+	if limit, ok := ctx.GetLimit().(*antlr.ExpressionContext); ok && limit != nil {
+		sqlSelect.Fetch, _ = v.VisitExpression(limit).(SqlNode)
+	}
+	if offset, ok := ctx.GetOffset().(*antlr.ExpressionContext); ok && offset != nil {
+		sqlSelect.Offset, _ = v.VisitExpression(offset).(SqlNode)
+	}
+}
+
+// visitSortItemInternal 处理排序项
+// sortItem: expression ordering=(ASC | DESC)? (NULLS nullOrder=(LAST | FIRST))?
+// DESC 和 NULLS FIRST / NULLS LAST 表示为包裹表达式的后缀操作符，类似 Calcite
+func (v *SqlNodeBuilderVisitor) visitSortItemInternal(ctx *antlr.SortItemContext) SqlNode {
+	expr, ok := ctx.Expression().(*antlr.ExpressionContext)
+	if !ok || expr == nil {
+		return nil
+	}
+	item, ok := v.VisitExpression(expr).(SqlNode)
+	if !ok {
+		return nil
 	}
 	
-	return v.newError("不支持的查询项类型", queryCtx)
+	pos := v.getPositionFromContext(ctx)
+	if ctx.DESC() != nil {
+		item = NewSqlCall(NewSqlOperator("DESC", SqlKindDescending, SyntaxPostfix), []SqlNode{item}, pos)
+	}
+	if ctx.GetNullOrder() != nil {
+		op := NewSqlOperator("NULLS LAST", SqlKindNullsLast, SyntaxPostfix)
+		if ctx.FIRST() != nil {
+			op = NewSqlOperator("NULLS FIRST", SqlKindNullsFirst, SyntaxPostfix)
+		}
+		item = NewSqlCall(op, []SqlNode{item}, pos)
+	}
+	return item
 }
 
 // VisitQueryTermDefault 访问查询项默认
@@ -142,58 +517,56 @@ func (v *SqlNodeBuilderVisitor) VisitRegularQuerySpecification(ctx *antlr.Regula
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	sqlSelect := NewSqlSelect(pos)
 	
 	// 重置当前标识符
 	v.currentIdentifiers = []string{}
 	
+	// TODO: 处理 LATERAL VIEW 和 WINDOW 子句
+	for _, lateralView := range ctx.AllLateralView() {
+		v.newError("不支持的 LATERAL VIEW", lateralView)
+	}
+	if window := ctx.WindowClause(); window != nil {
+		v.newError("不支持的 WINDOW 子句", window)
+	}
+	
 	// 1. 处理 FROM 子句
+	// 多个 relation 以 COMMA 连接保留原样，隐式 JOIN 改写见 RewriteImplicitJoins
 	var fromNode SqlNode
-	var fromList []SqlNode
 	if fromClauseIface := ctx.FromClause(); fromClauseIface != nil {
 		if fromClause, ok := fromClauseIface.(*antlr.FromClauseContext); ok {
-			fromResult := v.VisitFromClause(fromClause)
-			if fromResult != nil {
-				if list, ok := fromResult.([]SqlNode); ok {
-					fromList = list
-				} else if node, ok := fromResult.(SqlNode); ok {
-					fromList = []SqlNode{node}
-				}
-			}
-			
-			// 2. 处理 WHERE 子句（收集 join 和 filter 条件）
-			if whereClauseIface := ctx.WhereClause(); whereClauseIface != nil {
-				if whereClause, ok := whereClauseIface.(*antlr.WhereClauseContext); ok {
-					v.VisitWhereClause(whereClause)
-				}
-			}
-			
-			// 3. 构建 JOIN 树
-			if len(fromList) > 1 {
-				fromNode = v.dealJoinList2Node(fromList)
-			} else if len(fromList) == 1 {
-				fromNode = fromList[0]
+			if fromList, ok := v.VisitFromClause(fromClause).([]SqlNode); ok {
+				fromNode = v.buildCommaJoin(fromList)
 			}
-			
-			// 4. 处理 filter 条件
-			whereNode := v.dealFilterConditions()
-			sqlSelect.Where = whereNode
 		}
 	} else {
 		// 没有 FROM 子句，使用 DUAL 表
 		fromNode = NewSqlIdentifier([]string{"DUAL"}, pos)
 	}
 	
+	// 2. 处理 WHERE 子句，保留原始的布尔结构
+	if whereClauseIface := ctx.WhereClause(); whereClauseIface != nil {
+		if whereClause, ok := whereClauseIface.(*antlr.WhereClauseContext); ok {
+			whereNode, _ := v.VisitWhereClause(whereClause).(SqlNode)
+			if errNode := v.errorNodeFor(whereClause); errNode != nil {
+				// WHERE 子句有语法错误时，整个条件标记为错误区域
+				whereNode = errNode
+			}
+			sqlSelect.Where = whereNode
+		}
+	}
+	
 	sqlSelect.From = fromNode
 	
-	// 5. 处理 SELECT 列表和 HINTS
+	// 3. 处理 SELECT 列表和 HINTS
 	if selectClauseIface := ctx.SelectClause(); selectClauseIface != nil {
 		if selectClause, ok := selectClauseIface.(*antlr.SelectClauseContext); ok {
 			selectResult := v.VisitSelectClause(selectClause)
 			if selectResult != nil {
 				if result, ok := selectResult.(*SelectClauseResult); ok {
 					sqlSelect.Hints = result.Hints
+					sqlSelect.KeywordList = result.KeywordList
 					sqlSelect.SelectList = result.SelectList
 				} else if selectList, ok := selectResult.([]SqlNode); ok {
 					// 兼容旧的返回格式
@@ -203,7 +576,7 @@ func (v *SqlNodeBuilderVisitor) VisitRegularQuerySpecification(ctx *antlr.Regula
 		}
 	}
 	
-	// 6. 处理 GROUP BY 子句
+	// 4. 处理 GROUP BY 子句
 	if aggClauseIface := ctx.AggregationClause(); aggClauseIface != nil {
 		if aggClause, ok := aggClauseIface.(*antlr.AggregationClauseContext); ok {
 			aggResult := v.VisitAggregationClause(aggClause)
@@ -215,14 +588,15 @@ func (v *SqlNodeBuilderVisitor) VisitRegularQuerySpecification(ctx *antlr.Regula
 		}
 	}
 	
-	// 7. 处理 HAVING 子句
+	// 5. 处理 HAVING 子句
 	if havingClauseIface := ctx.HavingClause(); havingClauseIface != nil {
 		if havingClause, ok := havingClauseIface.(*antlr.HavingClauseContext); ok {
-			havingResult := v.VisitHavingClause(havingClause)
-			if havingResult != nil {
-				if havingNode, ok := havingResult.(SqlNode); ok {
-					sqlSelect.Having = havingNode
-				}
+			havingNode, _ := v.VisitHavingClause(havingClause).(SqlNode)
+			if havingNode == nil {
+				havingNode = v.errorNodeFor(havingClause)
+			}
+			if havingNode != nil {
+				sqlSelect.Having = havingNode
 			}
 		}
 	}
@@ -236,8 +610,9 @@ func (v *SqlNodeBuilderVisitor) VisitRegularQuerySpecification(ctx *antlr.Regula
 
 // SelectClauseResult 保存 SELECT 子句的解析结果
 type SelectClauseResult struct {
-	Hints      []*SqlHint
-	SelectList []SqlNode
+	Hints       []*SqlHint
+	KeywordList []string // DISTINCT 或 ALL
+	SelectList  []SqlNode
 }
 
 // VisitSelectClause 访问 SELECT 子句
@@ -247,8 +622,9 @@ func (v *SqlNodeBuilderVisitor) VisitSelectClause(ctx *antlr.SelectClauseContext
 	}
 	
 	result := &SelectClauseResult{
-		Hints:      []*SqlHint{},
-		SelectList: []SqlNode{},
+		Hints:       []*SqlHint{},
+		KeywordList: []string{},
+		SelectList:  []SqlNode{},
 	}
 	
 	// 1. 解析 HINTS（如果有）
@@ -265,13 +641,18 @@ func (v *SqlNodeBuilderVisitor) VisitSelectClause(ctx *antlr.SelectClauseContext
 		}
 	}
 	
-	// 2. 获取 namedExpressionSeq
+	// 2. 解析 DISTINCT / ALL
+	if quantifier := ctx.SetQuantifier(); quantifier != nil {
+		result.KeywordList = append(result.KeywordList, strings.ToUpper(quantifier.GetText()))
+	}
+	
+	// 3. 获取 namedExpressionSeq
 	namedExprsCtx := ctx.NamedExpressionSeq()
 	if namedExprsCtx == nil {
 		return result
 	}
 	
-	// 3. 检查类型
+	// 4. 检查类型
 	if federatedCtx, ok := namedExprsCtx.(*antlr.FederatedQueryExpressionContext); ok {
 		selectList := v.VisitFederatedQueryExpression(federatedCtx)
 		if selectList != nil {
@@ -295,10 +676,12 @@ func (v *SqlNodeBuilderVisitor) VisitFederatedQueryExpression(ctx *antlr.Federat
 	allNamedExprs := ctx.AllNamedExpression()
 	for _, namedExprIface := range allNamedExprs {
 		if namedExpr, ok := namedExprIface.(*antlr.NamedExpressionContext); ok && namedExpr != nil {
-			if node := v.VisitNamedExpression(namedExpr); node != nil {
-				if sqlNode, ok := node.(SqlNode); ok {
-					result = append(result, sqlNode)
-				}
+			sqlNode, _ := v.VisitNamedExpression(namedExpr).(SqlNode)
+			if sqlNode == nil {
+				sqlNode = v.errorNodeFor(namedExpr)
+			}
+			if sqlNode != nil {
+				result = append(result, sqlNode)
 			}
 		}
 	}
@@ -338,9 +721,9 @@ func (v *SqlNodeBuilderVisitor) VisitNamedExpression(ctx *antlr.NamedExpressionC
 	if identCtx != nil && identCtx.Identifier() != nil {
 		alias := identCtx.Identifier().GetText()
 		if alias != "" {
-			pos := v.getPosition(ctx.GetStart())
+			pos := v.getPositionFromContext(ctx)
 			// 使用 AS 操作符构建别名节点
-			asOp := &SqlOperator{Name: "AS", Kind: SqlKindAs, Syntax: SyntaxSpecial}
+			asOp := NewSqlOperator("AS", SqlKindAs, SyntaxSpecial)
 			aliasNode := NewSqlIdentifier([]string{alias}, pos)
 			return NewSqlCall(asOp, []SqlNode{sqlNode, aliasNode}, pos)
 		}
@@ -373,14 +756,26 @@ func (v *SqlNodeBuilderVisitor) VisitFromClause(ctx *antlr.FromClauseContext) in
 	
 	result := []SqlNode{}
 	
+	// TODO: 处理 LATERAL VIEW、PIVOT 和 UNPIVOT
+	for _, lateralView := range ctx.AllLateralView() {
+		v.newError("不支持的 LATERAL VIEW", lateralView)
+	}
+	if pivot := ctx.PivotClause(); pivot != nil {
+		v.newError("不支持的 PIVOT 子句", pivot)
+	}
+	if unpivot := ctx.UnpivotClause(); unpivot != nil {
+		v.newError("不支持的 UNPIVOT 子句", unpivot)
+	}
+	
 	allRelations := ctx.AllRelation()
 	for _, relationIface := range allRelations {
 		if relation, ok := relationIface.(*antlr.RelationContext); ok {
-			nodeResult := v.VisitRelation(relation)
-			if nodeResult != nil {
-				if node, ok := nodeResult.(SqlNode); ok {
-					result = append(result, node)
-				}
+			node, _ := v.VisitRelation(relation).(SqlNode)
+			if node == nil {
+				node = v.errorNodeFor(relation)
+			}
+			if node != nil {
+				result = append(result, node)
 			}
 		}
 	}
@@ -401,19 +796,129 @@ func (v *SqlNodeBuilderVisitor) VisitRelation(ctx *antlr.RelationContext) interf
 		return nil
 	}
 	
+	if ctx.LATERAL() != nil {
+		return v.newError("不支持的 LATERAL 子查询", ctx)
+	}
+	
 	// 处理基础的 relation（表或子查询）
-	result := v.visitRelationPrimaryInternal(relPrimary)
+	result, _ := v.visitRelationPrimaryInternal(relPrimary).(SqlNode)
+	if result == nil {
+		return nil
+	}
 	
-	// 处理 JOIN 扩展
-	allExtensions := ctx.AllRelationExtension()
-	if len(allExtensions) > 0 {
-		// TODO: 处理显式 JOIN，这里需要根据实际的语法结构来实现
-		// 目前主要依赖 WHERE 子句中的隐式 JOIN
+	// 处理 JOIN 扩展，多个 JOIN 组成左深树
+	// TODO: 处理 PIVOT 和 UNPIVOT
+	for _, extensionIface := range ctx.AllRelationExtension() {
+		extension, ok := extensionIface.(*antlr.RelationExtensionContext)
+		if !ok {
+			continue
+		}
+		joinRel, ok := extension.JoinRelation().(*antlr.JoinRelationContext)
+		if !ok || joinRel == nil {
+			v.newError("不支持的 relation 扩展", extension)
+			continue
+		}
+		result = v.visitJoinRelationInternal(result, ctx, joinRel)
 	}
 	
 	return result
 }
 
+// visitJoinRelationInternal 处理显式 JOIN，left 为 JOIN 左侧已构建的关系
+// joinRelation: joinType JOIN LATERAL? right=relationPrimary joinCriteria? | NATURAL joinType JOIN LATERAL? right=relationPrimary
+// 位置从整个 relation 的起点到 JOIN 结束
+// 不支持的 JOIN 记录错误，并用覆盖左侧和该 JOIN 原文的 SqlErrorNode 代替，不会只剩左侧的表
+func (v *SqlNodeBuilderVisitor) visitJoinRelationInternal(left SqlNode, relation *antlr.RelationContext, ctx *antlr.JoinRelationContext) SqlNode {
+	// TODO: 处理 NATURAL JOIN 和 LATERAL 子查询
+	if ctx.NATURAL() != nil {
+		v.newError("不支持的 NATURAL JOIN", ctx)
+		return v.sourceErrorNode(relation.GetStart(), ctx.GetStop())
+	}
+	if ctx.LATERAL() != nil {
+		v.newError("不支持的 LATERAL 子查询", ctx)
+		return v.sourceErrorNode(relation.GetStart(), ctx.GetStop())
+	}
+	
+	joinType, ok := v.getJoinType(ctx.JoinType())
+	if !ok {
+		v.newError("不支持的 SEMI / ANTI JOIN", ctx.JoinType())
+		return v.sourceErrorNode(relation.GetStart(), ctx.GetStop())
+	}
+	
+	right, _ := v.visitRelationPrimaryInternal(ctx.GetRight()).(SqlNode)
+	if right == nil {
+		if rightCtx, ok := ctx.GetRight().(antlr4.ParserRuleContext); ok {
+			right = v.errorNodeFor(rightCtx)
+		}
+		if right == nil {
+			return v.sourceErrorNode(relation.GetStart(), ctx.GetStop())
+		}
+	}
+	
+	pos := v.tokenSpan(relation.GetStart(), ctx.GetStop())
+	join := NewSqlJoin(left, right, joinType, nil, pos)
+	
+	criteria, ok := ctx.JoinCriteria().(*antlr.JoinCriteriaContext)
+	if !ok || criteria == nil {
+		return join
+	}
+	if joinType == JoinCross {
+		v.newError("CROSS JOIN 不能带 ON / USING 条件", criteria)
+		return join
+	}
+	if criteria.ON() != nil {
+		join.Condition, _ = v.visitBooleanExpressionInternal(criteria.BooleanExpression()).(SqlNode)
+		if errNode := v.errorNodeFor(criteria); errNode != nil {
+			join.Condition = errNode
+		}
+		return join
+	}
+	if identList, ok := criteria.IdentifierList().(*antlr.IdentifierListContext); ok && identList != nil {
+		join.Using = v.visitUsingColumns(identList)
+	}
+	return join
+}
+
+// getJoinType 把 joinType 规则转换为 JoinType，SEMI / ANTI JOIN 返回 false
+// joinType: INNER? | CROSS | LEFT OUTER? | LEFT? SEMI | RIGHT OUTER? | FULL OUTER? | LEFT? ANTI
+func (v *SqlNodeBuilderVisitor) getJoinType(ctx antlr.IJoinTypeContext) (JoinType, bool) {
+	joinType, ok := ctx.(*antlr.JoinTypeContext)
+	if !ok || joinType == nil {
+		return JoinInner, true
+	}
+	switch {
+	case joinType.SEMI() != nil, joinType.ANTI() != nil:
+		return "", false
+	case joinType.CROSS() != nil:
+		return JoinCross, true
+	case joinType.LEFT() != nil:
+		return JoinLeft, true
+	case joinType.RIGHT() != nil:
+		return JoinRight, true
+	case joinType.FULL() != nil:
+		return JoinFull, true
+	}
+	return JoinInner, true
+}
+
+// visitUsingColumns 处理 USING 后的列名列表
+func (v *SqlNodeBuilderVisitor) visitUsingColumns(ctx *antlr.IdentifierListContext) []SqlNode {
+	columns := []SqlNode{}
+	seq, ok := ctx.IdentifierSeq().(*antlr.IdentifierSeqContext)
+	if !ok || seq == nil {
+		return columns
+	}
+	for _, identIface := range seq.AllErrorCapturingIdentifier() {
+		ident, ok := identIface.(*antlr.ErrorCapturingIdentifierContext)
+		if !ok || ident == nil || ident.Identifier() == nil {
+			continue
+		}
+		name := ident.Identifier().GetText()
+		columns = append(columns, NewSqlIdentifier([]string{name}, v.getPositionFromContext(ident)))
+	}
+	return columns
+}
+
 // visitRelationPrimaryInternal 内部辅助方法，处理多个子类型
 func (v *SqlNodeBuilderVisitor) visitRelationPrimaryInternal(ctx antlr.IRelationPrimaryContext) interface{} {
 	if ctx == nil {
@@ -435,7 +940,7 @@ func (v *SqlNodeBuilderVisitor) visitRelationPrimaryInternal(ctx antlr.IRelation
 		return v.VisitAliasedRelation(aliasedRelCtx)
 	}
 	
-	return nil
+	return v.newError("不支持的 relation 类型", ctx)
 }
 
 // VisitTableName 访问表名
@@ -444,7 +949,7 @@ func (v *SqlNodeBuilderVisitor) VisitTableName(ctx *antlr.TableNameContext) inte
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	// 获取表名
 	multipartId := ctx.MultipartIdentifier()
@@ -460,7 +965,14 @@ func (v *SqlNodeBuilderVisitor) VisitTableName(ctx *antlr.TableNameContext) inte
 		v.currentAssetKey = parts[len(parts)-1]
 	}
 	
-	tableNode := NewSqlIdentifier(parts, pos)
+	var tableNode SqlNode = NewSqlIdentifier(parts, pos)
+	
+	// 时间旅行和采样子句
+	temporal := v.visitTemporalClauseInternal(ctx.TemporalClause())
+	sample := v.visitSampleInternal(ctx.Sample())
+	if temporal != nil || sample != nil {
+		tableNode = NewSqlTableRef(tableNode.(*SqlIdentifier), temporal, sample, pos)
+	}
 	
 	// 检查是否有别名
 	tableAlias := ctx.TableAlias()
@@ -470,7 +982,7 @@ func (v *SqlNodeBuilderVisitor) VisitTableName(ctx *antlr.TableNameContext) inte
 			v.currentAssetKey = alias
 			v.assetMap[alias] = tableName
 			// 使用 AS 操作符构建别名
-			asOp := &SqlOperator{Name: "AS", Kind: SqlKindAs, Syntax: SyntaxSpecial}
+			asOp := NewSqlOperator("AS", SqlKindAs, SyntaxSpecial)
 			aliasNode := NewSqlIdentifier([]string{alias}, pos)
 			return NewSqlCall(asOp, []SqlNode{tableNode, aliasNode}, pos)
 		}
@@ -481,13 +993,107 @@ func (v *SqlNodeBuilderVisitor) VisitTableName(ctx *antlr.TableNameContext) inte
 	return tableNode
 }
 
+// visitTemporalClauseInternal 处理时间旅行子句 (VERSION AS OF / TIMESTAMP AS OF)
+func (v *SqlNodeBuilderVisitor) visitTemporalClauseInternal(ctx antlr.ITemporalClauseContext) *SqlTemporalSpec {
+	temporalCtx, ok := ctx.(*antlr.TemporalClauseContext)
+	if !ok || temporalCtx == nil {
+		return nil
+	}
+	
+	// VERSION AS OF 版本号
+	if versionCtx, ok := temporalCtx.Version().(*antlr.VersionContext); ok && versionCtx != nil {
+		pos := v.getPositionFromContext(versionCtx)
+		var value SqlNode
+		if versionCtx.INTEGER_VALUE() != nil {
+			if number, err := strconv.ParseInt(versionCtx.INTEGER_VALUE().GetText(), 10, 64); err == nil {
+				value = NewSqlLiteral(number, LiteralInteger, pos)
+			}
+		} else if versionCtx.StringLit() != nil {
+			value = NewSqlLiteral(v.getStringLitText(versionCtx.StringLit()), LiteralString, pos)
+		}
+		return &SqlTemporalSpec{Type: TemporalVersion, Value: value}
+	}
+	
+	// TIMESTAMP AS OF 表达式
+	if temporalCtx.GetTimestamp() != nil {
+		value, _ := v.visitValueExpressionInternal(temporalCtx.GetTimestamp()).(SqlNode)
+		return &SqlTemporalSpec{Type: TemporalTimestamp, Value: value}
+	}
+	
+	return nil
+}
+
+// visitSampleInternal 处理 TABLESAMPLE 子句
+func (v *SqlNodeBuilderVisitor) visitSampleInternal(ctx antlr.ISampleContext) *SqlSampleSpec {
+	sampleCtx, ok := ctx.(*antlr.SampleContext)
+	if !ok || sampleCtx == nil {
+		return nil
+	}
+	
+	spec := &SqlSampleSpec{}
+	
+	switch methodCtx := sampleCtx.SampleMethod().(type) {
+	case *antlr.SampleByPercentileContext:
+		// TABLESAMPLE ([-]10 PERCENT)
+		spec.Method = SamplePercent
+		pos := v.getPositionFromContext(methodCtx)
+		text := methodCtx.GetPercentage().GetText()
+		if methodCtx.GetNegativeSign() != nil {
+			text = "-" + text
+		}
+		if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+			spec.Value = NewSqlLiteral(number, LiteralInteger, pos)
+		} else if number, err := strconv.ParseFloat(text, 64); err == nil {
+			spec.Value = NewSqlLiteral(number, LiteralDecimal, pos)
+		}
+	case *antlr.SampleByRowsContext:
+		// TABLESAMPLE (100 ROWS)
+		spec.Method = SampleRows
+		if expr, ok := methodCtx.Expression().(*antlr.ExpressionContext); ok {
+			spec.Value, _ = v.VisitExpression(expr).(SqlNode)
+		}
+	case *antlr.SampleByBucketContext:
+		// TABLESAMPLE (BUCKET 1 OUT OF 4 [ON col | ON func()])
+		spec.Method = SampleBucket
+		spec.Numerator, _ = strconv.ParseInt(methodCtx.GetNumerator().GetText(), 10, 64)
+		spec.Denominator, _ = strconv.ParseInt(methodCtx.GetDenominator().GetText(), 10, 64)
+		if methodCtx.Identifier() != nil {
+			spec.BucketOn = NewSqlIdentifier([]string{methodCtx.Identifier().GetText()}, v.getPositionFromContext(methodCtx.Identifier()))
+		} else if methodCtx.QualifiedName() != nil {
+			pos := v.getPositionFromContext(methodCtx.QualifiedName())
+			op := NewSqlOperator(strings.ToUpper(methodCtx.QualifiedName().GetText()), SqlKindOther, SyntaxFunction)
+			spec.BucketOn = NewSqlCall(op, []SqlNode{}, pos)
+		}
+	case *antlr.SampleByBytesContext:
+		// TABLESAMPLE (100M)
+		spec.Method = SampleBytes
+		if expr, ok := methodCtx.GetBytes().(*antlr.ExpressionContext); ok {
+			spec.Value, _ = v.VisitExpression(expr).(SqlNode)
+			if spec.Value == nil {
+				spec.Value = NewSqlIdentifier([]string{expr.GetText()}, v.getPositionFromContext(expr))
+			}
+		}
+	default:
+		return nil
+	}
+	
+	// REPEATABLE (seed)
+	if sampleCtx.GetSeed() != nil {
+		if seed, err := strconv.ParseInt(sampleCtx.GetSeed().GetText(), 10, 64); err == nil {
+			spec.Seed = &seed
+		}
+	}
+	
+	return spec
+}
+
 // VisitAliasedQuery 访问带别名的子查询
 func (v *SqlNodeBuilderVisitor) VisitAliasedQuery(ctx *antlr.AliasedQueryContext) interface{} {
 	if ctx == nil {
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	// 访问子查询
 	subqueryIface := ctx.Query()
@@ -507,12 +1113,13 @@ func (v *SqlNodeBuilderVisitor) VisitAliasedQuery(ctx *antlr.AliasedQueryContext
 	
 	// 获取别名（子查询必须有别名）
 	tableAlias := ctx.TableAlias()
-	if tableAlias != nil {
-		aliasText := tableAlias.GetText()
+	if tableAlias != nil && tableAlias.StrictIdentifier() != nil {
+		// 只取别名本身，不含 AS 关键字和列别名
+		aliasText := tableAlias.StrictIdentifier().GetText()
 		if aliasText != "" {
 			v.currentAssetKey = aliasText
 			// 使用 AS 操作符构建子查询别名
-			asOp := &SqlOperator{Name: "AS", Kind: SqlKindAs, Syntax: SyntaxSpecial}
+			asOp := NewSqlOperator("AS", SqlKindAs, SyntaxSpecial)
 			aliasNode := NewSqlIdentifier([]string{aliasText}, pos)
 			subQueryCall := NewSqlCall(asOp, []SqlNode{sqlNode, aliasNode}, pos)
 			v.subQueryTables[aliasText] = subQueryCall
@@ -547,7 +1154,6 @@ func (v *SqlNodeBuilderVisitor) VisitAliasedRelation(ctx *antlr.AliasedRelationC
 // =============================================================================
 
 // VisitWhereClause 访问 WHERE 子句
-// 这里会收集 join 条件和 filter 条件
 func (v *SqlNodeBuilderVisitor) VisitWhereClause(ctx *antlr.WhereClauseContext) interface{} {
 	if ctx == nil || ctx.BooleanExpression() == nil {
 		return nil
@@ -563,10 +1169,12 @@ func (v *SqlNodeBuilderVisitor) VisitWhereClause(ctx *antlr.WhereClauseContext)
 // =============================================================================
 
 // visitBooleanExpressionInternal 内部辅助方法，访问布尔表达式
-func (v *SqlNodeBuilderVisitor) visitBooleanExpressionInternal(ctx antlr.IBooleanExpressionContext) interface{} {
+func (v *SqlNodeBuilderVisitor) visitBooleanExpressionInternal(ctx antlr.IBooleanExpressionContext) (result interface{}) {
 	if ctx == nil {
 		return nil
 	}
+	v.enterNesting(ctx)
+	defer v.leaveNesting(ctx, &result)
 	
 	// LogicalBinary (AND, OR)
 	if logicalBinaryCtx, ok := ctx.(*antlr.LogicalBinaryContext); ok {
@@ -583,7 +1191,7 @@ func (v *SqlNodeBuilderVisitor) visitBooleanExpressionInternal(ctx antlr.IBoolea
 		return v.VisitPredicated(predicatedCtx)
 	}
 	
-	return nil
+	return v.newError("不支持的布尔表达式类型", ctx)
 }
 
 // VisitLogicalBinary 访问逻辑二元操作 (AND, OR)
@@ -592,7 +1200,7 @@ func (v *SqlNodeBuilderVisitor) VisitLogicalBinary(ctx *antlr.LogicalBinaryConte
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	left := v.visitBooleanExpressionInternal(ctx.GetLeft())
 	right := v.visitBooleanExpressionInternal(ctx.GetRight())
@@ -616,7 +1224,7 @@ func (v *SqlNodeBuilderVisitor) VisitLogicalBinary(ctx *antlr.LogicalBinaryConte
 		kind = SqlKindOr
 	}
 	
-	op := &SqlOperator{Name: opName, Kind: kind, Syntax: SyntaxBinary}
+	op := NewSqlOperator(opName, kind, SyntaxBinary)
 	return NewSqlCall(op, []SqlNode{leftNode, rightNode}, pos)
 }
 
@@ -626,7 +1234,7 @@ func (v *SqlNodeBuilderVisitor) VisitLogicalNot(ctx *antlr.LogicalNotContext) in
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	operand := v.visitBooleanExpressionInternal(ctx.BooleanExpression())
 	if operand == nil {
@@ -638,7 +1246,7 @@ func (v *SqlNodeBuilderVisitor) VisitLogicalNot(ctx *antlr.LogicalNotContext) in
 		return nil
 	}
 	
-	op := &SqlOperator{Name: "NOT", Kind: SqlKindNot, Syntax: SyntaxPrefix}
+	op := NewSqlOperator("NOT", SqlKindNot, SyntaxPrefix)
 	return NewSqlCall(op, []SqlNode{operandNode}, pos)
 }
 
@@ -666,29 +1274,45 @@ func (v *SqlNodeBuilderVisitor) VisitPredicated(ctx *antlr.PredicatedContext) in
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
-	// 处理 IS NULL / IS NOT NULL
-	kindToken := predicate.GetStart().GetText()
-	kindTokenUpper := strings.ToUpper(kindToken)
+	if predicate.GetKind() == nil {
+		return valueNode
+	}
+	kindTokenUpper := strings.ToUpper(predicate.GetKind().GetText())
+	negated := predicate.NOT() != nil
 	
+	// 处理 IS NULL / IS NOT NULL
 	if kindTokenUpper == "NULL" {
-		// IS NULL
-		op := &SqlOperator{Name: "IS NULL", Kind: SqlKindOther, Syntax: SyntaxPostfix}
-		callNode := NewSqlCall(op, []SqlNode{valueNode}, pos)
-		v.filterConditions = append(v.filterConditions, callNode)
-		return callNode
-	} else if kindTokenUpper == "NOT" {
-		// IS NOT NULL
-		op := &SqlOperator{Name: "IS NOT NULL", Kind: SqlKindOther, Syntax: SyntaxPostfix}
-		callNode := NewSqlCall(op, []SqlNode{valueNode}, pos)
-		v.filterConditions = append(v.filterConditions, callNode)
-		return callNode
+		name := "IS NULL"
+		if negated {
+			name = "IS NOT NULL"
+		}
+		op := NewSqlOperator(name, SqlKindOther, SyntaxPostfix)
+		return NewSqlCall(op, []SqlNode{valueNode}, pos)
 	}
 	
-	// TODO: 处理其他谓词（BETWEEN, IN, LIKE 等）
+	// 处理 IN (expr, ...) / NOT IN (expr, ...)
+	if kindTokenUpper == "IN" && predicate.Query() == nil {
+		items := []SqlNode{}
+		for _, exprIface := range predicate.AllExpression() {
+			if expr, ok := exprIface.(*antlr.ExpressionContext); ok {
+				if item, ok := v.VisitExpression(expr).(SqlNode); ok {
+					items = append(items, item)
+				}
+			}
+		}
+		op := NewSqlOperator("IN", SqlKindIn, SyntaxSpecial)
+		if negated {
+			op = NewSqlOperator("NOT IN", SqlKindNotIn, SyntaxSpecial)
+		}
+		return NewSqlCall(op, []SqlNode{valueNode, NewSqlNodeList(items, pos)}, pos)
+	}
 	
-	return valueNode
+	// TODO: 处理其他谓词（BETWEEN, LIKE 等）
+	// 只保留左侧的值会改变条件的含义，整个谓词用原文代替
+	v.newError("不支持的谓词", predicate)
+	return v.sourceErrorNode(ctx.GetStart(), ctx.GetStop())
 }
 
 // =============================================================================
@@ -696,10 +1320,12 @@ func (v *SqlNodeBuilderVisitor) VisitPredicated(ctx *antlr.PredicatedContext) in
 // =============================================================================
 
 // visitValueExpressionInternal 内部辅助方法，访问值表达式
-func (v *SqlNodeBuilderVisitor) visitValueExpressionInternal(ctx antlr.IValueExpressionContext) interface{} {
+func (v *SqlNodeBuilderVisitor) visitValueExpressionInternal(ctx antlr.IValueExpressionContext) (result interface{}) {
 	if ctx == nil {
 		return nil
 	}
+	v.enterNesting(ctx)
+	defer v.leaveNesting(ctx, &result)
 	
 	// ValueExpressionDefault (主表达式)
 	if defaultCtx, ok := ctx.(*antlr.ValueExpressionDefaultContext); ok {
@@ -721,7 +1347,7 @@ func (v *SqlNodeBuilderVisitor) visitValueExpressionInternal(ctx antlr.IValueExp
 		return v.VisitComparison(compCtx)
 	}
 	
-	return nil
+	return v.newError("不支持的值表达式类型", ctx)
 }
 
 // VisitValueExpressionDefault 访问值表达式默认
@@ -738,7 +1364,7 @@ func (v *SqlNodeBuilderVisitor) VisitArithmeticBinary(ctx *antlr.ArithmeticBinar
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	left := v.visitValueExpressionInternal(ctx.GetLeft())
 	right := v.visitValueExpressionInternal(ctx.GetRight())
@@ -755,7 +1381,7 @@ func (v *SqlNodeBuilderVisitor) VisitArithmeticBinary(ctx *antlr.ArithmeticBinar
 	
 	opText := ctx.GetOperator().GetText()
 	kind := v.getOperatorKind(opText)
-	op := &SqlOperator{Name: opText, Kind: kind, Syntax: SyntaxBinary}
+	op := NewSqlOperator(opText, kind, SyntaxBinary)
 	
 	return NewSqlCall(op, []SqlNode{leftNode, rightNode}, pos)
 }
@@ -766,7 +1392,7 @@ func (v *SqlNodeBuilderVisitor) VisitArithmeticUnary(ctx *antlr.ArithmeticUnaryC
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	operand := v.visitValueExpressionInternal(ctx.ValueExpression())
 	if operand == nil {
@@ -780,7 +1406,7 @@ func (v *SqlNodeBuilderVisitor) VisitArithmeticUnary(ctx *antlr.ArithmeticUnaryC
 	
 	opText := ctx.GetOperator().GetText()
 	kind := v.getOperatorKind(opText)
-	op := &SqlOperator{Name: opText, Kind: kind, Syntax: SyntaxPrefix}
+	op := NewSqlOperator(opText, kind, SyntaxPrefix)
 	
 	return NewSqlCall(op, []SqlNode{operandNode}, pos)
 }
@@ -792,7 +1418,7 @@ func (v *SqlNodeBuilderVisitor) VisitComparison(ctx *antlr.ComparisonContext) in
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	left := v.visitValueExpressionInternal(ctx.GetLeft())
 	right := v.visitValueExpressionInternal(ctx.GetRight())
@@ -815,39 +1441,10 @@ func (v *SqlNodeBuilderVisitor) VisitComparison(ctx *antlr.ComparisonContext) in
 	
 	opText := compOp.GetText()
 	kind := v.getOperatorKind(opText)
-	op := &SqlOperator{Name: opText, Kind: kind, Syntax: SyntaxBinary}
+	op := NewSqlOperator(opText, kind, SyntaxBinary)
 	
 	basicCall := NewSqlCall(op, []SqlNode{leftNode, rightNode}, pos)
 	
-	// 判断是 join 条件还是 filter 条件
-	// 如果左右两边都是标识符，可能是 join 条件
-	leftIsIdentifier := false
-	rightIsIdentifier := false
-	rightIsLiteral := false
-	
-	if _, ok := leftNode.(*SqlIdentifier); ok {
-		leftIsIdentifier = true
-	}
-	if _, ok := rightNode.(*SqlIdentifier); ok {
-		rightIsIdentifier = true
-	}
-	if _, ok := rightNode.(*SqlLiteral); ok {
-		rightIsLiteral = true
-	}
-	
-	if leftIsIdentifier && rightIsIdentifier {
-		// 两边都是标识符，可能是 join 条件
-		// 例如: a.id = b.id
-		v.joinConditions = append(v.joinConditions, basicCall)
-	} else if leftIsIdentifier && rightIsLiteral {
-		// 左边是标识符，右边是字面量，是 filter 条件
-		// 例如: a.status = 'active'
-		v.filterConditions = append(v.filterConditions, basicCall)
-	} else {
-		// 其他情况，默认作为 filter 条件
-		v.filterConditions = append(v.filterConditions, basicCall)
-	}
-	
 	return basicCall
 }
 
@@ -891,17 +1488,45 @@ func (v *SqlNodeBuilderVisitor) visitPrimaryExpressionInternal(ctx antlr.IPrimar
 		return v.VisitParenthesizedExpression(parenCtx)
 	}
 	
-	return nil
+	// Lambda (x -> x + 1)
+	if lambdaCtx, ok := ctx.(*antlr.LambdaContext); ok {
+		return v.VisitLambda(lambdaCtx)
+	}
+	
+	// Subscript (arr[0], m['key'])
+	if subscriptCtx, ok := ctx.(*antlr.SubscriptContext); ok {
+		return v.VisitSubscript(subscriptCtx)
+	}
+	
+	// Struct (STRUCT(a, b AS c))
+	if structCtx, ok := ctx.(*antlr.StructContext); ok {
+		return v.VisitStruct(structCtx)
+	}
+	
+	// RowConstructor ((a, b))
+	if rowCtx, ok := ctx.(*antlr.RowConstructorContext); ok {
+		return v.VisitRowConstructor(rowCtx)
+	}
+	
+	// Cast (CAST(x AS INT))
+	if castCtx, ok := ctx.(*antlr.CastContext); ok {
+		return v.VisitCast(castCtx)
+	}
+	
+	return v.newError("不支持的表达式类型", ctx)
 }
 
 // VisitStar 访问星号 (*)
 func (v *SqlNodeBuilderVisitor) VisitStar(ctx *antlr.StarContext) interface{} {
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	// 检查是否是 table.*
-	if ctx.QualifiedName() != nil {
-		tableName := ctx.QualifiedName().GetText()
-		return NewSqlIdentifier([]string{tableName, "*"}, pos)
+	if qualifiedName, ok := ctx.QualifiedName().(*antlr.QualifiedNameContext); ok && qualifiedName != nil {
+		names := []string{}
+		for _, identifier := range qualifiedName.AllIdentifier() {
+			names = append(names, identifier.GetText())
+		}
+		return NewSqlIdentifier(append(names, "*"), pos)
 	}
 	
 	return NewSqlIdentifier([]string{"*"}, pos)
@@ -913,9 +1538,14 @@ func (v *SqlNodeBuilderVisitor) VisitColumnReference(ctx *antlr.ColumnReferenceC
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	identifier := ctx.Identifier().GetText()
 	
+	// lambda 参数不是表中的列，不做记录
+	if v.isLambdaParameter(identifier) {
+		return NewSqlIdentifier([]string{identifier}, pos)
+	}
+	
 	// 记录字段
 	fullName := v.currentAssetKey + "." + identifier
 	v.allDerefFields[fullName] = true
@@ -926,19 +1556,31 @@ func (v *SqlNodeBuilderVisitor) VisitColumnReference(ctx *antlr.ColumnReferenceC
 }
 
 // VisitDereference 访问字段引用 (table.column 或 schema.table.column)
+// 如果基础部分不是简单的名字链（如 m['key'].field），则构建 DOT 字段访问
 func (v *SqlNodeBuilderVisitor) VisitDereference(ctx *antlr.DereferenceContext) interface{} {
 	if ctx == nil {
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
+	
+	if !v.isNameChain(ctx.GetBase()) {
+		base, ok := v.visitPrimaryExpressionInternal(ctx.GetBase()).(SqlNode)
+		if !ok || ctx.GetFieldName() == nil {
+			return nil
+		}
+		field := NewSqlIdentifier([]string{ctx.GetFieldName().GetText()}, v.getPositionFromContext(ctx.GetFieldName()))
+		op := NewSqlOperator(".", SqlKindDot, SyntaxSpecial)
+		return NewSqlCall(op, []SqlNode{base, field}, pos)
+	}
+	
 	text := ctx.GetText()
 	
 	// 分割为多个部分
 	parts := strings.Split(text, ".")
 	
-	// 记录字段
-	if len(parts) >= 2 {
+	// 记录字段（lambda 参数的字段访问除外）
+	if len(parts) >= 2 && !v.isLambdaParameter(parts[0]) {
 		v.allDerefFields[text] = true
 		v.columnNameSet[text] = true
 	}
@@ -946,6 +1588,17 @@ func (v *SqlNodeBuilderVisitor) VisitDereference(ctx *antlr.DereferenceContext)
 	return NewSqlIdentifier(parts, pos)
 }
 
+// isNameChain 判断表达式是否为 a.b.c 形式的名字链
+func (v *SqlNodeBuilderVisitor) isNameChain(ctx antlr.IPrimaryExpressionContext) bool {
+	switch c := ctx.(type) {
+	case *antlr.ColumnReferenceContext:
+		return true
+	case *antlr.DereferenceContext:
+		return v.isNameChain(c.GetBase())
+	}
+	return false
+}
+
 // VisitConstantDefault 访问常量默认
 func (v *SqlNodeBuilderVisitor) VisitConstantDefault(ctx *antlr.ConstantDefaultContext) interface{} {
 	if ctx == nil || ctx.Constant() == nil {
@@ -974,7 +1627,84 @@ func (v *SqlNodeBuilderVisitor) VisitConstantDefault(ctx *antlr.ConstantDefaultC
 		return v.VisitNullLiteral(nullCtx)
 	}
 	
-	return nil
+	// TypeConstructor (DATE '2024-01-01')
+	if typeCtx, ok := constantCtx.(*antlr.TypeConstructorContext); ok {
+		return v.VisitTypeConstructor(typeCtx)
+	}
+	
+	// IntervalLiteral (INTERVAL '1' DAY)
+	if intervalCtx, ok := constantCtx.(*antlr.IntervalLiteralContext); ok {
+		return v.VisitIntervalLiteral(intervalCtx)
+	}
+	
+	return v.newError("不支持的常量类型", constantCtx)
+}
+
+// VisitTypeConstructor 访问带类型的字面量，只支持 DATE 和 TIMESTAMP
+func (v *SqlNodeBuilderVisitor) VisitTypeConstructor(ctx *antlr.TypeConstructorContext) interface{} {
+	if ctx.Identifier() == nil || ctx.StringLit() == nil {
+		return nil
+	}
+	pos := v.getPositionFromContext(ctx)
+	value := v.getStringLitText(ctx.StringLit())
+	
+	switch strings.ToUpper(ctx.Identifier().GetText()) {
+	case "DATE":
+		return NewSqlLiteral(value, LiteralDate, pos)
+	case "TIMESTAMP":
+		return NewSqlLiteral(value, LiteralTimestamp, pos)
+	}
+	return v.newError("不支持的类型字面量", ctx)
+}
+
+// VisitIntervalLiteral 访问时间间隔字面量，只支持单个单位，如 INTERVAL '1' DAY、INTERVAL 3 HOURS
+func (v *SqlNodeBuilderVisitor) VisitIntervalLiteral(ctx *antlr.IntervalLiteralContext) interface{} {
+	intervalCtx, ok := ctx.Interval().(*antlr.IntervalContext)
+	if !ok || intervalCtx == nil {
+		return nil
+	}
+	multiUnits, ok := intervalCtx.ErrorCapturingMultiUnitsInterval().(*antlr.ErrorCapturingMultiUnitsIntervalContext)
+	if !ok || multiUnits == nil || multiUnits.UnitToUnitInterval() != nil {
+		return v.newError("不支持的 INTERVAL 形式", ctx)
+	}
+	body, ok := multiUnits.MultiUnitsInterval().(*antlr.MultiUnitsIntervalContext)
+	if !ok || body == nil || len(body.AllIntervalValue()) != 1 || len(body.AllUnitInMultiUnits()) != 1 {
+		return v.newError("不支持的 INTERVAL 形式", ctx)
+	}
+	
+	var value string
+	if valueCtx, ok := body.IntervalValue(0).(*antlr.IntervalValueContext); ok && valueCtx.StringLit() != nil {
+		value = v.getStringLitText(valueCtx.StringLit())
+	} else {
+		value = body.IntervalValue(0).GetText()
+	}
+	// 单位统一为单数形式，如 DAYS -> DAY
+	unit := strings.TrimSuffix(strings.ToUpper(body.UnitInMultiUnits(0).GetText()), "S")
+	
+	literal := NewSqlLiteral(value, LiteralInterval, v.getPositionFromContext(ctx))
+	literal.TypeName = unit
+	return literal
+}
+
+// VisitCast 访问类型转换 CAST(x AS type) / TRY_CAST(x AS type)
+// 目标类型保存为符号字面量，保留原始写法，如 DECIMAL(10,2)
+func (v *SqlNodeBuilderVisitor) VisitCast(ctx *antlr.CastContext) interface{} {
+	expr, ok := ctx.Expression().(*antlr.ExpressionContext)
+	if !ok || expr == nil || ctx.DataType() == nil {
+		return nil
+	}
+	operand, ok := v.VisitExpression(expr).(SqlNode)
+	if !ok {
+		return nil
+	}
+	
+	name := "CAST"
+	if ctx.TRY_CAST() != nil {
+		name = "TRY_CAST"
+	}
+	dataType := NewSqlLiteral(getTokenText(ctx.DataType()), LiteralSymbol, v.getPositionFromContext(ctx.DataType()))
+	op := NewSqlOperator(name, SqlKindCast, SyntaxSpecial)
+	return NewSqlCall(op, []SqlNode{operand, dataType}, v.getPositionFromContext(ctx))
 }
 
 // VisitParenthesizedExpression 访问括号表达式
@@ -994,9 +1724,27 @@ func (v *SqlNodeBuilderVisitor) VisitFunctionCall(ctx *antlr.FunctionCallContext
 		return nil
 	}
 	
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	funcName := ctx.FunctionName().GetText()
 	
+	// SqlCall 无法表达以下修饰，整个调用保留为原文错误节点，避免语义被静默丢弃
+	// ALL 与缺省语义相同，直接忽略
+	unsupported := ""
+	switch {
+	case ctx.SetQuantifier() != nil && ctx.SetQuantifier().DISTINCT() != nil:
+		unsupported = "不支持的函数参数 DISTINCT"
+	case ctx.FILTER() != nil:
+		unsupported = "不支持的函数 FILTER 子句"
+	case ctx.GetNullsOption() != nil:
+		unsupported = "不支持的函数 IGNORE/RESPECT NULLS"
+	case ctx.OVER() != nil:
+		unsupported = "不支持的窗口函数 OVER 子句"
+	}
+	if unsupported != "" {
+		v.newError(unsupported, ctx)
+		return v.sourceErrorNode(ctx.GetStart(), ctx.GetStop())
+	}
+	
 	// 收集参数
 	operands := []SqlNode{}
 	allArgs := ctx.AllExpression()
@@ -1010,22 +1758,120 @@ func (v *SqlNodeBuilderVisitor) VisitFunctionCall(ctx *antlr.FunctionCallContext
 		}
 	}
 	
-	op := &SqlOperator{
-		Name:   strings.ToUpper(funcName),
-		Kind:   SqlKindCall,
-		Syntax: SyntaxFunction,
-	}
+	op := NewSqlOperator(strings.ToUpper(funcName), SqlKindCall, SyntaxFunction)
 	
 	return NewSqlCall(op, operands, pos)
 }
 
+// =============================================================================
+// Complex Types - Lambda、元素访问、STRUCT 和行构造
+// =============================================================================
+
+// VisitLambda 访问 lambda 表达式
+// lambda: identifier ARROW expression | LEFT_PAREN identifier (COMMA identifier)+ RIGHT_PAREN ARROW expression
+func (v *SqlNodeBuilderVisitor) VisitLambda(ctx *antlr.LambdaContext) interface{} {
+	if ctx == nil || ctx.Expression() == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	
+	params := []*SqlIdentifier{}
+	scope := make(map[string]bool)
+	for _, identCtx := range ctx.AllIdentifier() {
+		name := identCtx.GetText()
+		scope[name] = true
+		params = append(params, NewSqlIdentifier([]string{name}, v.getPositionFromContext(identCtx)))
+	}
+	
+	// 参数只在 lambda 体内可见
+	v.lambdaScopes = append(v.lambdaScopes, scope)
+	defer func() {
+		v.lambdaScopes = v.lambdaScopes[:len(v.lambdaScopes)-1]
+	}()
+	
+	expr, ok := ctx.Expression().(*antlr.ExpressionContext)
+	if !ok {
+		return nil
+	}
+	body, ok := v.VisitExpression(expr).(SqlNode)
+	if !ok {
+		return nil
+	}
+	
+	return NewSqlLambda(params, body, pos)
+}
+
+// VisitSubscript 访问元素访问表达式 (arr[0], m['key'])
+func (v *SqlNodeBuilderVisitor) VisitSubscript(ctx *antlr.SubscriptContext) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	
+	value, ok1 := v.visitPrimaryExpressionInternal(ctx.GetValue()).(SqlNode)
+	index, ok2 := v.visitValueExpressionInternal(ctx.GetIndex()).(SqlNode)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	
+	op := NewSqlOperator("ITEM", SqlKindItem, SyntaxSpecial)
+	return NewSqlCall(op, []SqlNode{value, index}, pos)
+}
+
+// VisitStruct 访问 STRUCT 构造 (STRUCT(a, b AS c))
+func (v *SqlNodeBuilderVisitor) VisitStruct(ctx *antlr.StructContext) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	op := NewSqlOperator("STRUCT", SqlKindStruct, SyntaxFunction)
+	return NewSqlCall(op, v.visitNamedExpressionList(ctx.AllNamedExpression()), pos)
+}
+
+// VisitRowConstructor 访问行构造 ((a, b))
+func (v *SqlNodeBuilderVisitor) VisitRowConstructor(ctx *antlr.RowConstructorContext) interface{} {
+	if ctx == nil {
+		return nil
+	}
+	
+	pos := v.getPositionFromContext(ctx)
+	op := NewSqlOperator("ROW", SqlKindRow, SyntaxSpecial)
+	return NewSqlCall(op, v.visitNamedExpressionList(ctx.AllNamedExpression()), pos)
+}
+
+// visitNamedExpressionList 访问命名表达式列表
+func (v *SqlNodeBuilderVisitor) visitNamedExpressionList(list []antlr.INamedExpressionContext) []SqlNode {
+	result := []SqlNode{}
+	for _, namedExprIface := range list {
+		if namedExpr, ok := namedExprIface.(*antlr.NamedExpressionContext); ok {
+			if node, ok := v.VisitNamedExpression(namedExpr).(SqlNode); ok {
+				result = append(result, node)
+			}
+		}
+	}
+	return result
+}
+
+// isLambdaParameter 判断名字是否为当前作用域内的 lambda 参数
+func (v *SqlNodeBuilderVisitor) isLambdaParameter(name string) bool {
+	for i := len(v.lambdaScopes) - 1; i >= 0; i-- {
+		if v.lambdaScopes[i][name] {
+			return true
+		}
+	}
+	return false
+}
+
 // =============================================================================
 // Literals - 字面量处理
 // =============================================================================
 
 // VisitNullLiteral 访问 NULL 字面量
 func (v *SqlNodeBuilderVisitor) VisitNullLiteral(ctx *antlr.NullLiteralContext) interface{} {
-	return NewSqlLiteral(nil, LiteralNull, v.getPosition(ctx.GetStart()))
+	return NewSqlLiteral(nil, LiteralNull, v.getPositionFromContext(ctx))
 }
 
 // VisitNumericLiteral 访问数字字面量
@@ -1051,7 +1897,7 @@ func (v *SqlNodeBuilderVisitor) VisitNumericLiteral(ctx *antlr.NumericLiteralCon
 
 // VisitIntegerLiteral 访问整数字面量
 func (v *SqlNodeBuilderVisitor) VisitIntegerLiteral(ctx *antlr.IntegerLiteralContext) interface{} {
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	text := ctx.GetText()
 	
 	value, err := strconv.ParseInt(text, 10, 64)
@@ -1064,7 +1910,7 @@ func (v *SqlNodeBuilderVisitor) VisitIntegerLiteral(ctx *antlr.IntegerLiteralCon
 
 // VisitDecimalLiteral 访问小数字面量
 func (v *SqlNodeBuilderVisitor) VisitDecimalLiteral(ctx *antlr.DecimalLiteralContext) interface{} {
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	text := ctx.GetText()
 	
 	value, err := strconv.ParseFloat(text, 64)
@@ -1077,7 +1923,7 @@ func (v *SqlNodeBuilderVisitor) VisitDecimalLiteral(ctx *antlr.DecimalLiteralCon
 
 // VisitStringLiteral 访问字符串字面量
 func (v *SqlNodeBuilderVisitor) VisitStringLiteral(ctx *antlr.StringLiteralContext) interface{} {
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	
 	// 收集所有字符串
 	allStr := ctx.AllStringLit()
@@ -1088,12 +1934,7 @@ func (v *SqlNodeBuilderVisitor) VisitStringLiteral(ctx *antlr.StringLiteralConte
 	// 拼接多个字符串
 	var sb strings.Builder
 	for _, str := range allStr {
-		text := str.GetText()
-		// 去除引号
-		if len(text) >= 2 {
-			text = text[1 : len(text)-1]
-		}
-		sb.WriteString(text)
+		sb.WriteString(v.getStringLitText(str))
 	}
 	
 	return NewSqlLiteral(sb.String(), LiteralString, pos)
@@ -1101,7 +1942,7 @@ func (v *SqlNodeBuilderVisitor) VisitStringLiteral(ctx *antlr.StringLiteralConte
 
 // VisitBooleanLiteral 访问布尔字面量
 func (v *SqlNodeBuilderVisitor) VisitBooleanLiteral(ctx *antlr.BooleanLiteralContext) interface{} {
-	pos := v.getPosition(ctx.GetStart())
+	pos := v.getPositionFromContext(ctx)
 	value := strings.ToUpper(ctx.BooleanValue().GetText()) == "TRUE"
 	return NewSqlLiteral(value, LiteralBoolean, pos)
 }
@@ -1152,176 +1993,15 @@ func (v *SqlNodeBuilderVisitor) VisitHavingClause(ctx *antlr.HavingClauseContext
 // JOIN 处理逻辑
 // =============================================================================
 
-// dealJoinList2Node 将表列表和 join 条件转换为 JOIN 树
-// 参考 Java 版本的实现逻辑
-func (v *SqlNodeBuilderVisitor) dealJoinList2Node(fromList []SqlNode) SqlNode {
-	if len(fromList) <= 1 {
-		if len(fromList) == 1 {
-			return fromList[0]
-		}
-		return nil
-	}
-	
-	// 提取表名
-	fromTables := []string{}
-	for _, node := range fromList {
-		tableName := v.extractTableName(node)
-		if tableName != "" {
-			fromTables = append(fromTables, tableName)
-		}
-	}
-	
-	// 如果没有 join 条件，构建笛卡尔积
-	if len(v.joinConditions) == 0 {
-		return v.buildCartesianProduct(fromList)
-	}
-	
-	// 提取 join 条件中涉及的表
-	joinTables := make(map[string]bool)
-	for _, condition := range v.joinConditions {
-		tables := v.getConditionTables(condition)
-		for _, table := range tables {
-			joinTables[table] = true
-		}
-	}
-	
-	// 找出不在 join 条件中的表（需要笛卡尔积连接的表）
-	extraTables := []string{}
-	for _, table := range fromTables {
-		if !joinTables[table] {
-			extraTables = append(extraTables, table)
-		}
-	}
-	
-	// 构建 JOIN 树
-	var joinBuilder SqlNode
-	if len(v.joinConditions) > 0 {
-		joinBuilder = v.appendJoinFromConditions(v.joinConditions)
-	}
-	
-	// 连接额外的表（笛卡尔积）
-	if joinBuilder != nil {
-		for _, extraTable := range extraTables {
-			rightNode := NewSqlIdentifier([]string{extraTable}, &SqlParserPos{})
-			// 构建 1=1 的条件（笛卡尔积）
-			oneLiteral := NewSqlLiteral(int64(1), LiteralInteger, &SqlParserPos{})
-			condition := NewSqlCall(
-				&SqlOperator{Name: "=", Kind: SqlKindEquals, Syntax: SyntaxBinary},
-				[]SqlNode{oneLiteral, oneLiteral},
-				&SqlParserPos{},
-			)
-			joinBuilder = NewSqlJoin(joinBuilder, rightNode, JoinInner, condition, &SqlParserPos{})
-		}
-	} else if len(extraTables) > 0 {
-		// 没有 join 条件，全部是笛卡尔积
-		joinBuilder = NewSqlIdentifier([]string{extraTables[0]}, &SqlParserPos{})
-		for i := 1; i < len(extraTables); i++ {
-			rightNode := NewSqlIdentifier([]string{extraTables[i]}, &SqlParserPos{})
-			oneLiteral := NewSqlLiteral(int64(1), LiteralInteger, &SqlParserPos{})
-			condition := NewSqlCall(
-				&SqlOperator{Name: "=", Kind: SqlKindEquals, Syntax: SyntaxBinary},
-				[]SqlNode{oneLiteral, oneLiteral},
-				&SqlParserPos{},
-			)
-			joinBuilder = NewSqlJoin(joinBuilder, rightNode, JoinInner, condition, &SqlParserPos{})
-		}
-	}
-	
-	return joinBuilder
-}
-
-// appendJoinFromConditions 根据 join 条件构建 JOIN 树
-func (v *SqlNodeBuilderVisitor) appendJoinFromConditions(joinConditions []*SqlCall) SqlNode {
-	if len(joinConditions) == 0 {
-		return nil
-	}
-	
-	var joinBuilder SqlNode
-	usedTables := make(map[string]bool)
-	
-	for _, condition := range joinConditions {
-		tables := v.getConditionTables(condition)
-		if len(tables) < 2 {
-			continue
-		}
-		
-		leftTable := tables[0]
-		rightTable := tables[1]
-		
-		// 获取表节点（可能是子查询）
-		var leftNode SqlNode = NewSqlIdentifier([]string{leftTable}, &SqlParserPos{})
-		var rightNode SqlNode = NewSqlIdentifier([]string{rightTable}, &SqlParserPos{})
-		
-		if subQuery, exists := v.subQueryTables[leftTable]; exists {
-			leftNode = subQuery
-		}
-		if subQuery, exists := v.subQueryTables[rightTable]; exists {
-			rightNode = subQuery
-		}
-		
-		if joinBuilder == nil {
-			joinBuilder = leftNode
-			usedTables[leftTable] = true
-		}
-		
-		// 选择连接节点（优先使用右侧，但如果右侧已使用则使用左侧）
-		connectNode := rightNode
-		if usedTables[rightTable] {
-			connectNode = leftNode
-		}
-		
-		// 构建 JOIN
-		joinBuilder = NewSqlJoin(
-			joinBuilder,
-			connectNode,
-			JoinInner,
-			condition,
-			&SqlParserPos{},
-		)
-		
-		usedTables[leftTable] = true
-		usedTables[rightTable] = true
-	}
-	
-	return joinBuilder
-}
-
-// buildCartesianProduct 构建笛卡尔积
-func (v *SqlNodeBuilderVisitor) buildCartesianProduct(fromList []SqlNode) SqlNode {
+// buildCommaJoin 将 FROM a, b, c 构建为左深的 COMMA JOIN 树
+func (v *SqlNodeBuilderVisitor) buildCommaJoin(fromList []SqlNode) SqlNode {
 	if len(fromList) == 0 {
 		return nil
 	}
 	
 	result := fromList[0]
 	for i := 1; i < len(fromList); i++ {
-		// 构建 1=1 条件（笛卡尔积）
-		oneLiteral := NewSqlLiteral(int64(1), LiteralInteger, &SqlParserPos{})
-		condition := NewSqlCall(
-			&SqlOperator{Name: "=", Kind: SqlKindEquals, Syntax: SyntaxBinary},
-			[]SqlNode{oneLiteral, oneLiteral},
-			&SqlParserPos{},
-		)
-		result = NewSqlJoin(result, fromList[i], JoinInner, condition, &SqlParserPos{})
-	}
-	
-	return result
-}
-
-// dealFilterConditions 处理 filter 条件，合并为单个 WHERE 表达式
-func (v *SqlNodeBuilderVisitor) dealFilterConditions() SqlNode {
-	if len(v.filterConditions) == 0 {
-		return nil
-	}
-	
-	if len(v.filterConditions) == 1 {
-		return v.filterConditions[0]
-	}
-	
-	// 用 AND 连接所有条件
-	var result SqlNode = v.filterConditions[0]
-	for i := 1; i < len(v.filterConditions); i++ {
-		op := &SqlOperator{Name: "AND", Kind: SqlKindAnd, Syntax: SyntaxBinary}
-		result = NewSqlCall(op, []SqlNode{result, v.filterConditions[i]}, &SqlParserPos{})
+		result = NewSqlJoin(result, fromList[i], JoinComma, nil, syntheticPos(result, fromList[i]))
 	}
 	
 	return result
@@ -1331,20 +2011,6 @@ func (v *SqlNodeBuilderVisitor) dealFilterConditions() SqlNode {
 // Helper Methods - 辅助方法
 // =============================================================================
 
-// getPosition 从 Token 获取位置信息
-func (v *SqlNodeBuilderVisitor) getPosition(token antlr4.Token) *SqlParserPos {
-	if token == nil {
-		return &SqlParserPos{LineNumber: 0, ColumnNumber: 0}
-	}
-	
-	return &SqlParserPos{
-		LineNumber:   token.GetLine(),
-		ColumnNumber: token.GetColumn(),
-		EndLine:      token.GetLine(),
-		EndColumn:    token.GetColumn() + len(token.GetText()),
-	}
-}
-
 // getOperatorKind 根据操作符文本获取 SqlKind
 func (v *SqlNodeBuilderVisitor) getOperatorKind(op string) SqlKind {
 	switch op {
@@ -1365,76 +2031,138 @@ func (v *SqlNodeBuilderVisitor) getOperatorKind(op string) SqlKind {
 	case "<":
 		return SqlKindLessThan
 	case ">=":
-		return SqlKindOther // TODO: 添加 GreaterThanOrEqual
+		return SqlKindGreaterThanOrEqual
 	case "<=":
-		return SqlKindOther // TODO: 添加 LessThanOrEqual
+		return SqlKindLessThanOrEqual
 	default:
 		return SqlKindOther
 	}
 }
 
-// extractTableName 从节点中提取表名
-func (v *SqlNodeBuilderVisitor) extractTableName(node SqlNode) string {
-	if node == nil {
-		return ""
+// newError 创建不支持语法的错误并记录下来
+// 返回值作为访问结果时会被调用方当作缺失节点丢弃，记录的错误由 parseSQL 按严格/宽松模式处理
+func (v *SqlNodeBuilderVisitor) newError(msg string, ctx interface{}) error {
+	err := &UnsupportedFeatureError{
+		Rule:    ruleNameOf(ctx),
+		Message: msg,
+		Pos:     &SqlParserPos{},
 	}
+	if ruleCtx, ok := ctx.(antlr4.ParserRuleContext); ok && ruleCtx != nil {
+		err.Pos = v.getPositionFromContext(ruleCtx)
+		err.Text = getSourceText(ruleCtx)
+	}
+	v.unsupported = append(v.unsupported, err)
+	return err
+}
+
+// =============================================================================
+// 错误恢复
+// =============================================================================
+
+// buildPartialSqlNode 在恢复模式下从含有错误的解析树构建部分 SqlNode
+// 无法构建时整条语句作为一个 SqlErrorNode 返回
+func (v *SqlNodeBuilderVisitor) buildPartialSqlNode(ctx *antlr.SingleStatementContext, sql string) (node SqlNode) {
+	fallback := NewSqlErrorNode(sql, v.getPositionFromContext(ctx))
+	
+	defer func() {
+		// 部分语法树中子节点可能缺失，访问时的 panic 视为构建失败
+		// 超过资源限制或被取消引起的中止继续向上传递
+		if r := recover(); r != nil {
+			if abort, ok := r.(*parseAbort); ok {
+				panic(abort)
+			}
+			node = fallback
+		}
+	}()
 	
-	// 如果是标识符
-	if identifier, ok := node.(*SqlIdentifier); ok {
-		if len(identifier.Names) > 0 {
-			return identifier.Names[0]
+	if sqlNode, ok := v.VisitSingleStatement(ctx).(SqlNode); ok && sqlNode != nil {
+		return sqlNode
+	}
+	return fallback
+}
+
+// errorNodeFor 恢复模式下，如果 ctx 覆盖的区域包含语法错误，返回对应的 SqlErrorNode
+// 非恢复模式或区域内没有错误时返回 nil
+func (v *SqlNodeBuilderVisitor) errorNodeFor(ctx antlr4.ParserRuleContext) SqlNode {
+	if !v.recoverErrors || ctx == nil || !hasSyntaxError(ctx) {
+		return nil
+	}
+	return NewSqlErrorNode(getSourceText(ctx), v.getPositionFromContext(ctx))
+}
+
+// sourceErrorNode 返回覆盖 start 到 stop 原文的 SqlErrorNode
+// 用于代替含有不支持语法、无法完整构建的节点，Unparse 时原样输出，不会生成含义不同的 SQL
+func (v *SqlNodeBuilderVisitor) sourceErrorNode(start, stop antlr4.Token) SqlNode {
+	text := ""
+	if input := start.GetInputStream(); input != nil && stop.GetStop() >= start.GetStart() {
+		text = input.GetText(start.GetStart(), stop.GetStop())
+	}
+	return NewSqlErrorNode(text, v.tokenSpan(start, stop))
+}
+
+// hasSyntaxError 判断解析树中是否包含错误节点（ANTLR 恢复时插入或跳过的 token）
+func hasSyntaxError(tree antlr4.Tree) bool {
+	if _, ok := tree.(antlr4.ErrorNode); ok {
+		return true
+	}
+	if ctx, ok := tree.(antlr4.ParserRuleContext); ok {
+		// 规则未消费任何 token 就退出，说明在此处出错
+		if ctx.GetStart() != nil && ctx.GetStop() != nil && ctx.GetStop().GetTokenIndex() < ctx.GetStart().GetTokenIndex() {
+			return true
 		}
 	}
-	
-	// 如果是带别名的表（AS 操作符）
-	if call, ok := node.(*SqlCall); ok {
-		if call.Operator != nil && call.Operator.Kind == SqlKindAs {
-			if len(call.Operands) >= 2 {
-				// 第二个操作数是别名
-				if aliasNode, ok := call.Operands[1].(*SqlIdentifier); ok {
-					return aliasNode.GetSimple()
-				}
-			}
+	for _, child := range tree.GetChildren() {
+		if hasSyntaxError(child) {
+			return true
 		}
 	}
-	
-	return ""
+	return false
 }
 
-// getConditionTables 从 join 条件中提取表名
-func (v *SqlNodeBuilderVisitor) getConditionTables(condition *SqlCall) []string {
-	tables := []string{}
-	
-	if condition == nil || len(condition.Operands) < 2 {
-		return tables
+// getSourceText 获取 ctx 覆盖区域的原始 SQL 文本（保留空白）
+func getSourceText(ctx antlr4.ParserRuleContext) string {
+	start := ctx.GetStart()
+	stop := ctx.GetStop()
+	if start == nil || stop == nil || stop.GetStop() < start.GetStart() {
+		return ""
 	}
-	
-	// 从左右操作数中提取表名
-	for _, operand := range condition.Operands {
-		if identifier, ok := operand.(*SqlIdentifier); ok {
-			if len(identifier.Names) >= 2 {
-				// 假设格式为 table.column
-				tables = append(tables, identifier.Names[0])
+	if input := start.GetInputStream(); input != nil {
+		return input.GetText(start.GetStart(), stop.GetStop())
+	}
+	return ctx.GetText()
+}
+
+// getTokenText 按 token 重建 ctx 的文本，原文中有空白或注释分隔的 token 之间保留一个空格
+// 用于 INTERVAL DAY TO SECOND 这类多个单词组成的写法，GetText 会把它们直接拼接
+func getTokenText(ctx antlr4.ParserRuleContext) string {
+	var sb strings.Builder
+	var prev antlr4.Token
+	var walk func(tree antlr4.Tree)
+	walk = func(tree antlr4.Tree) {
+		if terminal, ok := tree.(antlr4.TerminalNode); ok {
+			token := terminal.GetSymbol()
+			if prev != nil && token.GetStart() > prev.GetStop()+1 {
+				sb.WriteByte(' ')
 			}
+			sb.WriteString(token.GetText())
+			prev = token
+			return
 		}
-	}
-	
-	// 去重
-	uniqueTables := make(map[string]bool)
-	result := []string{}
-	for _, table := range tables {
-		if !uniqueTables[table] {
-			uniqueTables[table] = true
-			result = append(result, table)
+		for _, child := range tree.GetChildren() {
+			walk(child)
 		}
 	}
-	
-	return result
+	walk(ctx)
+	return sb.String()
 }
 
-// newError 创建错误
-func (v *SqlNodeBuilderVisitor) newError(msg string, ctx interface{}) error {
-	return fmt.Errorf("%s: %v", msg, ctx)
+// getUnsupportedWarnings 以 error 列表形式返回遇到的不支持语法
+func (v *SqlNodeBuilderVisitor) getUnsupportedWarnings() []error {
+	warnings := make([]error, len(v.unsupported))
+	for i, err := range v.unsupported {
+		warnings[i] = err
+	}
+	return warnings
 }
 
 // =============================================================================
@@ -1558,28 +2286,34 @@ func (v *SqlNodeBuilderVisitor) getIdentifierText(ctx *antlr.IdentifierContext)
 	return ctx.GetText()
 }
 
-// getPositionFromContext 从上下文获取位置信息
+// getPositionFromContext 从规则上下文获取覆盖整个规则的位置信息
 func (v *SqlNodeBuilderVisitor) getPositionFromContext(ctx antlr4.ParserRuleContext) *SqlParserPos {
 	if ctx == nil || ctx.GetStart() == nil {
 		return &SqlParserPos{}
 	}
 	
-	start := ctx.GetStart()
-	stop := ctx.GetStop()
+	start, stop := ctx.GetStart(), ctx.GetStop()
+	// 空规则的 stop 在 start 之前
+	if stop == nil || stop.GetTokenIndex() < start.GetTokenIndex() {
+		stop = start
+	}
+	return v.tokenSpan(start, stop)
+}
+
+// tokenSpan 计算从 start 到 stop（含）的位置信息
+func (v *SqlNodeBuilderVisitor) tokenSpan(start, stop antlr4.Token) *SqlParserPos {
+	if v.source == nil {
+		v.source = newSourceIndex(start.GetInputStream())
+	}
 	
 	pos := &SqlParserPos{
 		LineNumber:   start.GetLine(),
 		ColumnNumber: start.GetColumn(),
+		StartOffset:  v.source.byteOffset(start.GetStart()),
+		EndOffset:    v.source.byteOffset(stop.GetStop() + 1),
 	}
-	
-	if stop != nil {
-		pos.EndLine = stop.GetLine()
-		pos.EndColumn = stop.GetColumn() + len(stop.GetText())
-	} else {
-		pos.EndLine = pos.LineNumber
-		pos.EndColumn = pos.ColumnNumber + len(start.GetText())
-	}
-	
+	end := tokenEnd(stop)
+	pos.EndLine, pos.EndColumn = end.line, end.column
 	return pos
 }
 
@@ -0,0 +1,129 @@
+package parser
+
+// =============================================================================
+// Walk / Inspect - 通用树遍历
+// =============================================================================
+
+// Walker Walk 使用的遍历器，参考 go/ast.Visitor
+// Visit 对每个节点调用；返回值 w 不为 nil 时，Walk 用 w 访问该节点的各个子节点，
+// 子节点访问完毕后再调用 w.Visit(nil)
+type Walker interface {
+	Visit(node SqlNode) (w Walker)
+}
+
+// Walk 深度优先遍历语法树，参考 go/ast.Walk
+func Walk(w Walker, node SqlNode) {
+	if node == nil {
+		return
+	}
+	if w = w.Visit(node); w == nil {
+		return
+	}
+	forEachChildNode(node, func(child SqlNode) bool {
+		Walk(w, child)
+		return true
+	})
+	w.Visit(nil)
+}
+
+// inspector 把函数适配为 Walker
+type inspector func(SqlNode) bool
+
+// Visit 实现 Walker 接口
+func (f inspector) Visit(node SqlNode) Walker {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect 深度优先遍历语法树，参考 go/ast.Inspect
+// 先对节点调用 f(node)，返回 true 时继续遍历其子节点，子节点遍历完毕后调用 f(nil)
+//
+//	parser.Inspect(node, func(n parser.SqlNode) bool {
+//		if id, ok := n.(*parser.SqlIdentifier); ok {
+//			fmt.Println(id.ToString())
+//		}
+//		return true
+//	})
+func Inspect(node SqlNode, f func(SqlNode) bool) {
+	Walk(inspector(f), node)
+}
+
+// forEachChildNode 按子句顺序对 node 的直接子节点调用 fn，跳过为 nil 的子节点
+// fn 返回 false 时停止枚举。新增节点类型时只需在这里补充子节点，
+// Walk、Inspect 和 BaseSqlNodeVisitor 都基于它遍历
+func forEachChildNode(node SqlNode, fn func(SqlNode) bool) {
+	stopped := false
+	visit := func(child SqlNode) {
+		if !stopped && child != nil {
+			stopped = !fn(child)
+		}
+	}
+	visitList := func(nodes []SqlNode) {
+		for _, child := range nodes {
+			visit(child)
+		}
+	}
+	visitIdentifier := func(identifier *SqlIdentifier) {
+		if identifier != nil {
+			visit(identifier)
+		}
+	}
+
+	switch n := node.(type) {
+	case *SqlCall:
+		visitList(n.Operands)
+	case *SqlBasicCall:
+		visit(n.Operand)
+	case *SqlNodeList:
+		visitList(n.List)
+	case *SqlHint:
+		visitList(n.Parameters)
+	case *SqlSelect:
+		for _, hint := range n.Hints {
+			if hint != nil {
+				visit(hint)
+			}
+		}
+		visitList(n.SelectList)
+		visit(n.From)
+		visit(n.Where)
+		visitList(n.GroupBy)
+		visit(n.Having)
+		visitList(n.WindowDecls)
+		visitList(n.OrderBy)
+		visit(n.Offset)
+		visit(n.Fetch)
+	case *SqlJoin:
+		visit(n.Left)
+		visit(n.Right)
+		visit(n.Condition)
+		visitList(n.Using)
+	case *SqlLambda:
+		for _, param := range n.Parameters {
+			visitIdentifier(param)
+		}
+		visit(n.Body)
+	case *SqlTableRef:
+		visitIdentifier(n.Name)
+		if n.Temporal != nil {
+			visit(n.Temporal.Value)
+		}
+		if n.Sample != nil {
+			visit(n.Sample.Value)
+			visit(n.Sample.BucketOn)
+		}
+	case *SqlExplain:
+		visit(n.Statement)
+	case *SqlDescribe:
+		visitIdentifier(n.Table)
+		visitIdentifier(n.Column)
+		visit(n.Query)
+	case *SqlShow:
+		visitIdentifier(n.Object)
+		visitIdentifier(n.Namespace)
+	case *SqlUse:
+		visitIdentifier(n.Namespace)
+	}
+}
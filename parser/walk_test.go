@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestInspectVisitsAllNodes 测试 Inspect 访问所有子句，并在子节点之后调用 f(nil)
+func TestInspectVisitsAllNodes(t *testing.T) {
+	tree := buildCloneTestTree()
+
+	identifiers, enters, leaves := 0, 0, 0
+	Inspect(tree, func(node SqlNode) bool {
+		if node == nil {
+			leaves++
+			return false
+		}
+		enters++
+		if _, ok := node.(*SqlIdentifier); ok {
+			identifiers++
+		}
+		return true
+	})
+
+	if identifiers != 17 {
+		t.Errorf("标识符数量 = %d, 期望 17", identifiers)
+	}
+	if enters != leaves {
+		t.Errorf("进入 %d 次, 离开 %d 次, 应当相等", enters, leaves)
+	}
+}
+
+// TestInspectSkipsChildren 测试 f 返回 false 时跳过子节点
+func TestInspectSkipsChildren(t *testing.T) {
+	tree := buildCloneTestTree()
+
+	identifiers := 0
+	Inspect(tree, func(node SqlNode) bool {
+		switch node.(type) {
+		case *SqlLambda:
+			return false
+		case *SqlIdentifier:
+			identifiers++
+		}
+		return true
+	})
+
+	if identifiers != 15 {
+		t.Errorf("跳过 lambda 后标识符数量 = %d, 期望 15", identifiers)
+	}
+}
+
+// literalCollector 只覆盖 VisitLiteral 的访问者
+type literalCollector struct {
+	*BaseSqlNodeVisitor
+	values []interface{}
+	failOn interface{}
+}
+
+func (c *literalCollector) VisitLiteral(node *SqlLiteral) (interface{}, error) {
+	if c.failOn != nil && node.Value == c.failOn {
+		return nil, errors.New("stop")
+	}
+	c.values = append(c.values, node.Value)
+	return nil, nil
+}
+
+// TestBaseSqlNodeVisitor 测试嵌入默认访问者后只需覆盖关心的方法
+func TestBaseSqlNodeVisitor(t *testing.T) {
+	tree := buildCloneTestTree()
+
+	collector := &literalCollector{}
+	collector.BaseSqlNodeVisitor = NewBaseSqlNodeVisitor(collector)
+	if _, err := tree.Accept(collector); err != nil {
+		t.Fatalf("遍历失败: %v", err)
+	}
+
+	// lambda 体、时间旅行、采样、WHERE、HAVING、OFFSET、FETCH 中的字面量
+	if len(collector.values) != 7 {
+		t.Errorf("字面量 = %v, 期望 7 个", collector.values)
+	}
+}
+
+// TestBaseSqlNodeVisitorStopsOnError 测试子节点返回错误时停止遍历
+func TestBaseSqlNodeVisitorStopsOnError(t *testing.T) {
+	tree := buildCloneTestTree()
+
+	collector := &literalCollector{failOn: "v"}
+	collector.BaseSqlNodeVisitor = NewBaseSqlNodeVisitor(collector)
+	if _, err := tree.Accept(collector); err == nil || err.Error() != "stop" {
+		t.Fatalf("期望返回子节点的错误, 实际为 %v", err)
+	}
+
+	// WHERE 之前只有 lambda 体、时间旅行和采样中的字面量
+	if len(collector.values) != 3 {
+		t.Errorf("出错前的字面量 = %v, 期望 3 个", collector.values)
+	}
+}
+
+// identifierCounter 用带类型结果的访问者统计标识符
+type identifierCounter struct {
+	*BaseSqlVisitor[int]
+}
+
+func (c *identifierCounter) VisitIdentifier(*SqlIdentifier) (int, error) {
+	return 1, nil
+}
+
+// TestSqlVisitorTypedResult 测试 SqlVisitor[R] 合并子节点结果
+func TestSqlVisitorTypedResult(t *testing.T) {
+	tree := buildCloneTestTree()
+
+	counter := &identifierCounter{}
+	counter.BaseSqlVisitor = NewBaseSqlVisitor[int](counter)
+	counter.Aggregate = func(total, next int) int { return total + next }
+
+	count, err := Visit[int](tree, counter)
+	if err != nil {
+		t.Fatalf("遍历失败: %v", err)
+	}
+	if count != 17 {
+		t.Errorf("标识符数量 = %d, 期望 17", count)
+	}
+
+	if count, _ := Visit[int](nil, counter); count != 0 {
+		t.Errorf("nil 节点应返回零值, 实际为 %d", count)
+	}
+}
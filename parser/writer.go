@@ -0,0 +1,836 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// =============================================================================
+// SqlWriter - SQL 输出
+// =============================================================================
+
+// SqlWriterConfig SQL 输出选项
+type SqlWriterConfig struct {
+	Dialect             SqlDialect     // 目标方言，默认为 SparkDialect
+	QuoteAllIdentifiers bool           // 所有标识符都加引号，默认只括起无法直接书写的标识符
+	Format              *FormatOptions // 格式化选项，为 nil 时输出为一行
+}
+
+// SqlWriter 把 SqlNode 树输出为 SQL 文本，类似 Calcite 的 SqlWriter
+//
+// 只在优先级需要时添加括号，标识符按需加反引号，字符串字面量转义后加单引号，
+// 因此对解析得到的树 t 有 parse(Unparse(t)) 与 t 结构相等（忽略位置信息）
+type SqlWriter struct {
+	sb      strings.Builder
+	config  SqlWriterConfig
+	dialect SqlDialect
+
+	// 格式化状态，见 format.go
+	format     *FormatOptions // 已填充默认值的格式化选项，为 nil 时不换行
+	lower      bool           // 关键字输出为小写
+	indent     int            // 当前缩进层级
+	column     int            // 当前行已输出的字符数
+	breakJoins bool           // 当前 FROM 中的 JOIN 是否各占一行
+
+	lineComments []string // 等待输出的行注释，之后的内容需要另起一行
+}
+
+// NewSqlWriter 创建 SqlWriter
+func NewSqlWriter(config SqlWriterConfig) *SqlWriter {
+	dialect := config.Dialect
+	if dialect == nil {
+		dialect = SparkDialect
+	}
+	w := &SqlWriter{config: config, dialect: dialect}
+	if config.Format != nil {
+		format := config.Format.withDefaults()
+		w.format = &format
+		w.lower = format.KeywordCase == KeywordLower
+	}
+	return w
+}
+
+// Unparse 使用默认选项把节点输出为 Spark SQL，nil 返回空字符串
+func Unparse(node SqlNode) string {
+	return UnparseWithDialect(node, SparkDialect)
+}
+
+// UnparseWithDialect 把节点输出为指定方言的 SQL
+func UnparseWithDialect(node SqlNode, dialect SqlDialect) string {
+	w := NewSqlWriter(SqlWriterConfig{Dialect: dialect})
+	w.Write(node)
+	return w.String()
+}
+
+// Write 把节点作为一条完整的语句或表达式追加到输出中
+func (w *SqlWriter) Write(node SqlNode) {
+	w.writeStatement(node)
+	w.flushLineComments("")
+}
+
+// String 返回已输出的 SQL
+func (w *SqlWriter) String() string {
+	return w.sb.String()
+}
+
+// Reset 清空已输出的内容
+func (w *SqlWriter) Reset() {
+	w.sb.Reset()
+	w.indent, w.column, w.breakJoins, w.lineComments = 0, 0, false, nil
+}
+
+// writeStatement 输出语句位置上的节点，顶层查询不加括号
+func (w *SqlWriter) writeStatement(node SqlNode) {
+	if sel, ok := node.(*SqlSelect); ok {
+		if comments := sel.Comments; comments != nil {
+			w.writeLeadingComments(comments.Leading)
+			defer w.writeTrailingComments(comments.Trailing)
+		}
+		w.writeSelect(sel)
+		return
+	}
+	w.writeNode(node, 0, 0)
+}
+
+// writeNode 在左右优先级分别为 leftPrec、rightPrec 的上下文中输出节点
+// 与 Calcite 的 SqlNode.unparse(writer, leftPrec, rightPrec) 含义相同：
+// 节点左侧紧邻的操作符优先级为 leftPrec，右侧为 rightPrec，优先级不足以抵抗时加括号
+func (w *SqlWriter) writeNode(node SqlNode, leftPrec, rightPrec int) {
+	if comments := NodeComments(node); comments != nil {
+		w.writeLeadingComments(comments.Leading)
+		defer w.writeTrailingComments(comments.Trailing)
+	}
+
+	switch n := node.(type) {
+	case nil:
+	case *SqlIdentifier:
+		w.writeIdentifier(n)
+	case *SqlLiteral:
+		w.writeLiteral(n)
+	case *SqlCall:
+		w.writeCall(n, leftPrec, rightPrec)
+	case *SqlSelect:
+		// 子查询总是加括号
+		w.writeSubquery(n)
+	case *SqlJoin:
+		w.writeJoin(n)
+	case *SqlBasicCall:
+		w.writeNode(n.Operand, 0, 0)
+		if n.Alias != "" {
+			w.keyword(" AS ")
+			w.writeName(n.Alias)
+		}
+	case *SqlNodeList:
+		w.writeList(n.List, ", ")
+	case *SqlHint:
+		w.writeHint(n)
+	case *SqlLambda:
+		w.writeLambda(n, leftPrec, rightPrec)
+	case *SqlTableRef:
+		w.writeTableRef(n)
+	case *SqlExplain:
+		w.keyword("EXPLAIN ")
+		if n.Mode != "" {
+			w.keyword(n.Mode)
+			w.write(" ")
+		}
+		w.writeStatement(n.Statement)
+	case *SqlDescribe:
+		w.writeDescribe(n)
+	case *SqlShow:
+		w.writeShow(n)
+	case *SqlUse:
+		w.writeUse(n)
+	case *SqlErrorNode:
+		w.write(n.Text)
+	default:
+		w.write(node.ToString())
+	}
+}
+
+// write 追加原始文本
+func (w *SqlWriter) write(s string) {
+	if len(w.lineComments) > 0 && s != "" {
+		s = w.flushLineComments(s)
+	}
+	w.sb.WriteString(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		w.column = utf8.RuneCountInString(s[i+1:])
+	} else {
+		w.column += utf8.RuneCountInString(s)
+	}
+}
+
+// keyword 按关键字大小写选项输出关键字
+func (w *SqlWriter) keyword(s string) {
+	if w.lower {
+		s = strings.ToLower(s)
+	}
+	w.write(s)
+}
+
+// writeList 用 sep 分隔输出节点列表，每个元素都在最低优先级的上下文中输出
+func (w *SqlWriter) writeList(nodes []SqlNode, sep string) {
+	for i, node := range nodes {
+		if i > 0 {
+			w.write(sep)
+		}
+		w.writeNode(node, 0, 0)
+	}
+}
+
+// =============================================================================
+// 标识符与字面量
+// =============================================================================
+
+// writeIdentifier 输出多部分标识符，各部分按需加反引号
+func (w *SqlWriter) writeIdentifier(identifier *SqlIdentifier) {
+	for i, name := range identifier.Names {
+		if i > 0 {
+			w.write(".")
+		}
+		w.writeName(name)
+	}
+}
+
+// writeName 输出标识符的一个部分
+// 已带反引号的部分（来自解析结果）区分大小写，换成方言的引号后仍然括起
+func (w *SqlWriter) writeName(name string) {
+	if name == "*" {
+		w.write(name)
+		return
+	}
+	if quoted, ok := unquoteIdentifier(name); ok {
+		w.write(w.dialect.QuoteIdentifier(quoted))
+		return
+	}
+	if !w.config.QuoteAllIdentifiers && isSimpleIdentifier(name) {
+		w.write(name)
+		return
+	}
+	w.write(w.dialect.QuoteIdentifier(name))
+}
+
+// unquoteIdentifier 去掉标识符的反引号，两个反引号还原为一个
+func unquoteIdentifier(name string) (string, bool) {
+	if len(name) < 2 || name[0] != '`' || name[len(name)-1] != '`' {
+		return name, false
+	}
+	return strings.ReplaceAll(name[1:len(name)-1], "``", "`"), true
+}
+
+// QuoteIdentifier 用反引号括起标识符，内部的反引号写为两个反引号
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// isSimpleIdentifier 判断名字能否不加反引号直接书写
+func isSimpleIdentifier(name string) bool {
+	if name == "" || reservedWords[strings.ToUpper(name)] {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// reservedWords 作为标识符时必须加反引号的关键字
+var reservedWords = map[string]bool{
+	"ALL": true, "AND": true, "ANY": true, "AS": true, "BETWEEN": true, "BOTH": true, "BY": true,
+	"CASE": true, "CAST": true, "CROSS": true, "DISTINCT": true, "ELSE": true, "END": true,
+	"EXCEPT": true, "EXISTS": true, "FALSE": true, "FETCH": true, "FROM": true, "FULL": true,
+	"GROUP": true, "HAVING": true, "IN": true, "INNER": true, "INTERSECT": true, "INTO": true,
+	"IS": true, "JOIN": true, "LATERAL": true, "LEFT": true, "LIKE": true, "LIMIT": true,
+	"NATURAL": true, "NOT": true, "NULL": true, "OFFSET": true, "ON": true, "OR": true,
+	"ORDER": true, "OUTER": true, "RIGHT": true, "SELECT": true, "SEMI": true, "TABLE": true,
+	"THEN": true, "TRUE": true, "UNION": true, "USING": true, "WHEN": true, "WHERE": true,
+	"WINDOW": true, "WITH": true,
+}
+
+// writeLiteral 输出字面量
+func (w *SqlWriter) writeLiteral(literal *SqlLiteral) {
+	switch literal.ValueType {
+	case LiteralDate, LiteralTime, LiteralTimestamp:
+		w.write(w.dialect.DatetimeLiteral(literal.ValueType, fmt.Sprintf("%v", literal.Value)))
+		return
+	case LiteralInterval:
+		w.write(w.dialect.IntervalLiteral(fmt.Sprintf("%v", literal.Value), literal.TypeName))
+		return
+	case LiteralSymbol:
+		w.write(fmt.Sprintf("%v", literal.Value))
+		return
+	}
+
+	switch value := literal.Value.(type) {
+	case nil:
+		w.keyword("NULL")
+	case bool:
+		w.keyword(w.dialect.BooleanLiteral(value))
+	case int64:
+		w.write(strconv.FormatInt(value, 10))
+	case float64:
+		// 总是带小数点，保证重新解析后仍是小数
+		text := strconv.FormatFloat(value, 'f', -1, 64)
+		if !strings.ContainsAny(text, ".NI") {
+			text += ".0"
+		}
+		w.write(text)
+	case string:
+		w.write(w.dialect.QuoteString(value))
+	default:
+		w.write(fmt.Sprintf("%v", value))
+	}
+}
+
+// QuoteString 把字符串输出为单引号字符串字面量，反斜杠、引号和控制字符按 Spark 的规则转义
+// 与解析时的 unescapeStringLiteral 互逆
+func QuoteString(value string) string {
+	var sb strings.Builder
+	sb.Grow(len(value) + 2)
+	sb.WriteByte('\'')
+	for _, r := range value {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case 0:
+			// 不写成 \0，避免与后续数字组成八进制转义
+			sb.WriteString(`\u0000`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// =============================================================================
+// 操作符调用
+// =============================================================================
+
+// writeCall 输出操作符调用，优先级不足时加括号
+func (w *SqlWriter) writeCall(call *SqlCall, leftPrec, rightPrec int) {
+	op := call.Operator
+	if op == nil {
+		w.write("UNKNOWN")
+		return
+	}
+	opLeft, opRight := op.Precedence()
+	if leftPrec > opLeft || (rightPrec != 0 && opRight <= rightPrec) {
+		w.write("(")
+		defer w.write(")")
+		leftPrec, rightPrec = 0, 0
+	}
+
+	operands := call.Operands
+	switch {
+	case op.Syntax == SyntaxBinary && len(operands) == 2:
+		w.writeNode(operands[0], leftPrec, opLeft)
+		w.write(" ")
+		w.keyword(op.Name)
+		w.write(" ")
+		w.writeNode(operands[1], opRight, rightPrec)
+	case op.Syntax == SyntaxPrefix && len(operands) == 1:
+		w.keyword(op.Name)
+		w.write(" ")
+		w.writeNode(operands[0], opRight, rightPrec)
+	case op.Syntax == SyntaxPostfix && len(operands) == 1:
+		w.writeNode(operands[0], leftPrec, opLeft)
+		w.write(" ")
+		w.keyword(op.Name)
+	case op.Syntax == SyntaxSpecial:
+		w.writeSpecialCall(call, leftPrec)
+	default:
+		w.writeFunction(w.dialect.FunctionName(op.Name), operands)
+	}
+}
+
+// writeSpecialCall 输出特殊语法的操作符调用
+func (w *SqlWriter) writeSpecialCall(call *SqlCall, leftPrec int) {
+	op, operands := call.Operator, call.Operands
+	opLeft, _ := op.Precedence()
+
+	switch {
+	case op.Kind == SqlKindItem && len(operands) == 2:
+		w.writeNode(operands[0], leftPrec, opLeft)
+		w.write("[")
+		w.writeNode(operands[1], 0, 0)
+		w.write("]")
+	case op.Kind == SqlKindDot && len(operands) == 2:
+		w.writeNode(operands[0], leftPrec, opLeft)
+		w.write(".")
+		w.writeNode(operands[1], 0, 0)
+	case op.Kind == SqlKindRow:
+		w.write("(")
+		w.writeList(operands, ", ")
+		w.write(")")
+	case op.Kind == SqlKindAs && len(operands) >= 2:
+		w.writeNode(operands[0], 0, 0)
+		w.keyword(" AS ")
+		w.writeNode(operands[1], 0, 0)
+		if len(operands) > 2 {
+			// 列别名：AS t(a, b)
+			w.write("(")
+			w.writeList(operands[2:], ", ")
+			w.write(")")
+		}
+	case op.Kind == SqlKindCast && len(operands) == 2:
+		// 目标类型保存为符号字面量，按方言转换类型名
+		w.keyword(op.Name)
+		w.write("(")
+		w.writeNode(operands[0], 0, 0)
+		w.keyword(" AS ")
+		if typeName, ok := operands[1].(*SqlLiteral); ok && typeName.ValueType == LiteralSymbol {
+			w.write(w.dialect.CastTypeName(fmt.Sprintf("%v", typeName.Value)))
+		} else {
+			w.writeNode(operands[1], 0, 0)
+		}
+		w.write(")")
+	case (op.Kind == SqlKindIn || op.Kind == SqlKindNotIn) && len(operands) == 2:
+		w.writeNode(operands[0], leftPrec, opLeft)
+		w.write(" ")
+		w.keyword(op.Name)
+		w.write(" ")
+		if _, ok := operands[1].(*SqlSelect); ok {
+			w.writeNode(operands[1], 0, 0)
+		} else {
+			w.write("(")
+			w.writeNode(operands[1], 0, 0)
+			w.write(")")
+		}
+	default:
+		w.writeFunction(op.Name, operands)
+	}
+}
+
+// writeFunction 以函数调用语法输出
+func (w *SqlWriter) writeFunction(name string, operands []SqlNode) {
+	w.write(name)
+	w.write("(")
+	w.writeList(operands, ", ")
+	w.write(")")
+}
+
+// writeLambda 输出 lambda 表达式，函数体会尽量向右延伸，因此不在函数参数等位置时加括号
+func (w *SqlWriter) writeLambda(lambda *SqlLambda, leftPrec, rightPrec int) {
+	parenthesize := leftPrec > 0 || rightPrec > 0
+	if parenthesize {
+		w.write("(")
+	}
+	if len(lambda.Parameters) == 1 && lambda.Parameters[0] != nil {
+		w.writeNode(lambda.Parameters[0], 0, 0)
+	} else {
+		w.write("(")
+		for i, param := range lambda.Parameters {
+			if i > 0 {
+				w.write(", ")
+			}
+			if param != nil {
+				w.writeNode(param, 0, 0)
+			}
+		}
+		w.write(")")
+	}
+	w.write(" -> ")
+	w.writeNode(lambda.Body, 0, 0)
+	if parenthesize {
+		w.write(")")
+	}
+}
+
+// =============================================================================
+// 操作符优先级
+// =============================================================================
+
+// 操作符优先级，数值越大结合越紧，参考 Spark SQL 语法中各规则的层次
+const (
+	precSortOrder  = 10 // 排序项的 DESC、NULLS FIRST 等
+	precOr         = 22
+	precAnd        = 24
+	precNot        = 26
+	precPredicate  = 28 // IS NULL、IN 等谓词
+	precComparison = 30
+	precBitOr      = 32
+	precBitXor     = 34
+	precBitAnd     = 36
+	precAdditive   = 40
+	precMultiply   = 50
+	precUnary      = 60
+	precAccess     = 80  // 元素访问 arr[0] 和字段访问 s.f
+	precPrimary    = 100 // 函数调用、行构造等不需要括号的表达式
+)
+
+// defaultPrecedence 根据操作符的名字、类型和语法推断左右优先级
+// 左结合的二元操作符右优先级比左优先级高 1
+func defaultPrecedence(name string, kind SqlKind, syntax SqlSyntax) (int, int) {
+	switch syntax {
+	case SyntaxBinary:
+		var prec int
+		switch strings.ToUpper(name) {
+		case "OR":
+			prec = precOr
+		case "AND":
+			prec = precAnd
+		case "|":
+			prec = precBitOr
+		case "^":
+			prec = precBitXor
+		case "&":
+			prec = precBitAnd
+		case "+", "-", "||":
+			prec = precAdditive
+		case "*", "/", "%", "DIV":
+			prec = precMultiply
+		default:
+			prec = precComparison
+		}
+		return prec, prec + 1
+	case SyntaxPrefix:
+		if strings.EqualFold(name, "NOT") {
+			return precNot, precNot
+		}
+		return precUnary, precUnary
+	case SyntaxPostfix:
+		switch kind {
+		case SqlKindDescending, SqlKindNullsFirst, SqlKindNullsLast:
+			return precSortOrder, precSortOrder + 1
+		}
+		return precPredicate, precPredicate
+	case SyntaxSpecial:
+		switch kind {
+		case SqlKindItem, SqlKindDot:
+			return precAccess, precAccess + 1
+		case SqlKindIn, SqlKindNotIn:
+			// 谓词不能连用，左右优先级相同使嵌套时总是加括号
+			return precPredicate, precPredicate
+		case SqlKindAs:
+			return 0, 0
+		}
+	}
+	return precPrimary, precPrimary
+}
+
+// =============================================================================
+// 查询与表引用
+// =============================================================================
+
+// writeSelect 输出不带外层括号的 SELECT 语句
+func (w *SqlWriter) writeSelect(sel *SqlSelect) {
+	w.keyword("SELECT")
+	if len(sel.Hints) > 0 {
+		// 多个 hint 合并到同一个注释中
+		w.write(" /*+ ")
+		for i, hint := range sel.Hints {
+			if i > 0 {
+				w.write(", ")
+			}
+			w.writeHintStatement(hint)
+		}
+		w.write(" */")
+	}
+	for _, keyword := range sel.KeywordList {
+		w.write(" ")
+		w.keyword(keyword)
+	}
+	w.writeSelectList(sel.SelectList)
+
+	// 没有 FROM 子句时解析器使用 DUAL 占位
+	if sel.From != nil && !isDualTable(sel.From) {
+		w.clause("FROM")
+		w.writeFrom(sel.From)
+	}
+	if sel.Where != nil {
+		w.clause("WHERE")
+		w.writeCondition(sel.Where)
+	}
+	if len(sel.GroupBy) > 0 {
+		w.clause("GROUP BY")
+		w.writeList(sel.GroupBy, ", ")
+	}
+	if sel.Having != nil {
+		w.clause("HAVING")
+		w.writeCondition(sel.Having)
+	}
+	if len(sel.WindowDecls) > 0 {
+		w.clause("WINDOW")
+		w.writeList(sel.WindowDecls, ", ")
+	}
+	if len(sel.OrderBy) > 0 {
+		w.clause("ORDER BY")
+		for i, item := range sel.OrderBy {
+			if i > 0 {
+				w.write(", ")
+			}
+			w.writeOrderItem(item)
+		}
+	}
+	w.writeOffsetFetch(sel.Offset, sel.Fetch)
+}
+
+// writeOrderItem 输出排序项，方言不支持 NULLS FIRST / NULLS LAST 时先按 x IS NULL 排序
+func (w *SqlWriter) writeOrderItem(item SqlNode) {
+	call, ok := item.(*SqlCall)
+	if !ok || w.dialect.SupportsNullsOrdering() || len(call.Operands) != 1 ||
+		(call.Kind != SqlKindNullsFirst && call.Kind != SqlKindNullsLast) {
+		w.writeNode(item, 0, 0)
+		return
+	}
+
+	ordered := call.Operands[0]
+	expr := ordered
+	if desc, ok := ordered.(*SqlCall); ok && desc.Kind == SqlKindDescending && len(desc.Operands) == 1 {
+		expr = desc.Operands[0]
+	}
+	isNull := NewSqlCall(NewSqlOperator("IS NULL", SqlKindOther, SyntaxPostfix), []SqlNode{expr}, nil)
+	w.writeNode(isNull, 0, 0)
+	if call.Kind == SqlKindNullsFirst {
+		w.keyword(" DESC")
+	}
+	w.write(", ")
+	w.writeNode(ordered, 0, 0)
+}
+
+// maxLimit LIMIT m, n 写法中只有 OFFSET 时使用的行数
+const maxLimit = "9223372036854775807"
+
+// writeOffsetFetch 按方言输出分页子句
+func (w *SqlWriter) writeOffsetFetch(offset, fetch SqlNode) {
+	switch w.dialect.LimitStyle() {
+	case OffsetFetch:
+		if offset != nil {
+			w.clause("OFFSET")
+			w.writeNode(offset, 0, 0)
+			w.keyword(" ROWS")
+		}
+		if fetch != nil {
+			w.clause("FETCH NEXT")
+			w.writeNode(fetch, 0, 0)
+			w.keyword(" ROWS ONLY")
+		}
+	case LimitComma:
+		if offset == nil {
+			if fetch != nil {
+				w.clause("LIMIT")
+				w.writeNode(fetch, 0, 0)
+			}
+			return
+		}
+		w.clause("LIMIT")
+		w.writeNode(offset, 0, 0)
+		w.write(", ")
+		if fetch != nil {
+			w.writeNode(fetch, 0, 0)
+		} else {
+			w.write(maxLimit)
+		}
+	default:
+		if fetch != nil {
+			w.clause("LIMIT")
+			w.writeNode(fetch, 0, 0)
+		}
+		if offset != nil {
+			w.clause("OFFSET")
+			w.writeNode(offset, 0, 0)
+		}
+	}
+}
+
+// isDualTable 判断是否为解析器为无 FROM 查询生成的 DUAL 表
+func isDualTable(node SqlNode) bool {
+	identifier, ok := node.(*SqlIdentifier)
+	return ok && len(identifier.Names) == 1 && strings.EqualFold(identifier.Names[0], "DUAL")
+}
+
+// writeHint 输出单独的 hint 注释
+func (w *SqlWriter) writeHint(hint *SqlHint) {
+	w.write("/*+ ")
+	w.writeHintStatement(hint)
+	w.write(" */")
+}
+
+// writeHintStatement 输出 hint 名称和参数，不含注释符号
+// hint 参数在解析时已去掉引号，作为不透明文本原样输出
+func (w *SqlWriter) writeHintStatement(hint *SqlHint) {
+	w.write(hint.Name)
+	if len(hint.Parameters) == 0 {
+		return
+	}
+	w.write("(")
+	for i, param := range hint.Parameters {
+		if i > 0 {
+			w.write(", ")
+		}
+		if identifier, ok := param.(*SqlIdentifier); ok {
+			w.write(identifier.ToString())
+		} else {
+			w.writeNode(param, 0, 0)
+		}
+	}
+	w.write(")")
+}
+
+// writeJoin 输出 JOIN，JOIN 左结合，右侧的 JOIN 需要加括号
+func (w *SqlWriter) writeJoin(join *SqlJoin) {
+	w.writeNode(join.Left, 0, 0)
+	if join.JoinType == JoinComma {
+		w.write(",")
+		w.joinSeparator(1)
+	} else {
+		w.joinSeparator(0)
+		w.keyword(string(join.JoinType))
+		w.keyword(" JOIN ")
+	}
+	if _, ok := join.Right.(*SqlJoin); ok {
+		w.write("(")
+		w.writeNode(join.Right, 0, 0)
+		w.write(")")
+	} else {
+		w.writeNode(join.Right, 0, 0)
+	}
+	if join.Condition != nil {
+		w.keyword(" ON ")
+		w.writeNode(join.Condition, 0, 0)
+	} else if len(join.Using) > 0 {
+		w.keyword(" USING (")
+		w.writeList(join.Using, ", ")
+		w.write(")")
+	}
+}
+
+// writeTableRef 输出带时间旅行或采样子句的表引用
+func (w *SqlWriter) writeTableRef(ref *SqlTableRef) {
+	sep := ""
+	if ref.Name != nil {
+		w.writeNode(ref.Name, 0, 0)
+		sep = " "
+	}
+	if ref.Temporal != nil {
+		w.write(sep)
+		w.writeTemporal(ref.Temporal)
+		sep = " "
+	}
+	if ref.Sample != nil {
+		w.write(sep)
+		w.writeSample(ref.Sample)
+	}
+}
+
+// writeTemporal 输出 VERSION AS OF / TIMESTAMP AS OF 子句
+func (w *SqlWriter) writeTemporal(spec *SqlTemporalSpec) {
+	w.keyword(string(spec.Type) + " AS OF ")
+	w.writeNode(spec.Value, 0, 0)
+}
+
+// writeSample 输出 TABLESAMPLE 子句
+func (w *SqlWriter) writeSample(spec *SqlSampleSpec) {
+	w.keyword("TABLESAMPLE (")
+	switch spec.Method {
+	case SamplePercent:
+		w.writeNode(spec.Value, 0, 0)
+		w.keyword(" PERCENT")
+	case SampleRows:
+		w.writeNode(spec.Value, 0, 0)
+		w.keyword(" ROWS")
+	case SampleBucket:
+		w.keyword("BUCKET ")
+		w.write(strconv.FormatInt(spec.Numerator, 10))
+		w.keyword(" OUT OF ")
+		w.write(strconv.FormatInt(spec.Denominator, 10))
+		if spec.BucketOn != nil {
+			w.keyword(" ON ")
+			w.writeNode(spec.BucketOn, 0, 0)
+		}
+	default:
+		// 字节数如 100M 无法解析为表达式时保存为标识符，原样输出
+		if identifier, ok := spec.Value.(*SqlIdentifier); ok {
+			w.write(identifier.ToString())
+		} else {
+			w.writeNode(spec.Value, 0, 0)
+		}
+	}
+	w.write(")")
+	if spec.Seed != nil {
+		w.keyword(" REPEATABLE (")
+		w.write(strconv.FormatInt(*spec.Seed, 10))
+		w.write(")")
+	}
+}
+
+// =============================================================================
+// 工具语句
+// =============================================================================
+
+// writeDescribe 输出 DESCRIBE 语句
+func (w *SqlWriter) writeDescribe(describe *SqlDescribe) {
+	if describe.Query != nil {
+		w.keyword("DESCRIBE QUERY ")
+		w.writeStatement(describe.Query)
+		return
+	}
+	w.keyword("DESCRIBE")
+	if describe.Option != "" {
+		w.write(" ")
+		w.keyword(describe.Option)
+	}
+	if describe.Table != nil {
+		w.write(" ")
+		w.writeNode(describe.Table, 0, 0)
+	}
+	if describe.Column != nil {
+		w.write(" ")
+		w.writeNode(describe.Column, 0, 0)
+	}
+}
+
+// writeShow 输出 SHOW 语句
+func (w *SqlWriter) writeShow(show *SqlShow) {
+	w.keyword("SHOW ")
+	w.keyword(show.Target)
+	if show.Object != nil {
+		if show.Target == "COLUMNS" {
+			w.keyword(" IN")
+		}
+		w.write(" ")
+		w.writeNode(show.Object, 0, 0)
+	}
+	if show.Namespace != nil {
+		w.keyword(" IN ")
+		w.writeNode(show.Namespace, 0, 0)
+	}
+	if show.Pattern != "" {
+		w.keyword(" LIKE ")
+		w.write(QuoteString(show.Pattern))
+	}
+}
+
+// writeUse 输出 USE / SET CATALOG 语句
+func (w *SqlWriter) writeUse(use *SqlUse) {
+	if use.Kind == SqlKindSetCatalog {
+		w.keyword("SET CATALOG")
+	} else {
+		w.keyword("USE")
+		if use.NamespaceType != "" {
+			w.write(" ")
+			w.keyword(use.NamespaceType)
+		}
+	}
+	if use.Namespace != nil {
+		w.write(" ")
+		w.writeNode(use.Namespace, 0, 0)
+	}
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"testing"
+)
+
+func writerIdent(names ...string) SqlNode {
+	return NewSqlIdentifier(names, nil)
+}
+
+func writerCall(name string, kind SqlKind, syntax SqlSyntax, operands ...SqlNode) SqlNode {
+	return NewSqlCall(NewSqlOperator(name, kind, syntax), operands, nil)
+}
+
+// writerAlias 与解析器一样把别名表示为 AS 调用
+func writerAlias(node SqlNode, alias string) SqlNode {
+	return writerCall("AS", SqlKindAs, SyntaxSpecial, node, writerIdent(alias))
+}
+
+// TestUnparsePrecedence 测试只在优先级需要时加括号
+func TestUnparsePrecedence(t *testing.T) {
+	a, b, c := writerIdent("a"), writerIdent("b"), writerIdent("c")
+	and := func(l, r SqlNode) SqlNode { return writerCall("AND", SqlKindAnd, SyntaxBinary, l, r) }
+	or := func(l, r SqlNode) SqlNode { return writerCall("OR", SqlKindOr, SyntaxBinary, l, r) }
+	plus := func(l, r SqlNode) SqlNode { return writerCall("+", SqlKindPlus, SyntaxBinary, l, r) }
+	minus := func(l, r SqlNode) SqlNode { return writerCall("-", SqlKindMinus, SyntaxBinary, l, r) }
+	times := func(l, r SqlNode) SqlNode { return writerCall("*", SqlKindTimes, SyntaxBinary, l, r) }
+	eq := func(l, r SqlNode) SqlNode { return writerCall("=", SqlKindEquals, SyntaxBinary, l, r) }
+	isNull := func(operand SqlNode) SqlNode { return writerCall("IS NULL", SqlKindOther, SyntaxPostfix, operand) }
+
+	tests := []struct {
+		name     string
+		node     SqlNode
+		expected string
+	}{
+		{"NOT 作用于 AND", writerCall("NOT", SqlKindNot, SyntaxPrefix, and(a, b)), "NOT (a AND b)"},
+		{"NOT 作用于比较", writerCall("NOT", SqlKindNot, SyntaxPrefix, eq(a, b)), "NOT a = b"},
+		{"AND 内的 OR", and(or(a, b), c), "(a OR b) AND c"},
+		{"OR 内的 AND", or(and(a, b), c), "a AND b OR c"},
+		{"乘法内的加法", times(plus(a, b), c), "(a + b) * c"},
+		{"加法内的乘法", plus(a, times(b, c)), "a + b * c"},
+		{"左结合", minus(minus(a, b), c), "a - b - c"},
+		{"右侧同级", minus(a, minus(b, c)), "a - (b - c)"},
+		{"比较内的谓词", eq(isNull(a), NewSqlLiteral(true, LiteralBoolean, nil)), "(a IS NULL) = TRUE"},
+		{"谓词内的比较", isNull(eq(a, b)), "a = b IS NULL"},
+		{"取负", writerCall("-", SqlKindMinus, SyntaxPrefix, plus(a, b)), "- (a + b)"},
+		{"连续取负", writerCall("-", SqlKindMinus, SyntaxPrefix, writerCall("-", SqlKindMinus, SyntaxPrefix, a)), "- - a"},
+		{"元素访问", writerCall("ITEM", SqlKindItem, SyntaxSpecial, plus(a, b), NewSqlLiteral(int64(0), LiteralInteger, nil)), "(a + b)[0]"},
+		{"函数参数", writerCall("F", SqlKindCall, SyntaxFunction, or(a, b), c), "F(a OR b, c)"},
+		{"IN 的值", writerCall("IN", SqlKindIn, SyntaxSpecial, plus(a, b), NewSqlNodeList([]SqlNode{c}, nil)), "a + b IN (c)"},
+		{"直接构造的操作符", NewSqlCall(&SqlOperator{Name: "*", Kind: SqlKindTimes, Syntax: SyntaxBinary},
+			[]SqlNode{plus(a, b), c}, nil), "(a + b) * c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unparse(tt.node); got != tt.expected {
+				t.Errorf("Unparse = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestUnparseQuoting 测试标识符与字面量的引号和转义
+func TestUnparseQuoting(t *testing.T) {
+	tests := []struct {
+		name     string
+		node     SqlNode
+		expected string
+	}{
+		{"普通标识符", writerIdent("db", "t"), "db.t"},
+		{"保留字", writerIdent("t", "select"), "t.`select`"},
+		{"特殊字符", writerIdent("my col"), "`my col`"},
+		{"内含反引号", writerIdent("a`b"), "`a``b`"},
+		{"已加反引号", writerIdent("`MixedCase`"), "`MixedCase`"},
+		{"数字开头", writerIdent("1a"), "`1a`"},
+		{"星号", writerIdent("t", "*"), "t.*"},
+		{"单引号", NewSqlLiteral("it's", LiteralString, nil), `'it\'s'`},
+		{"反斜杠和换行", NewSqlLiteral("a\\b\nc", LiteralString, nil), `'a\\b\nc'`},
+		{"小数", NewSqlLiteral(2.0, LiteralDecimal, nil), "2.0"},
+		{"负数", NewSqlLiteral(int64(-3), LiteralInteger, nil), "-3"},
+		{"NULL", NewSqlLiteral(nil, LiteralNull, nil), "NULL"},
+		{"日期", NewSqlLiteral("2024-01-01", LiteralDate, nil), "DATE '2024-01-01'"},
+		{"别名", writerAlias(writerIdent("t"), "order"), "t AS `order`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unparse(tt.node); got != tt.expected {
+				t.Errorf("Unparse = %q, 期望 %q", got, tt.expected)
+			}
+		})
+	}
+
+	w := NewSqlWriter(SqlWriterConfig{QuoteAllIdentifiers: true})
+	w.Write(writerIdent("db", "t", "*"))
+	if got := w.String(); got != "`db`.`t`.*" {
+		t.Errorf("QuoteAllIdentifiers = %q", got)
+	}
+}
+
+// TestQuoteStringRoundTrip 测试字符串转义与解析时的反转义互逆
+func TestQuoteStringRoundTrip(t *testing.T) {
+	values := []string{"", "plain", "it's", `a\b`, "line\nbreak\ttab\r", "\x00123", `"quoted"`, "中文"}
+	for _, value := range values {
+		if got := unescapeStringLiteral(QuoteString(value)); got != value {
+			t.Errorf("往返后 %q 变为 %q", value, got)
+		}
+	}
+
+	if got := unescapeStringLiteral(`'\u4e2d\101\Z'`); got != "中A\x1a" {
+		t.Errorf("unicode 和八进制转义 = %q", got)
+	}
+	if got := unescapeStringLiteral(`'a\%b\_'`); got != `a\%b\_` {
+		t.Errorf("LIKE 转义应保留反斜杠, 实际为 %q", got)
+	}
+	if got := unescapeStringLiteral(`R'a\nb'`); got != `a\nb` {
+		t.Errorf("原始字符串不应处理转义, 实际为 %q", got)
+	}
+}
+
+// TestUnparseSelect 测试完整查询的输出
+func TestUnparseSelect(t *testing.T) {
+	tree := buildCloneTestTree()
+	expected := "SELECT /*+ JOIN(TEE) */ DISTINCT TRANSFORM(a.arr, x -> x + 1), a.x AS ax" +
+		" FROM a INNER JOIN db.b VERSION AS OF 3 TABLESAMPLE (10 PERCENT) REPEATABLE (42) ON a.id = b.id CROSS JOIN c" +
+		" WHERE a.x = 'v' GROUP BY a.x HAVING a.y = 2 WINDOW w ORDER BY a.x LIMIT 10 OFFSET 5"
+	if got := Unparse(tree); got != expected {
+		t.Errorf("Unparse =\n%q\n期望\n%q", got, expected)
+	}
+	if tree.ToString() != Unparse(tree) {
+		t.Errorf("ToString 应与 Unparse 一致")
+	}
+
+	// 多个 hint 合并到同一个注释，DUAL 表不输出 FROM
+	sel := NewSqlSelect(nil)
+	sel.Hints = []*SqlHint{NewSqlHint("JOIN", []SqlNode{writerIdent("TEE")}, nil), NewSqlHint("LOCAL", nil, nil)}
+	sel.SelectList = []SqlNode{NewSqlLiteral(int64(1), LiteralInteger, nil)}
+	sel.From = writerIdent("DUAL")
+	if got := Unparse(sel); got != "SELECT /*+ JOIN(TEE), LOCAL */ 1" {
+		t.Errorf("Unparse = %q", got)
+	}
+	// IdentifierCaseLower 会把 DUAL 转为小写
+	sel.From = writerIdent("dual")
+	if got := Unparse(sel); got != "SELECT /*+ JOIN(TEE), LOCAL */ 1" {
+		t.Errorf("小写 dual: Unparse = %q", got)
+	}
+
+	// 子查询在表达式中加括号，在 EXPLAIN 中不加
+	inner := NewSqlSelect(nil)
+	inner.SelectList = []SqlNode{writerIdent("t", "a")}
+	inner.From = writerIdent("t")
+	in := writerCall("IN", SqlKindIn, SyntaxSpecial, writerIdent("x"), inner)
+	if got := Unparse(in); got != "x IN (SELECT t.a FROM t)" {
+		t.Errorf("Unparse = %q", got)
+	}
+	if got := Unparse(NewSqlExplain("", inner, nil)); got != "EXPLAIN SELECT t.a FROM t" {
+		t.Errorf("Unparse = %q", got)
+	}
+
+	// 右侧的 JOIN 加括号
+	join := NewSqlJoin(writerIdent("a"), NewSqlJoin(writerIdent("b"), writerIdent("c"), JoinCross, nil, nil), JoinCross, nil, nil)
+	if got := Unparse(join); got != "a CROSS JOIN (b CROSS JOIN c)" {
+		t.Errorf("Unparse = %q", got)
+	}
+}
+
+// TestUnparseRoundTrip 测试解析 Unparse 的输出得到结构相同的树
+func TestUnparseRoundTrip(t *testing.T) {
+	sqls := []string{
+		"select t.a, t.b + 1 from t where not (t.a = 1 and t.b = 2)",
+		"select (t.a + t.b) * t.c from t where t.a - (t.b - t.c) > 0",
+		"select t.`select`, t.s from t where t.s = 'it\\'s' or t.s = 'a\\nb'",
+		"select events.m['key'], events.arr[0].name from events",
+		"select TRANSFORM(events.arr, x -> x + 1) from events where (events.a, events.b) in ((1, 2), (3, 4))",
+		"select /*+ JOIN(TEE), FUNC(TEE) */ a.id from plat1.a, plat2.b where a.id = b.id",
+		"select x.a from (select t.a from t) as x",
+		"select 1",
+		"select t.a from t order by t.a desc nulls last, t.b limit 10 offset 5",
+		"select cast(t.a as decimal(10,2)), date '2024-01-01' + interval 3 days from t",
+		"select cast(t.a as interval day to second), cast(t.b as map<string, int>) from t",
+	}
+
+	for _, sql := range sqls {
+		t.Run(sql, func(t *testing.T) {
+			first, err := ParseSQLWithAntlr(sql)
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			unparsed := Unparse(first.SqlNode)
+			second, err := ParseSQLWithAntlr(unparsed)
+			if err != nil {
+				t.Fatalf("重新解析 %q 失败: %v", unparsed, err)
+			}
+			if !Equal(first.SqlNode, second.SqlNode, EqualOptions{IgnorePositions: true}) {
+				t.Errorf("往返后结构不同:\n%s\n%s", unparsed, Unparse(second.SqlNode))
+			}
+		})
+	}
+}